@@ -0,0 +1,55 @@
+package main
+
+import "context"
+
+// SendAll sends messages in order, holding the lock for as long as there is room
+// instead of taking it once per message. It only releases the lock to wait while the
+// buffer is full, so other senders may interleave their messages at those points.
+// The overflow policy applies to each message. On ErrClosed the messages before the
+// failing one have been sent.
+func (ch *Channel[G]) SendAll(messages []G) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	for _, message := range messages {
+		switch {
+		case ch.close:
+			return ch.closedLocked()
+		case ch.offerLocked(message):
+		case ch.opts.overflow != OverflowBlock:
+			if err := ch.overflowLocked(message); err != nil {
+				return err
+			}
+		default:
+			if err := ch.parkSenderLocked(context.Background(), message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReceiveN blocks like Receive until a message is available, then takes it together
+// with whatever else is buffered or held by parked senders, up to n messages, under a
+// single lock acquisition.
+// ok is false (and the slice nil) once the channel is closed and drained.
+func (ch *Channel[G]) ReceiveN(n int) (messages []G, ok bool) {
+	if n <= 0 {
+		return nil, true
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	first, ok, _ := ch.receiveLocked(context.Background())
+	if !ok {
+		return nil, false // Closed and drained
+	}
+	messages = make([]G, 1, min(n, 1+ch.store.Len()+ch.senders.Len()))
+	messages[0] = first
+	for len(messages) < n {
+		message, ok := ch.takeLocked()
+		if !ok {
+			break
+		}
+		messages = append(messages, message)
+	}
+	return messages, true
+}
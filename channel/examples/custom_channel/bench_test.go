@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// link is what the benchmarks need from a channel implementation.
+type link interface {
+	Send(int) error
+	Receive() (int, bool)
+	Close() error
+}
+
+// nativeLink adapts a native channel to link, as the baseline to compare against.
+type nativeLink chan int
+
+func (n nativeLink) Send(v int) error { n <- v; return nil }
+func (n nativeLink) Receive() (int, bool) {
+	v, ok := <-n
+	return v, ok
+}
+func (n nativeLink) Close() error { close(n); return nil }
+
+// transfer moves b.N messages from producers to consumers through ch, splitting the
+// work evenly, and waits for all of them.
+func transfer(b *testing.B, ch link, producers, consumers int) {
+	var wg sync.WaitGroup
+	for c := range consumers {
+		wg.Go(func() {
+			for range b.N/consumers + boolInt(c < b.N%consumers) {
+				ch.Receive()
+			}
+		})
+	}
+	for p := range producers {
+		wg.Go(func() {
+			for i := range b.N/producers + boolInt(p < b.N%producers) {
+				ch.Send(i)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// BenchmarkVsNative compares Channel with a native channel of the same capacity for
+// 1:1, N:1 and N:M producer/consumer ratios
+func BenchmarkVsNative(b *testing.B) {
+	for _, capacity := range []int{0, 64} {
+		for _, ratio := range []struct{ producers, consumers int }{{1, 1}, {8, 1}, {8, 8}} {
+			for _, impl := range []struct {
+				name    string
+				newLink func() link
+			}{
+				{"Channel", func() link { return NewChannel[int](capacity) }},
+				{"native", func() link { return make(nativeLink, capacity) }},
+			} {
+				name := fmt.Sprintf("cap%d/%d:%d/%s", capacity, ratio.producers, ratio.consumers, impl.name)
+				b.Run(name, func(b *testing.B) {
+					b.ReportAllocs()
+					transfer(b, impl.newLink(), ratio.producers, ratio.consumers)
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkMPMC measures throughput with several producers and consumers contending
+// on one channel, where wake-up strategy matters most
+func BenchmarkMPMC(b *testing.B) {
+	for _, capacity := range []int{0, 16} {
+		b.Run(map[int]string{0: "unbuffered", 16: "buffered16"}[capacity], func(b *testing.B) {
+			transfer(b, NewChannel[int](capacity), 8, 8)
+		})
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// BenchmarkPipelineStage measures one producer feeding one consumer, the link
+// between two pipeline stages, with the general Channel and the SPSCChannel
+func BenchmarkPipelineStage(b *testing.B) {
+	for _, tc := range []struct {
+		name    string
+		newLink func() link
+	}{
+		{"Channel", func() link { return NewChannel[int](64) }},
+		{"SPSCChannel", func() link { return NewSPSCChannel[int](64) }},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			ch := tc.newLink()
+			go func() {
+				for i := range b.N {
+					ch.Send(i)
+				}
+				ch.Close()
+			}()
+			for {
+				if _, ok := ch.Receive(); !ok {
+					break
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFanIn measures many producers feeding one consumer through a single
+// Channel and through a ShardedChannel. Sharding only pays off with producers
+// running in parallel on several cores (compare -cpu 1,8); on one core there is
+// no lock contention to remove and the extra scanning makes it slower.
+func BenchmarkFanIn(b *testing.B) {
+	for _, tc := range []struct {
+		name    string
+		newLink func() link
+	}{
+		{"Channel", func() link { return NewChannel[int](256) }},
+		{"ShardedChannel8", func() link { return NewShardedChannel[int](8, 32) }},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			transfer(b, tc.newLink(), 32, 1)
+		})
+	}
+}
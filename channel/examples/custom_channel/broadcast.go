@@ -0,0 +1,85 @@
+package main
+
+import (
+	"slices"
+	"sync"
+)
+
+// BroadcastChannel delivers every sent value to every listener, unlike Channel where
+// each value goes to exactly one receiver. Each listener gets its own Channel from
+// Listen, so listeners receive at their own pace; a full listener holds up Send
+// (backpressure), unless the listeners were created WithOverflow a drop policy.
+type BroadcastChannel[G any] struct {
+	sending   sync.Mutex // Serializes Send so every listener sees the same order
+	mu        sync.Mutex // Guards listeners and closed; not held while sending
+	listeners []*Channel[G]
+	capacity  int
+	opts      []Option
+	closed    bool
+}
+
+// NewBroadcastChannel creates a BroadcastChannel whose listeners each buffer up to
+// capacity values. opts apply to every listener's Channel.
+func NewBroadcastChannel[G any](capacity int, opts ...Option) *BroadcastChannel[G] {
+	return &BroadcastChannel[G]{capacity: capacity, opts: opts}
+}
+
+// Listen registers a new listener and returns the Channel it receives from. The
+// listener gets every value sent from now on. Closing the returned Channel
+// unsubscribes it. After Close, Listen returns an already closed Channel.
+func (b *BroadcastChannel[G]) Listen() *Channel[G] {
+	ch := NewChannel[G](b.capacity, b.opts...)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		ch.Close()
+		return ch
+	}
+	b.listeners = append(b.listeners, ch)
+	return ch
+}
+
+// Send delivers message to every listener, in listener order, blocking on each one
+// that is full. Listeners that have closed their Channel are dropped. Listen and
+// Close do not wait for a blocked Send; Close releases it, as the listener Channels
+// it is blocked on are closed.
+func (b *BroadcastChannel[G]) Send(message G) error {
+	b.sending.Lock()
+	defer b.sending.Unlock()
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	// Listen only appends and Close replaces the slice, so this view stays valid
+	listeners := b.listeners
+	b.mu.Unlock()
+
+	var gone []*Channel[G]
+	for _, listener := range listeners {
+		if listener.Send(message) != nil {
+			gone = append(gone, listener)
+		}
+	}
+	if len(gone) > 0 {
+		b.mu.Lock()
+		b.listeners = slices.DeleteFunc(b.listeners, func(l *Channel[G]) bool { return slices.Contains(gone, l) })
+		b.mu.Unlock()
+	}
+	return nil
+}
+
+// Close closes every listener's Channel; they can still drain what they buffered.
+func (b *BroadcastChannel[G]) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	b.closed = true
+	for _, listener := range b.listeners {
+		listener.Close() // ErrClosed if the listener already left
+	}
+	b.listeners = nil
+	return nil
+}
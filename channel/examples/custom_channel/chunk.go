@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// Chunk returns a Channel of the messages received from src grouped into slices of
+// up to size messages. A chunk is sent once it is full or maxWait after its first
+// message arrived, whichever comes first, so a slow trickle still flows downstream;
+// maxWait <= 0 waits for full chunks. The last chunk may be short. The result has
+// src's capacity and is closed, with src's error, once src is closed and drained.
+// The window is measured on src's clock (see WithClock).
+func Chunk[G any](src *Channel[G], size int, maxWait time.Duration) *Channel[[]G] {
+	size = max(size, 1)
+	out := NewChannel[[]G](src.Cap())
+	go func() {
+		defer func() { out.CloseWithError(src.Err()) }()
+		for {
+			first, ok := src.Receive()
+			if !ok {
+				return
+			}
+			chunk, more := fillChunk(src, append(make([]G, 0, size), first), maxWait)
+			if out.Send(chunk) != nil || !more {
+				return // The result was closed, or src is drained
+			}
+		}
+	}()
+	return out
+}
+
+// fillChunk receives into chunk until it is full or maxWait has passed. more is
+// false if src turned out to be closed and drained.
+func fillChunk[G any](src *Channel[G], chunk []G, maxWait time.Duration) (_ []G, more bool) {
+	deadline := src.opts.clock.Now().Add(maxWait)
+	for len(chunk) < cap(chunk) {
+		var message G
+		var ok bool
+		if maxWait <= 0 {
+			message, ok = src.Receive()
+		} else {
+			remaining := deadline.Sub(src.opts.clock.Now())
+			if remaining <= 0 {
+				break
+			}
+			var err error
+			if message, ok, err = src.ReceiveTimeout(remaining); errors.Is(err, ErrTimeout) {
+				break
+			}
+		}
+		if !ok {
+			return chunk, false
+		}
+		chunk = append(chunk, message)
+	}
+	return chunk, true
+}
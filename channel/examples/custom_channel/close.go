@@ -1,14 +1,91 @@
 package main
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
 
 func (ch *Channel[G]) Close() error {
-	ch.cond.L.Lock()
-	defer ch.cond.L.Unlock()
+	return ch.CloseWithError(nil)
+}
+
+// CloseWithError closes the channel like Close and records err as the reason, which
+// ReceiveErr returns once the buffer is drained. A nil err is a plain Close.
+func (ch *Channel[G]) CloseWithError(err error) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
 	if ch.close {
 		return errors.New("close is already closed")
 	}
 	ch.close = true
-	ch.cond.Broadcast()
+	ch.err = err
+	if ch.store.Len() == 0 && ch.spill != nil {
+		ch.spill.remove() // Nothing left to read back
+	}
+	if ch.done != nil {
+		close(ch.done)
+	}
+	ch.opts.logger.Debug("custom channel closed", "buffered", ch.store.Len(), "err", err)
+	// Wake everybody: senders fail, receivers drain the buffer and then return ok=false
+	wakeAllLocked[G](ch.senders)
+	wakeAllLocked[G](ch.receivers)
+	ch.notifySelectors()
+	return nil
+}
+
+// Done returns a channel that is closed when the channel is closed, for use in a
+// native select next to ctx.Done(). Buffered messages may still be receivable then.
+func (ch *Channel[G]) Done() <-chan struct{} {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.done == nil {
+		ch.done = make(chan struct{})
+		if ch.close {
+			close(ch.done)
+		}
+	}
+	return ch.done
+}
+
+// Err returns the error the channel was closed with by CloseWithError, or nil if it
+// is open or was closed with Close.
+func (ch *Channel[G]) Err() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.err
+}
+
+// AbandonedError is returned by CloseAndWait when ctx is done before the buffer has
+// been drained.
+type AbandonedError struct {
+	Count int   // Messages still buffered
+	Err   error // ctx.Err()
+}
+
+func (e *AbandonedError) Error() string {
+	return fmt.Sprintf("%d messages abandoned: %v", e.Count, e.Err)
+}
+
+func (e *AbandonedError) Unwrap() error { return e.Err }
+
+// CloseAndWait closes the channel (if it is still open) and waits until receivers
+// have drained every buffered message, for a deterministic graceful shutdown. If ctx
+// is done first it returns an *AbandonedError with the number of messages left.
+func (ch *Channel[G]) CloseAndWait(ctx context.Context) error {
+	ch.Close() // Already closed is fine: just wait
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.drained == nil {
+		ch.drained = sync.NewCond(&ch.mu)
+	}
+	defer wakeOnDone(ctx, ch.drained)()
+	for ch.bufferedLocked() > 0 {
+		if err := ctx.Err(); err != nil {
+			return &AbandonedError{Count: ch.bufferedLocked(), Err: err}
+		}
+		ch.drained.Wait()
+	}
 	return nil
 }
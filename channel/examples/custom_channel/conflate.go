@@ -0,0 +1,74 @@
+package main
+
+import "sync"
+
+// ConflatingChannel keeps only the most recent value: Send never blocks and replaces
+// a value nobody has received yet, and Receive gets the latest one. It suits state
+// synchronisation, where a consumer only cares about the current state and a
+// backlog of stale updates would just be work to skip.
+type ConflatingChannel[G any] struct {
+	mu       sync.Mutex
+	ready    *sync.Cond // Receivers wait here for a value
+	value    G
+	has      bool // value has not been received yet
+	close    bool
+	replaced int64 // Values overwritten before being received
+}
+
+// NewConflatingChannel creates an empty ConflatingChannel.
+func NewConflatingChannel[G any]() *ConflatingChannel[G] {
+	c := &ConflatingChannel[G]{}
+	c.ready = sync.NewCond(&c.mu)
+	return c
+}
+
+// Send stores message as the latest value, replacing the unreceived one if any.
+func (c *ConflatingChannel[G]) Send(message G) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.close {
+		return ErrClosed
+	}
+	if c.has {
+		c.replaced++
+	}
+	c.value, c.has = message, true
+	c.ready.Signal()
+	return nil
+}
+
+// Receive takes the latest value, blocking until one is sent. After Close it still
+// returns the last unreceived value, then ok=false.
+func (c *ConflatingChannel[G]) Receive() (message G, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for !c.has && !c.close {
+		c.ready.Wait()
+	}
+	if !c.has {
+		return message, false
+	}
+	message = c.value
+	var zero G
+	c.value, c.has = zero, false // Don't keep the value reachable
+	return message, true
+}
+
+// Replaced returns how many values were overwritten before anyone received them.
+func (c *ConflatingChannel[G]) Replaced() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.replaced
+}
+
+// Close closes the channel, waking every waiting receiver.
+func (c *ConflatingChannel[G]) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.close {
+		return ErrClosed
+	}
+	c.close = true
+	c.ready.Broadcast()
+	return nil
+}
@@ -3,20 +3,49 @@ package main
 import (
 	"container/list"
 	"sync"
+	"time"
 )
 
 type Channel[G any] struct {
+	id       uint64 // Lock order for Select (see lockAll)
 	store    *list.List
 	capacity int
-	cond     *sync.Cond
+	mu       sync.Mutex
 	close    bool
+	err      error         // Reason given to CloseWithError
+	done     chan struct{} // Closed by Close; created on demand by Done
+	sends    int64
+	receives int64
+	dropped  int64
+	opts     options
+
+	failedSends    int64         // Sends rejected after Close
+	sendBlocked    time.Duration // Time senders spent waiting (see Stats)
+	receiveBlocked time.Duration // Time receivers spent waiting
+
+	senders   *list.List            // Of *handoff[G]: senders parked on a full buffer
+	receivers *list.List            // Of *handoff[G]: receivers parked on an empty buffer
+	selectors map[chan struct{}]int // Blocked Select calls to notify on every state change
+	spill     *spill[G]             // Messages beyond capacity, with WithSpillover
+	drained   *sync.Cond            // CloseAndWait waits here; created on demand
+	pool      *ChannelPool[G]       // Pool that created the channel, for ChannelPool.Put
 }
 
-func NewChannel[G any](capacity int) *Channel[G] {
-	return &Channel[G]{
-		store:    list.New(),
-		capacity: capacity,
-		cond:     sync.NewCond(&sync.Mutex{}),
-		close:    false,
+func NewChannel[G any](capacity int, opts ...Option) *Channel[G] {
+	ch := &Channel[G]{
+		id:        channelIDs.Add(1),
+		store:     list.New(),
+		close:     false,
+		opts:      newOptions(opts),
+		senders:   list.New(),
+		receivers: list.New(),
+	}
+	ch.capacity = ch.opts.capacity(capacity)
+	if ch.opts.overflow == overflowSpill {
+		ch.spill = &spill[G]{dir: ch.opts.spillDir}
+	}
+	if ch.opts.metricsName != "" {
+		ch.PublishExpvar(ch.opts.metricsName)
 	}
+	return ch
 }
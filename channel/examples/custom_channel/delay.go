@@ -0,0 +1,180 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// wheelSlots is the number of slots in a DelayChannel's timer wheel. Delays longer
+// than wheelSlots ticks go round the wheel more than once.
+const wheelSlots = 64
+
+// DelayChannel is a Channel whose messages only become receivable once the delay
+// given to Send has elapsed. Pending messages sit in a hashed timer wheel: one slot
+// per tick, each holding the messages due when the wheel reaches it, so a single
+// goroutine and a single timer serve any number of pending messages.
+//
+// Delivery happens on tick boundaries, so a message is received between delay and
+// delay+tick after Send (later if receivers fall behind and the buffer is full).
+// Messages due on the same tick are delivered in Send order.
+type DelayChannel[G any] struct {
+	out  *Channel[G] // Messages whose delay has elapsed
+	tick time.Duration
+	opts options
+
+	mu      sync.Mutex
+	slots   [wheelSlots]*list.List // Of *delayed[G]
+	pos     int                    // Slot processed on the last tick
+	next    time.Time              // When the wheel moves to the next slot
+	pending int
+	closed  bool
+
+	wake chan struct{} // Wakes the idle wheel goroutine
+	done chan struct{} // Closed by Close to stop the wheel goroutine
+}
+
+type delayed[G any] struct {
+	message G
+	rounds  int // Full turns of the wheel left before the message is due
+}
+
+// NewDelayChannel creates a DelayChannel buffering up to capacity due messages and
+// moving its timer wheel every tick. WithClock sets the time source for the wheel.
+func NewDelayChannel[G any](capacity int, tick time.Duration, opts ...Option) *DelayChannel[G] {
+	d := &DelayChannel[G]{
+		out:  NewChannel[G](capacity, opts...),
+		tick: tick,
+		opts: newOptions(opts),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	for i := range d.slots {
+		d.slots[i] = list.New()
+	}
+	go d.run()
+	return d
+}
+
+// Send schedules message to become receivable after delay. A non-positive delay
+// sends it straight away, blocking like Channel.Send if the buffer is full.
+func (d *DelayChannel[G]) Send(message G, delay time.Duration) error {
+	if delay <= 0 {
+		return d.out.Send(message)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	now := d.opts.clock.Now()
+	if d.pending == 0 {
+		// The wheel is idle: restart it with a full tick ahead
+		d.next = now.Add(d.tick)
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+	// The next tick is partial; count whole ticks after it, rounding up so the
+	// message is never delivered early
+	ticks := 1
+	if rest := delay - d.next.Sub(now); rest > 0 {
+		ticks += int((rest + d.tick - 1) / d.tick)
+	}
+	slot := (d.pos + ticks) % wheelSlots
+	d.slots[slot].PushBack(&delayed[G]{message: message, rounds: (ticks - 1) / wheelSlots})
+	d.pending++
+	return nil
+}
+
+// Receive returns the next message whose delay has elapsed, blocking until there is
+// one. ok is false once the channel is closed and drained.
+func (d *DelayChannel[G]) Receive() (message G, ok bool) {
+	return d.out.Receive()
+}
+
+// ReceiveContext is Receive that gives up with ctx.Err() when ctx is done.
+func (d *DelayChannel[G]) ReceiveContext(ctx context.Context) (message G, ok bool, err error) {
+	return d.out.ReceiveContext(ctx)
+}
+
+// Pending returns the number of messages whose delay has not elapsed yet.
+func (d *DelayChannel[G]) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pending
+}
+
+// Close stops the wheel and closes the channel. Messages whose delay has not elapsed
+// are discarded; the ones already due can still be received.
+func (d *DelayChannel[G]) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrClosed
+	}
+	d.closed = true
+	for _, slot := range d.slots {
+		slot.Init()
+	}
+	d.pending = 0
+	d.mu.Unlock()
+
+	close(d.done)
+	return d.out.Close()
+}
+
+// run moves the wheel one slot per tick while messages are pending and hands the due
+// ones to the output channel. It sleeps on wake while the wheel is empty.
+func (d *DelayChannel[G]) run() {
+	for {
+		d.mu.Lock()
+		idle, next := d.pending == 0, d.next
+		d.mu.Unlock()
+
+		if idle {
+			select {
+			case <-d.wake:
+				continue
+			case <-d.done:
+				return
+			}
+		}
+		timer := d.opts.clock.NewTimer(next.Sub(d.opts.clock.Now()))
+		select {
+		case <-timer.C():
+		case <-d.done:
+			timer.Stop()
+			return
+		}
+		// Sending may block on a full buffer; Close unblocks it with ErrClosed
+		if d.out.SendAll(d.advance()) != nil {
+			return
+		}
+	}
+}
+
+// advance moves the wheel to the next slot and returns the messages due on it.
+func (d *DelayChannel[G]) advance() []G {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pos = (d.pos + 1) % wheelSlots
+	d.next = d.next.Add(d.tick)
+
+	var due []G
+	slot := d.slots[d.pos]
+	for e := slot.Front(); e != nil; {
+		next := e.Next()
+		if item := e.Value.(*delayed[G]); item.rounds > 0 {
+			item.rounds--
+		} else {
+			due = append(due, item.message)
+			slot.Remove(e)
+		}
+		e = next
+	}
+	d.pending -= len(due)
+	return due
+}
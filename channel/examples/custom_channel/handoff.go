@@ -0,0 +1,152 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// handoff is a sender or receiver parked on the channel. Messages move between the
+// two sides directly, like in the runtime's native channels: a sender that finds a
+// parked receiver fills in its value and wakes it, and a receiver that finds a parked
+// sender takes its value (or, on a full buffer, moves it into the buffer) and wakes
+// it. Each waiter has its own condition variable, so a transfer wakes exactly the
+// goroutine involved, and the unbuffered case never touches the store.
+//
+// A sender only parks when no receiver is parked and the buffer is full, and a
+// receiver only when no sender is parked and the buffer is empty, so at most one of
+// the two queues holds waiters that can still complete at any time.
+//
+// A blocked Select parks one slot per case, all sharing sel. The first counterpart
+// to claim one of them wins the Select; the other slots stay queued until the Select
+// wakes up and removes them, and are skipped (see popLocked) by anyone who finds them
+// first.
+type handoff[G any] struct {
+	value G    // Carried by a parked sender, or filled in for a parked receiver
+	done  bool // The message has been handed over
+	ready *sync.Cond
+	elem  *list.Element
+	sel   *selectWait // Set for the cases of a blocked Select
+}
+
+// signal wakes the goroutine parked on slot once its message has been handed over.
+func (slot *handoff[G]) signal() {
+	if slot.sel != nil {
+		slot.sel.signal()
+		return
+	}
+	slot.ready.Signal()
+}
+
+// popLocked removes and returns the longest waiting slot on queue that can still
+// complete, or nil if there is none. Slots of a Select that another case has already
+// won are dropped on the way. Must be called with the lock held.
+func popLocked[G any](queue *list.List) *handoff[G] {
+	for e := queue.Front(); e != nil; e = queue.Front() {
+		slot := queue.Remove(e).(*handoff[G])
+		if slot.sel == nil || slot.sel.claim() {
+			return slot
+		}
+	}
+	return nil
+}
+
+// parkLocked queues a waiter with value (the message, for a sender) on queue. The
+// caller then waits on the slot's ready until done is set, the channel is closed or
+// it gives up, and must call queue.Remove(slot.elem) when it stops waiting (a no-op
+// if the other side already took the slot). Must be called with the lock held.
+func (ch *Channel[G]) parkLocked(queue *list.List, value G) *handoff[G] {
+	slot := &handoff[G]{value: value, ready: sync.NewCond(&ch.mu)}
+	slot.elem = queue.PushBack(slot)
+	ch.notifySelectors() // A parked waiter makes the opposite cases ready (see Select)
+	return slot
+}
+
+// offerLocked sends message without waiting: to the longest parked receiver if there
+// is one, otherwise into the buffer if it has room. It reports false if the sender
+// has to wait. Must be called with the lock held.
+func (ch *Channel[G]) offerLocked(message G) bool {
+	if slot := popLocked[G](ch.receivers); slot != nil {
+		slot.value, slot.done = message, true
+		slot.signal()
+	} else if !ch.full() && ch.spilled() == 0 {
+		ch.store.PushBack(message)
+	} else {
+		return false
+	}
+	ch.sends++
+	ch.notifySelectors()
+	return true
+}
+
+// takeLocked receives without waiting: the oldest buffered message, whose place the
+// longest parked sender then takes, or, with nothing buffered, the message of the
+// longest parked sender. It reports false if the receiver has to wait. Must be
+// called with the lock held.
+func (ch *Channel[G]) takeLocked() (message G, ok bool) {
+	if ch.store.Len() > 0 {
+		message = ch.store.Remove(ch.store.Front()).(G)
+		ch.refillLocked()
+	} else if slot := popLocked[G](ch.senders); slot != nil {
+		message = slot.value
+		ch.releaseLocked(slot)
+	} else {
+		return message, false
+	}
+	ch.receives++
+	ch.notifySelectors()
+	if ch.drained != nil && ch.close && ch.bufferedLocked() == 0 {
+		ch.drained.Broadcast()
+	}
+	return message, true
+}
+
+// refillLocked moves spilled messages (see WithSpillover), or else the messages of
+// parked senders, into the buffer while it has room. Must be called with the lock
+// held.
+func (ch *Channel[G]) refillLocked() {
+	for !ch.full() && ch.spilled() > 0 {
+		before := ch.spill.n
+		message, err := ch.spill.pop()
+		if err != nil {
+			lost := before - ch.spill.n
+			ch.dropped += int64(lost)
+			ch.opts.logger.Error("custom channel: spilled messages lost", "count", lost, "err", err)
+			continue
+		}
+		ch.store.PushBack(message)
+	}
+	if ch.close && ch.store.Len() == 0 && ch.spill != nil {
+		ch.spill.remove() // Drained for good
+	}
+	for !ch.full() {
+		slot := popLocked[G](ch.senders)
+		if slot == nil {
+			break
+		}
+		ch.store.PushBack(slot.value)
+		ch.releaseLocked(slot)
+	}
+}
+
+// spilled returns the number of messages on disk. Must be called with the lock held.
+func (ch *Channel[G]) spilled() int {
+	if ch.spill == nil {
+		return 0
+	}
+	return ch.spill.n
+}
+
+// releaseLocked completes the Send of a parked sender whose message was taken.
+func (ch *Channel[G]) releaseLocked(slot *handoff[G]) {
+	slot.done = true
+	slot.signal()
+	ch.sends++
+}
+
+// wakeAllLocked wakes every waiter parked on queue, so it re-checks its wait
+// condition (the channel was closed). Must be called with the lock held.
+func wakeAllLocked[G any](queue *list.List) {
+	for e := queue.Front(); e != nil; e = e.Next() {
+		e.Value.(*handoff[G]).ready.Broadcast()
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import "iter"
+
+// All returns an iterator over the messages received from the channel:
+//
+//	for v := range ch.All() { ... }
+//
+// It ends once the channel is closed and drained. Breaking out of the loop stops
+// receiving; messages not yet received stay in the channel.
+func (ch *Channel[G]) All() iter.Seq[G] {
+	return func(yield func(G) bool) {
+		for {
+			message, ok := ch.Receive()
+			if !ok || !yield(message) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+// Len returns the number of messages currently buffered (in memory or spilled, see
+// WithSpillover), like len() on a native channel.
+func (ch *Channel[G]) Len() int {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.bufferedLocked()
+}
+
+// bufferedLocked returns the number of messages buffered in memory or spilled. Must
+// be called with the lock held.
+func (ch *Channel[G]) bufferedLocked() int {
+	return ch.store.Len() + ch.spilled()
+}
+
+// Cap returns the current capacity (see Resize), like cap() on a native channel.
+func (ch *Channel[G]) Cap() int {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.capacity
+}
+
+// WaitingSenders returns the number of goroutines blocked in Send (or SendAll), or in
+// a Select with a send case on ch, on a full buffer.
+func (ch *Channel[G]) WaitingSenders() int {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.senders.Len()
+}
+
+// WaitingReceivers returns the number of goroutines blocked in Receive (or ReceiveN),
+// or in a Select with a receive case on ch, on an empty buffer.
+func (ch *Channel[G]) WaitingReceivers() int {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.receivers.Len()
+}
@@ -0,0 +1,1251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+	"goconcurrency/sync/linearizability"
+)
+
+// TestChannelLinearizable tests that concurrent Send/Receive histories behave like a FIFO queue
+func TestChannelLinearizable(t *testing.T) {
+	for _, capacity := range []int{0, 1, 4} {
+		ch := NewChannel[int](capacity)
+		var rec linearizability.Recorder[linearizability.QueueInput[int], linearizability.QueueOutput[int]]
+
+		var wg sync.WaitGroup
+		for p := range 3 {
+			wg.Go(func() {
+				for i := range 5 {
+					v := p*100 + i
+					in := linearizability.QueueInput[int]{Op: linearizability.Enqueue, Value: v}
+					rec.Record(p, in, func() linearizability.QueueOutput[int] {
+						if err := ch.Send(v); err != nil {
+							t.Errorf("Send() returned error: %v", err)
+						}
+						return linearizability.QueueOutput[int]{}
+					})
+				}
+			})
+			wg.Go(func() {
+				for range 5 {
+					in := linearizability.QueueInput[int]{Op: linearizability.Dequeue}
+					rec.Record(10+p, in, func() linearizability.QueueOutput[int] {
+						v, ok := ch.Receive()
+						return linearizability.QueueOutput[int]{Value: v, OK: ok}
+					})
+				}
+			})
+		}
+		wg.Wait()
+
+		if !linearizability.Check(linearizability.QueueModel[int](), rec.History()) {
+			t.Errorf("capacity %d: history is not linearizable as a FIFO queue", capacity)
+		}
+	}
+}
+
+// TestNewChannelOptions tests that constructor options are applied
+func TestNewChannelOptions(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	ch := NewChannel[int](2, WithClock(fake), WithMetrics("test-options"))
+
+	if ch.opts.clock != fake {
+		t.Error("WithClock was not applied")
+	}
+	if expvarRoot.Get("test-options") == nil {
+		t.Error("WithMetrics did not register the channel in expvar")
+	}
+	if NewChannel[int](2).opts.clock != clock.Real {
+		t.Error("Expected the real clock by default")
+	}
+}
+
+// TestSnapshotRestore tests that Export and NewChannelFromSnapshot round-trip buffered messages
+func TestSnapshotRestore(t *testing.T) {
+	ch := NewChannel[string](3)
+	ch.Send("a")
+	ch.Send("b")
+
+	items := ch.Export()
+	if !slices.Equal(items, []string{"a", "b"}) {
+		t.Fatalf("Expected [a b], got %v", items)
+	}
+	if got := ch.Export(); len(got) != 2 {
+		t.Errorf("Expected Export not to drain the channel, got %v", got)
+	}
+
+	restored, err := NewChannelFromSnapshot(3, items)
+	if err != nil {
+		t.Fatalf("NewChannelFromSnapshot() returned error: %v", err)
+	}
+	restored.Send("c")
+	for _, want := range []string{"a", "b", "c"} {
+		if got, ok := restored.Receive(); !ok || got != want {
+			t.Errorf("Expected %s, got %s (ok=%v)", want, got, ok)
+		}
+	}
+
+	if _, err := NewChannelFromSnapshot(1, items); err == nil {
+		t.Error("Expected an error when the snapshot exceeds capacity")
+	}
+}
+
+// TestSendReceiveContext tests that blocked Send and Receive give up when their context ends
+func TestSendReceiveContext(t *testing.T) {
+	ch := NewChannel[int](0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := ch.SendContext(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected SendContext to time out without a receiver, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if _, ok, err := ch.ReceiveContext(ctx); ok || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ReceiveContext to be cancelled, got ok=%v err=%v", ok, err)
+	}
+
+	// The cancelled receiver gave its slot back: the channel is still unbuffered
+	if err := ch.SendContext(shortContext(t), 2); err == nil {
+		t.Error("Expected SendContext to block again after the receiver gave up")
+	}
+
+	go ch.Send(3)
+	if v, ok, err := ch.ReceiveContext(context.Background()); v != 3 || !ok || err != nil {
+		t.Errorf("Expected 3, got %d (ok=%v, err=%v)", v, ok, err)
+	}
+}
+
+// shortContext returns a context that expires after a few milliseconds
+func shortContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// TestSendReceiveTimeout tests the timeouts against the channel's clock
+func TestSendReceiveTimeout(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	ch := NewChannel[int](1, WithClock(fake))
+	ch.Send(1)
+
+	result := make(chan error)
+	go func() { result <- ch.SendTimeout(2, time.Second) }()
+	for fake.Pending() == 0 {
+		time.Sleep(time.Millisecond) // wait for SendTimeout to start its timer
+	}
+	fake.Advance(time.Second)
+	if err := <-result; !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+
+	if v, ok, err := ch.ReceiveTimeout(time.Second); v != 1 || !ok || err != nil {
+		t.Errorf("Expected buffered 1 without waiting, got %d (ok=%v, err=%v)", v, ok, err)
+	}
+
+	go func() {
+		_, _, err := ch.ReceiveTimeout(time.Minute)
+		result <- err
+	}()
+	for fake.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	fake.Advance(time.Minute)
+	if err := <-result; !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout from ReceiveTimeout, got %v", err)
+	}
+}
+
+// TestSelect tests Select over channels of different element types
+func TestSelect(t *testing.T) {
+	ints := NewChannel[int](1)
+	strs := NewChannel[string](1)
+
+	// Only strs is ready to receive from
+	strs.Send("hello")
+	if i, v, ok := Select(RecvCase(ints), RecvCase(strs)); i != 1 || v != "hello" || !ok {
+		t.Errorf("Expected 'hello' from case 1, got %v from %d (ok=%v)", v, i, ok)
+	}
+
+	// A send case proceeds when there is room
+	if i, _, ok := Select(RecvCase(ints), SendCase(ints, 42)); i != 1 || !ok {
+		t.Errorf("Expected send case 1 to proceed, got %d (ok=%v)", i, ok)
+	}
+	if v, _ := ints.Receive(); v != 42 {
+		t.Errorf("Expected 42 to have been sent, got %d", v)
+	}
+
+	// Blocks until another goroutine sends
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		ints.Send(7)
+	}()
+	if i, v, ok := Select(RecvCase(ints), RecvCase(strs)); i != 0 || v != 7 || !ok {
+		t.Errorf("Expected 7 from case 0, got %v from %d (ok=%v)", v, i, ok)
+	}
+
+	// Closed channels are always ready
+	strs.Close()
+	if i, _, ok := Select(RecvCase(ints), RecvCase(strs)); i != 1 || ok {
+		t.Errorf("Expected closed case 1 with ok=false, got %d (ok=%v)", i, ok)
+	}
+}
+
+// TestSelectUnbuffered tests that Select takes the value of a sender blocked on an unbuffered channel
+func TestSelectUnbuffered(t *testing.T) {
+	a, b := NewChannel[int](0), NewChannel[int](0)
+	done := make(chan error)
+	go func() { done <- b.Send(5) }()
+
+	if i, v, ok := Select(RecvCase(a), RecvCase(b)); i != 1 || v != 5 || !ok {
+		t.Errorf("Expected 5 from case 1, got %v from %d (ok=%v)", v, i, ok)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Send() returned error: %v", err)
+	}
+}
+
+// TestSelectVsSelect tests that a Select sending on an unbuffered channel meets a Select
+// receiving from it, and that the losing cases of both are withdrawn
+func TestSelectVsSelect(t *testing.T) {
+	ch, other := NewChannel[int](0), NewChannel[int](0)
+	for n := range 100 {
+		done := make(chan int)
+		go func() {
+			i, _, ok := Select(SendCase(ch, n), RecvCase(other))
+			if !ok {
+				i = -1
+			}
+			done <- i
+		}()
+		i, v, ok := Select(RecvCase(other), RecvCase(ch))
+		if i != 1 || v != n || !ok {
+			t.Fatalf("Expected %d from case 1, got %v from %d (ok=%v)", n, v, i, ok)
+		}
+		if i := <-done; i != 0 {
+			t.Fatalf("Expected the sending Select to complete case 0, got %d", i)
+		}
+	}
+	if ch.WaitingSenders() != 0 || ch.WaitingReceivers() != 0 || other.WaitingReceivers() != 0 {
+		t.Errorf("Expected no parked waiters, got %d/%d on ch and %d on other",
+			ch.WaitingSenders(), ch.WaitingReceivers(), other.WaitingReceivers())
+	}
+
+	// A plain Send still completes a parked Select, and a withdrawn case stays unused
+	go func() { time.Sleep(10 * time.Millisecond); ch.Send(7) }()
+	if i, v, ok := Select(RecvCase(other), RecvCase(ch)); i != 1 || v != 7 || !ok {
+		t.Errorf("Expected 7 from case 1, got %v from %d (ok=%v)", v, i, ok)
+	}
+	if i, _, _ := TrySelect(SendCase(other, 1)); i != -1 {
+		t.Errorf("Expected no receiver left on other, got case %d", i)
+	}
+}
+
+// TestLenCap tests the fill level and capacity accessors
+func TestLenCap(t *testing.T) {
+	ch := NewChannel[int](3)
+	ch.Send(1)
+	ch.Send(2)
+	if ch.Len() != 2 || ch.Cap() != 3 {
+		t.Errorf("Expected len 2 cap 3, got len %d cap %d", ch.Len(), ch.Cap())
+	}
+
+	unbuffered := NewChannel[int](0)
+	go unbuffered.Receive()
+	for {
+		// A waiting receiver lets one message through, but does not change Cap
+		if unbuffered.WaitingReceivers() == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if unbuffered.Len() != 0 || unbuffered.Cap() != 0 {
+		t.Errorf("Expected len 0 cap 0, got len %d cap %d", unbuffered.Len(), unbuffered.Cap())
+	}
+	unbuffered.Send(1)
+}
+
+// TestReceiveDrainsAfterClose tests native-channel semantics for Receive after Close
+func TestReceiveDrainsAfterClose(t *testing.T) {
+	ch := NewChannel[string](2)
+	ch.Send("a")
+	ch.Send("b")
+	ch.Close()
+
+	for _, want := range []string{"a", "b"} {
+		if got, ok := ch.Receive(); got != want || !ok {
+			t.Errorf("Expected %q with ok=true after Close, got %q (ok=%v)", want, got, ok)
+		}
+	}
+	if got, ok := ch.Receive(); got != "" || ok {
+		t.Errorf("Expected zero value with ok=false once drained, got %q (ok=%v)", got, ok)
+	}
+	if ch.Cap() != 2 {
+		t.Errorf("Expected Receive to leave the capacity at 2, got %d", ch.Cap())
+	}
+
+	// Close wakes receivers blocked on an empty channel
+	empty := NewChannel[int](0)
+	done := make(chan bool)
+	go func() {
+		_, ok := empty.Receive()
+		done <- ok
+	}()
+	time.Sleep(10 * time.Millisecond)
+	empty.Close()
+	if ok := <-done; ok {
+		t.Error("Expected blocked Receive to return ok=false after Close")
+	}
+}
+
+// TestOverflowPolicies tests what Send does on a full buffer under each policy
+func TestOverflowPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		policy Overflow
+		want   []int
+	}{
+		{OverflowDropNewest, []int{1, 2}},
+		{OverflowDropOldest, []int{3, 4}},
+	} {
+		ch := NewChannel[int](2, WithOverflow(tc.policy))
+		for i := 1; i <= 4; i++ {
+			if err := ch.Send(i); err != nil {
+				t.Fatalf("Send() returned error: %v", err)
+			}
+		}
+		if got := ch.Export(); !slices.Equal(got, tc.want) {
+			t.Errorf("policy %d: expected %v buffered, got %v", tc.policy, tc.want, got)
+		}
+		if ch.dropped != 2 {
+			t.Errorf("policy %d: expected 2 dropped, got %d", tc.policy, ch.dropped)
+		}
+	}
+
+	// Unbuffered without a receiver: nothing to evict, the message is dropped
+	ch := NewChannel[int](0, WithOverflow(OverflowDropOldest))
+	if err := ch.Send(1); err != nil || ch.Len() != 0 {
+		t.Errorf("Expected unbuffered drop without blocking, got err=%v len=%d", err, ch.Len())
+	}
+}
+
+// TestSendAllReceiveN tests moving batches of messages through the channel
+func TestSendAllReceiveN(t *testing.T) {
+	ch := NewChannel[int](2)
+	done := make(chan error, 1)
+	go func() { done <- ch.SendAll([]int{1, 2, 3, 4, 5}) }()
+
+	var got []int
+	for len(got) < 5 {
+		batch, ok := ch.ReceiveN(3)
+		if !ok || len(batch) == 0 || len(batch) > 3 {
+			t.Fatalf("ReceiveN(3) = %v, %v", batch, ok)
+		}
+		got = append(got, batch...)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("SendAll() returned error: %v", err)
+	}
+	if !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("Expected messages in order, got %v", got)
+	}
+
+	ch.Close()
+	if batch, ok := ch.ReceiveN(3); ok || batch != nil {
+		t.Errorf("Expected ReceiveN on a closed, drained channel to report !ok, got %v, %v", batch, ok)
+	}
+	if err := ch.SendAll([]int{6}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+// TestAll tests ranging over the channel until it is closed and drained
+func TestAll(t *testing.T) {
+	ch := NewChannel[int](3)
+	go func() {
+		for i := 1; i <= 5; i++ {
+			ch.Send(i)
+		}
+		ch.Close()
+	}()
+
+	var got []int
+	for v := range ch.All() {
+		got = append(got, v)
+		if v == 2 {
+			break
+		}
+	}
+	for v := range ch.All() {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("Expected all messages across both loops, got %v", got)
+	}
+}
+
+// TestDelayChannel tests that messages only become receivable once their delay has elapsed
+func TestDelayChannel(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	const tick = 10 * time.Millisecond
+	d := NewDelayChannel[string](10, tick, WithClock(fake))
+
+	if err := d.Send("now", 0); err != nil {
+		t.Fatalf("Send() returned error: %v", err)
+	}
+	d.Send("late", time.Second) // 100 ticks: goes round the 64-slot wheel once
+	d.Send("second", 25*time.Millisecond)
+	d.Send("first", 5*time.Millisecond)
+	if msg, _ := d.Receive(); msg != "now" {
+		t.Fatalf("Expected the undelayed message first, got %q", msg)
+	}
+
+	due := map[int]string{1: "first", 3: "second", 100: "late"}
+	for ticks := 1; ticks <= 100; ticks++ {
+		for fake.Pending() == 0 {
+			time.Sleep(time.Millisecond) // wait for the wheel to arm its timer
+		}
+		if n := d.out.Len(); n != 0 {
+			t.Fatalf("Expected nothing receivable before tick %d, got %d messages", ticks, n)
+		}
+		fake.Advance(tick)
+		if want, ok := due[ticks]; ok {
+			if msg, _ := d.Receive(); msg != want {
+				t.Errorf("Expected %q after %d ticks, got %q", want, ticks, msg)
+			}
+		}
+	}
+	if n := d.Pending(); n != 0 {
+		t.Errorf("Expected no pending messages, got %d", n)
+	}
+
+	d.Send("dropped", time.Hour)
+	d.Close()
+	if _, ok := d.Receive(); ok {
+		t.Error("Expected a closed DelayChannel to discard pending messages")
+	}
+	if err := d.Send("x", time.Second); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+// TestMerge tests fanning in several channels until all of them are closed
+func TestMerge(t *testing.T) {
+	a, b := NewChannel[int](1), NewChannel[int](0)
+	merged := Merge(a, b)
+	go func() {
+		for i := range 3 {
+			a.Send(i)
+		}
+		a.Close()
+	}()
+	go func() {
+		for i := 10; i < 13; i++ {
+			b.Send(i)
+		}
+		b.Close()
+	}()
+
+	var got []int
+	for v := range merged.All() {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []int{0, 1, 2, 10, 11, 12}) {
+		t.Errorf("Expected every message from both sources, got %v", got)
+	}
+
+	if _, ok := Merge[int]().Receive(); ok {
+		t.Error("Expected merging no channels to yield a closed channel")
+	}
+}
+
+// TestTee tests that every branch receives every message in order
+func TestTee(t *testing.T) {
+	src := NewChannel[int](0)
+	branches := Tee(src, 3, WithBranchBuffer(2))
+	go func() {
+		src.SendAll([]int{1, 2, 3, 4})
+		src.Close()
+	}()
+
+	var wg sync.WaitGroup
+	results := make([][]int, len(branches))
+	for i, branch := range branches {
+		wg.Go(func() {
+			for v := range branch.All() {
+				results[i] = append(results[i], v)
+			}
+		})
+	}
+	wg.Wait()
+	for i, got := range results {
+		if !slices.Equal(got, []int{1, 2, 3, 4}) {
+			t.Errorf("Branch %d: expected [1 2 3 4], got %v", i, got)
+		}
+	}
+}
+
+// TestTeeDropOverflow tests that a stalled branch does not hold up the others
+func TestTeeDropOverflow(t *testing.T) {
+	src := NewChannel[int](0)
+	branches := Tee(src, 2, WithBranchBuffer(1), WithBranchOverflow(OverflowDropNewest))
+	go func() {
+		src.SendAll([]int{1, 2, 3})
+		src.Close()
+	}()
+
+	// Only the first branch is read while src is being forwarded
+	var got []int
+	for v := range branches[0].All() {
+		got = append(got, v)
+	}
+	if len(got) == 0 || got[0] != 1 {
+		t.Errorf("Expected the read branch to receive from the start, got %v", got)
+	}
+	if got, _ := branches[1].ReceiveN(3); !slices.Equal(got, []int{1}) {
+		t.Errorf("Expected the stalled branch to keep only its first message, got %v", got)
+	}
+}
+
+// TestOperators tests composing a pipeline out of MapChannel, FilterChannel and FlatMapChannel
+func TestOperators(t *testing.T) {
+	src := NewChannel[int](2)
+	go func() {
+		src.SendAll([]int{1, 2, 3, 4, 5})
+		src.Close()
+	}()
+
+	even := FilterChannel(src, func(v int) bool { return v%2 == 0 })
+	twice := FlatMapChannel(even, func(v int) []int { return []int{v, v} })
+	labels := MapChannel(twice, func(v int) string { return strconv.Itoa(v * 10) })
+
+	var got []string
+	for v := range labels.All() {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []string{"20", "20", "40", "40"}) {
+		t.Errorf("Expected [20 20 40 40], got %v", got)
+	}
+}
+
+// TestNativeBridge tests round-tripping messages through a native channel
+func TestNativeBridge(t *testing.T) {
+	native := make(chan int, 2)
+	go func() {
+		for i := range 4 {
+			native <- i
+		}
+		close(native)
+	}()
+
+	var got []int
+	for v := range FromNative(native).ToNative() {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("Expected [0 1 2 3], got %v", got)
+	}
+
+	ch := NewChannel[string](1)
+	ch.Send("ready")
+	select {
+	case v := <-ch.ToNative():
+		if v != "ready" {
+			t.Errorf("Expected 'ready', got %q", v)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected ToNative to be usable in a select")
+	}
+	ch.Close()
+}
+
+// TestBroadcastChannel tests that every listener gets every value and sees the close
+func TestBroadcastChannel(t *testing.T) {
+	b := NewBroadcastChannel[int](3)
+	first, second := b.Listen(), b.Listen()
+	leaving := b.Listen()
+	leaving.Close()
+
+	for i := range 3 {
+		if err := b.Send(i); err != nil {
+			t.Fatalf("Send() returned error: %v", err)
+		}
+	}
+	b.Close()
+
+	for i, listener := range []*Channel[int]{first, second} {
+		var got []int
+		for v := range listener.All() {
+			got = append(got, v)
+		}
+		if !slices.Equal(got, []int{0, 1, 2}) {
+			t.Errorf("Listener %d: expected [0 1 2], got %v", i, got)
+		}
+	}
+	if _, ok := b.Listen().Receive(); ok {
+		t.Error("Expected Listen after Close to return a closed channel")
+	}
+	if err := b.Send(3); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+// TestBroadcastBlockedSend tests that a Send blocked on a full listener holds up
+// neither Listen nor Close, and that Close releases it
+func TestBroadcastBlockedSend(t *testing.T) {
+	b := NewBroadcastChannel[int](0)
+	slow := b.Listen()
+	sent := make(chan error)
+	go func() { sent <- b.Send(1) }()
+	for slow.WaitingSenders() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	late := b.Listen()
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+	if err := <-sent; err != nil {
+		t.Errorf("Send() returned error: %v", err)
+	}
+	if _, ok := late.Receive(); ok {
+		t.Error("Expected the late listener to be closed without values")
+	}
+}
+
+// TestCloseWithError tests that the close reason reaches receivers after the buffer drains
+func TestCloseWithError(t *testing.T) {
+	cause := errors.New("upstream failed")
+	src := NewChannel[int](2)
+	src.Send(1)
+	src.CloseWithError(cause)
+
+	doubled := MapChannel(src, func(v int) int { return v * 2 })
+	if v, err := doubled.ReceiveErr(); v != 2 || err != nil {
+		t.Errorf("Expected buffered 2 before the error, got %d, %v", v, err)
+	}
+	if _, err := doubled.ReceiveErr(); !errors.Is(err, cause) {
+		t.Errorf("Expected the cause to propagate through MapChannel, got %v", err)
+	}
+
+	plain := NewChannel[int](0)
+	plain.Close()
+	if _, err := plain.ReceiveErr(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after a plain Close, got %v", err)
+	}
+}
+
+// TestResize tests growing and shrinking the buffer at runtime
+func TestResize(t *testing.T) {
+	ch := NewChannel[int](1)
+	ch.Send(1)
+	sent := make(chan error)
+	go func() { sent <- ch.SendAll([]int{2, 3}) }()
+
+	// Growing the buffer releases the blocked sender
+	if err := ch.Resize(3); err != nil {
+		t.Fatalf("Resize(3) returned error: %v", err)
+	}
+	if err := <-sent; err != nil {
+		t.Fatalf("SendAll() returned error: %v", err)
+	}
+	if ch.Cap() != 3 || ch.Len() != 3 {
+		t.Errorf("Expected len 3 and cap 3, got %d and %d", ch.Len(), ch.Cap())
+	}
+
+	// ShrinkBlock: the excess stays receivable, senders wait for the buffer to drain
+	if err := ch.Resize(1); err != nil {
+		t.Fatalf("Resize(1) returned error: %v", err)
+	}
+	if err := ch.SendContext(shortContext(t), 4); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Send to block after shrinking, got %v", err)
+	}
+	if got, _ := ch.ReceiveN(3); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], got %v", got)
+	}
+
+	strict := NewChannel[int](2, WithShrink(ShrinkReject))
+	strict.SendAll([]int{1, 2})
+	if err := strict.Resize(1); !errors.Is(err, ErrShrink) || strict.Cap() != 2 {
+		t.Errorf("Expected ErrShrink and unchanged capacity, got %v and %d", err, strict.Cap())
+	}
+}
+
+// TestStats tests the operation counters and blocked time against the channel's clock
+func TestStats(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	ch := NewChannel[int](1, WithClock(fake))
+
+	received := make(chan int)
+	go func() {
+		v, _ := ch.Receive()
+		received <- v
+	}()
+	for ch.WaitingReceivers() != 1 {
+		time.Sleep(time.Millisecond) // wait for Receive to park
+	}
+	fake.Advance(3 * time.Second)
+	ch.Send(7)
+	<-received
+
+	ch.Close()
+	ch.Send(8)
+
+	want := Stats{Cap: 1, Sends: 1, Receives: 1, FailedSends: 1, ReceiveBlocked: 3 * time.Second}
+	if got := ch.Stats(); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+// TestWatchdog tests that an operation blocked past the threshold is reported
+func TestWatchdog(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	reports := make(chan StuckReport, 1)
+	ch := NewChannel[int](0, WithClock(fake), WithWatchdog(time.Second, func(r StuckReport) { reports <- r }))
+
+	first := make(chan int)
+	go func() {
+		v, _ := ch.Receive()
+		first <- v
+	}()
+	for fake.Pending() == 0 {
+		time.Sleep(time.Millisecond) // wait for Receive to park and arm the watchdog
+	}
+	fake.Advance(time.Second)
+	r := <-reports
+	if r.Op != "receive" || r.WaitingReceivers != 1 || !strings.Contains(string(r.Stack), "Receive") {
+		t.Errorf("Unexpected report: %v", r)
+	}
+	ch.Send(1)
+	<-first
+
+	// An operation that completes in time disarms its watchdog
+	go ch.Receive()
+	for fake.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ch.Send(2)
+	for fake.Pending() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	fake.Advance(time.Minute)
+	select {
+	case r := <-reports:
+		t.Errorf("Unexpected report for a completed operation: %v", r)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+// TestConflatingChannel tests that only the latest unreceived value is kept
+func TestConflatingChannel(t *testing.T) {
+	c := NewConflatingChannel[int]()
+	for i := 1; i <= 3; i++ {
+		c.Send(i) // Never blocks
+	}
+	if v, ok := c.Receive(); v != 3 || !ok {
+		t.Errorf("Expected latest value 3, got %d (ok=%v)", v, ok)
+	}
+	if n := c.Replaced(); n != 2 {
+		t.Errorf("Expected 2 replaced values, got %d", n)
+	}
+
+	received := make(chan int)
+	go func() {
+		v, _ := c.Receive()
+		received <- v
+	}()
+	c.Send(4)
+	if v := <-received; v != 4 {
+		t.Errorf("Expected blocked Receive to get 4, got %d", v)
+	}
+
+	c.Send(5)
+	c.Close()
+	if v, ok := c.Receive(); v != 5 || !ok {
+		t.Errorf("Expected the last value after Close, got %d (ok=%v)", v, ok)
+	}
+	if _, ok := c.Receive(); ok {
+		t.Error("Expected ok=false once drained")
+	}
+	if err := c.Send(6); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+// TestWaitingCounts tests counting the goroutines parked on the channel
+func TestWaitingCounts(t *testing.T) {
+	ch := NewChannel[int](0)
+	for range 2 {
+		go ch.Send(1)
+	}
+	for ch.WaitingSenders() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if n := ch.WaitingReceivers(); n != 0 {
+		t.Errorf("Expected no waiting receivers, got %d", n)
+	}
+
+	ch.Receive()
+	ch.Receive()
+	if s, r := ch.WaitingSenders(), ch.WaitingReceivers(); s != 0 || r != 0 {
+		t.Errorf("Expected nobody waiting after the handoffs, got %d senders and %d receivers", s, r)
+	}
+
+	// A blocked Select counts as waiting on every channel of its cases
+	other := NewChannel[int](0)
+	selected := make(chan int)
+	go func() {
+		i, _, _ := Select(RecvCase(ch), SendCase(other, 2))
+		selected <- i
+	}()
+	for ch.WaitingReceivers() != 1 || other.WaitingSenders() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	ch.Send(3)
+	if i := <-selected; i != 0 {
+		t.Errorf("Expected the receive case to win, got %d", i)
+	}
+	if r, s := ch.WaitingReceivers(), other.WaitingSenders(); r != 0 || s != 0 {
+		t.Errorf("Expected the Select to have withdrawn, got %d receivers and %d senders", r, s)
+	}
+}
+
+// TestUnbufferedHandoff tests that unbuffered messages pass directly between parked
+// senders and receivers, in order, without ever being buffered
+func TestUnbufferedHandoff(t *testing.T) {
+	ch := NewChannel[int](0)
+
+	// Parked senders are served in order, and each Send returns only once taken
+	sent := make(chan int, 3)
+	for i := range 3 {
+		go func() {
+			ch.Send(i)
+			sent <- i
+		}()
+		for ch.WaitingSenders() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if ch.Len() != 0 || len(sent) != 0 {
+		t.Fatalf("Expected senders parked without buffering, got len %d and %d returned", ch.Len(), len(sent))
+	}
+	for i := range 3 {
+		if v, _ := ch.Receive(); v != i {
+			t.Errorf("Expected %d from the longest parked sender, got %d", i, v)
+		}
+		if v := <-sent; v != i {
+			t.Errorf("Expected Send(%d) to return after its handoff, got Send(%d)", i, v)
+		}
+	}
+
+	// A parked receiver gets the message straight from Send
+	received := make(chan int)
+	go func() {
+		v, _ := ch.Receive()
+		received <- v
+	}()
+	for ch.WaitingReceivers() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	ch.Send(7)
+	if v := <-received; v != 7 || ch.Len() != 0 {
+		t.Errorf("Expected handoff of 7 with nothing buffered, got %d (len %d)", v, ch.Len())
+	}
+
+	// A sender that gives up takes its message with it
+	if err := ch.SendContext(shortContext(t), 8); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+	if _, ok, err := ch.ReceiveContext(shortContext(t)); ok || ch.WaitingSenders() != 0 {
+		t.Errorf("Expected the abandoned message to be gone, got ok=%v err=%v", ok, err)
+	}
+}
+
+// TestDone tests that Done is closed by Close, whenever it was obtained
+func TestDone(t *testing.T) {
+	ch := NewChannel[int](1)
+	done := ch.Done()
+	select {
+	case <-done:
+		t.Fatal("Expected Done to block while the channel is open")
+	default:
+	}
+
+	ch.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expected Done to be closed by Close")
+	}
+	if _, ok := <-ch.Done(); ok {
+		t.Error("Expected Done after Close to be closed")
+	}
+}
+
+// TestSPSCChannel tests ordered delivery through the lock-free ring and close draining
+func TestSPSCChannel(t *testing.T) {
+	c := NewSPSCChannel[int](4)
+	const n = 10000
+	go func() {
+		for i := range n {
+			if err := c.Send(i); err != nil {
+				t.Errorf("Send(%d) returned error: %v", i, err)
+				return
+			}
+		}
+		c.Close()
+	}()
+
+	for want := 0; ; want++ {
+		v, ok := c.Receive()
+		if !ok {
+			if want != n {
+				t.Errorf("Expected %d messages before close, got %d", n, want)
+			}
+			break
+		}
+		if v != want {
+			t.Fatalf("Expected %d, got %d", want, v)
+		}
+	}
+	if err := c.Send(n); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+// TestShardedChannel tests that every message from many producers is received once
+func TestShardedChannel(t *testing.T) {
+	c := NewShardedChannel[int](4, 2)
+	const producers, perProducer = 8, 100
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Go(func() {
+			for i := range perProducer {
+				c.Send(p*perProducer + i)
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		c.Close()
+	}()
+
+	seen := make(map[int]bool)
+	for {
+		v, ok := c.Receive()
+		if !ok {
+			break
+		}
+		if seen[v] {
+			t.Fatalf("Received %d twice", v)
+		}
+		seen[v] = true
+	}
+	if len(seen) != producers*perProducer {
+		t.Errorf("Expected %d messages, got %d", producers*perProducer, len(seen))
+	}
+}
+
+// TestReset tests reusing a closed and drained channel, directly and through a pool
+func TestReset(t *testing.T) {
+	ch := NewChannel[int](1)
+	ch.Send(1)
+	if err := ch.Reset(); !errors.Is(err, ErrNotReusable) {
+		t.Errorf("Expected ErrNotReusable for an open channel, got %v", err)
+	}
+	ch.CloseWithError(errors.New("done"))
+	if err := ch.Reset(); !errors.Is(err, ErrNotReusable) {
+		t.Errorf("Expected ErrNotReusable while messages are buffered, got %v", err)
+	}
+	ch.Receive()
+	if err := ch.Reset(); err != nil {
+		t.Fatalf("Reset() returned error: %v", err)
+	}
+	if err := ch.Send(2); err != nil || ch.Err() != nil || ch.Stats().Sends != 1 {
+		t.Errorf("Expected a fresh open channel, got err=%v closeErr=%v stats=%+v", err, ch.Err(), ch.Stats())
+	}
+
+	pool := NewChannelPool[int](1)
+	reply := pool.Get()
+	reply.Send(3)
+	reply.Close()
+	if pool.Put(reply) {
+		t.Error("Expected Put to refuse a channel with a buffered message")
+	}
+	reply.Receive()
+	if !pool.Put(reply) {
+		t.Error("Expected Put to accept a closed and drained channel")
+	}
+	if err := pool.Get().Send(4); err != nil {
+		t.Errorf("Expected an open channel from the pool, got %v", err)
+	}
+
+	// Only the pool's own channels, at the pool's capacity, go back in
+	foreign := NewChannel[int](1)
+	foreign.Close()
+	if pool.Put(foreign) {
+		t.Error("Expected Put to refuse a channel the pool did not create")
+	}
+	resized := pool.Get()
+	resized.Resize(8)
+	resized.Close()
+	if pool.Put(resized) {
+		t.Error("Expected Put to refuse a resized channel")
+	}
+}
+
+// TestResetSpilled tests that Reset refuses a channel that still has messages spilled
+// to disk, even with nothing left in its in-memory buffer
+func TestResetSpilled(t *testing.T) {
+	ch := NewChannel[int](1, WithSpillover(t.TempDir()))
+	ch.Send(1)
+	ch.Send(2) // Spilled
+	ch.Close()
+	ch.mu.Lock()
+	ch.store.Init() // Only the spilled message is left
+	ch.mu.Unlock()
+	if err := ch.Reset(); !errors.Is(err, ErrNotReusable) {
+		t.Errorf("Expected ErrNotReusable with a spilled message, got %v", err)
+	}
+}
+
+// TestTrySelect tests the default-case behaviour of TrySelect
+func TestTrySelect(t *testing.T) {
+	empty, full := NewChannel[int](0), NewChannel[int](1)
+	full.Send(1)
+
+	if i, v, ok := TrySelect(RecvCase(empty), SendCase(full, 2)); i != -1 || v != nil || ok {
+		t.Errorf("Expected no ready case, got %d, %v, %v", i, v, ok)
+	}
+	if i, v, ok := TrySelect(RecvCase(empty), RecvCase(full)); i != 1 || v != 1 || !ok {
+		t.Errorf("Expected to receive 1 from case 1, got %d, %v, %v", i, v, ok)
+	}
+	if i, _, _ := TrySelect(); i != -1 {
+		t.Errorf("Expected -1 with no cases, got %d", i)
+	}
+}
+
+// TestPrioritySelect tests that the first ready case in declared order always wins
+func TestPrioritySelect(t *testing.T) {
+	control, data := NewChannel[string](10), NewChannel[string](10)
+	for range 5 {
+		data.Send("data")
+		control.Send("control")
+	}
+	for i := range 10 {
+		_, v, _ := PrioritySelect(RecvCase(control), RecvCase(data))
+		if want := map[bool]string{true: "control", false: "data"}[i < 5]; v != want {
+			t.Fatalf("Receive %d: expected %s, got %v", i, want, v)
+		}
+	}
+}
+
+// TestPipe tests copying with a transform, close propagation and context shutdown
+func TestPipe(t *testing.T) {
+	src, dst := NewChannel[int](3), NewChannel[int](3)
+	src.SendAll([]int{1, 2, 3})
+	src.CloseWithError(io.ErrUnexpectedEOF)
+	done := Pipe(src, dst, WithPipeTransform(func(v int) int { return v * 10 }))
+
+	var got []int
+	for v := range dst.All() {
+		got = append(got, v)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected nil once src drained, got %v", err)
+	}
+	if !slices.Equal(got, []int{10, 20, 30}) || !errors.Is(dst.Err(), io.ErrUnexpectedEOF) {
+		t.Errorf("Expected [10 20 30] and the propagated error, got %v and %v", got, dst.Err())
+	}
+
+	// Rate limited on dst's clock, then shut down through the context
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	src, dst = NewChannel[int](3), NewChannel[int](3, WithClock(fake))
+	src.SendAll([]int{1, 2, 3})
+	done = Pipe(src, dst, WithPipeRate[int](2), WithPipeContext[int](ctx))
+	for fake.Pending() == 0 {
+		time.Sleep(time.Millisecond) // first message sent, pipe waiting out the interval
+	}
+	if dst.Len() != 1 {
+		t.Errorf("Expected 1 message before the interval elapsed, got %d", dst.Len())
+	}
+	fake.Advance(500 * time.Millisecond)
+	for dst.Len() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if dst.Err() != nil || dst.Send(0) != nil {
+		t.Error("Expected dst to stay open after a context shutdown")
+	}
+}
+
+// TestPipeRateBounds tests that WithPipeRate treats a non-positive rate as unlimited
+// and never yields an interval below one nanosecond
+func TestPipeRateBounds(t *testing.T) {
+	for perSecond, want := range map[int]time.Duration{
+		-5:            0,
+		0:             0,
+		2:             500 * time.Millisecond,
+		2_000_000_000: time.Nanosecond,
+	} {
+		var cfg pipeConfig[int]
+		WithPipeRate[int](perSecond)(&cfg)
+		if cfg.interval != want {
+			t.Errorf("WithPipeRate(%d): expected interval %v, got %v", perSecond, want, cfg.interval)
+		}
+	}
+
+	src, dst := NewChannel[int](2), NewChannel[int](2)
+	src.SendAll([]int{1, 2})
+	src.Close()
+	if err := <-Pipe(src, dst, WithPipeRate[int](0)); err != nil || dst.Len() != 2 {
+		t.Errorf("Expected an unlimited pipe to copy both messages, got %d (err=%v)", dst.Len(), err)
+	}
+}
+
+// TestSpillover tests that messages beyond capacity go to disk and come back in order
+func TestSpillover(t *testing.T) {
+	dir := t.TempDir()
+	ch := NewChannel[string](2, WithSpillover(dir))
+	for i := range 10 {
+		if err := ch.SendContext(shortContext(t), strconv.Itoa(i)); err != nil {
+			t.Fatalf("Send(%d) returned error: %v", i, err)
+		}
+	}
+	if ch.Len() != 10 || len(ch.Export()) != 2 {
+		t.Errorf("Expected 10 messages with 2 in memory, got len %d and %v", ch.Len(), ch.Export())
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Expected one spill file, got %d", len(files))
+	}
+
+	ch.Close()
+	var got []string
+	for v := range ch.All() {
+		got = append(got, v)
+	}
+	if want := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected the spill file to be removed once drained, got %d files", len(files))
+	}
+
+	if err := NewChannel[func()](0, WithSpillover(dir)).SendAll([]func(){nil, nil}); err == nil {
+		t.Error("Expected an error spilling a message gob cannot encode")
+	}
+}
+
+// TestSpilloverResizeToZero tests that resizing a spilling channel to 0 keeps the
+// buffer of one that spilled messages are read back through
+func TestSpilloverResizeToZero(t *testing.T) {
+	ch := NewChannel[int](2, WithSpillover(t.TempDir()))
+	ch.SendAll([]int{1, 2, 3, 4})
+	if err := ch.Resize(0); err != nil || ch.Cap() != 1 {
+		t.Fatalf("Expected capacity 1 after Resize(0), got %d (err %v)", ch.Cap(), err)
+	}
+	for want := 1; want <= 4; want++ {
+		if v, ok, err := ch.ReceiveContext(shortContext(t)); v != want || !ok || err != nil {
+			t.Fatalf("Expected %d, got %d (ok=%v, err %v)", want, v, ok, err)
+		}
+	}
+}
+
+// TestResults tests carrying values and errors on one channel
+func TestResults(t *testing.T) {
+	errOdd := errors.New("odd input")
+	ch := NewChannel[Result[int]](4)
+	go func() {
+		for i := range 4 {
+			if i%2 == 1 {
+				SendResult(ch, 0, fmt.Errorf("input %d: %w", i, errOdd))
+				continue
+			}
+			SendResult(ch, i*i, nil)
+		}
+		ch.Close()
+	}()
+
+	if v, ok, err := ReceiveResult(ch); v != 0 || !ok || err != nil {
+		t.Errorf("Expected first result 0, got %d (ok=%v, err=%v)", v, ok, err)
+	}
+	values, err := CollectResults(ch)
+	if !slices.Equal(values, []int{4}) || !errors.Is(err, errOdd) || strings.Count(err.Error(), "odd input") != 2 {
+		t.Errorf("Expected [4] and both errors, got %v and %v", values, err)
+	}
+	if _, ok, err := ReceiveResult(ch); ok || err != nil {
+		t.Errorf("Expected ok=false and no error once drained, got ok=%v err=%v", ok, err)
+	}
+}
+
+// TestChunk tests grouping by count and by time window
+func TestChunk(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	src := NewChannel[int](10, WithClock(fake))
+	chunks := Chunk(src, 3, time.Second)
+
+	src.SendAll([]int{1, 2, 3, 4})
+	if got, _ := chunks.Receive(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Expected a full chunk [1 2 3], got %v", got)
+	}
+
+	// 4 waits for the window to close
+	for fake.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	fake.Advance(time.Second)
+	if got, _ := chunks.Receive(); !slices.Equal(got, []int{4}) {
+		t.Errorf("Expected [4] after the window, got %v", got)
+	}
+
+	src.Send(5)
+	src.Close()
+	if got, _ := chunks.Receive(); !slices.Equal(got, []int{5}) {
+		t.Errorf("Expected the short last chunk [5], got %v", got)
+	}
+	if _, ok := chunks.Receive(); ok {
+		t.Error("Expected the chunk channel to be closed")
+	}
+}
+
+// TestCloseAndWait tests waiting for receivers to drain the buffer after Close
+func TestCloseAndWait(t *testing.T) {
+	ch := NewChannel[int](3)
+	ch.SendAll([]int{1, 2, 3})
+	go func() {
+		for range 3 {
+			time.Sleep(time.Millisecond)
+			ch.Receive()
+		}
+	}()
+	if err := ch.CloseAndWait(context.Background()); err != nil || ch.Len() != 0 {
+		t.Errorf("Expected a drained channel, got err=%v len=%d", err, ch.Len())
+	}
+
+	stalled := NewChannel[int](3)
+	stalled.SendAll([]int{1, 2})
+	var abandoned *AbandonedError
+	err := stalled.CloseAndWait(shortContext(t))
+	if !errors.As(err, &abandoned) || abandoned.Count != 2 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected 2 abandoned messages after the deadline, got %v", err)
+	}
+}
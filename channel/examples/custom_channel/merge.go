@@ -0,0 +1,27 @@
+package main
+
+import "sync"
+
+// Merge fans in chs: it returns an unbuffered Channel that receives every message from
+// every source, in arrival order, and is closed once all sources are closed and drained.
+// Each source is forwarded by its own goroutine, so a slow source never holds up the
+// others. Closing the result early stops the forwarding; messages already taken from a
+// source at that point are lost.
+func Merge[G any](chs ...*Channel[G]) *Channel[G] {
+	out := NewChannel[G](0)
+	var wg sync.WaitGroup
+	for _, ch := range chs {
+		wg.Go(func() {
+			for message := range ch.All() {
+				if out.Send(message) != nil {
+					return // The result was closed
+				}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		out.Close() // Already closed if the consumer gave up early
+	}()
+	return out
+}
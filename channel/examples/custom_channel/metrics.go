@@ -0,0 +1,59 @@
+package main
+
+import (
+	"expvar"
+	"time"
+)
+
+// expvarRoot groups every Channel registered with PublishExpvar under a single
+// "custom_channel" entry on /debug/vars.
+var expvarRoot = expvar.NewMap("custom_channel")
+
+// PublishExpvar registers the channel's gauges (len, cap) and counters
+// (sends, receives, dropped, failed_sends, send_blocked_ns, receive_blocked_ns)
+// under custom_channel.<name>.
+func (ch *Channel[G]) PublishExpvar(name string) {
+	expvarRoot.Set(name, expvar.Func(func() any {
+		ch.mu.Lock()
+		defer ch.mu.Unlock()
+		return map[string]int64{
+			"len":                int64(ch.store.Len()),
+			"cap":                int64(ch.capacity),
+			"sends":              ch.sends,
+			"receives":           ch.receives,
+			"dropped":            ch.dropped,
+			"failed_sends":       ch.failedSends,
+			"send_blocked_ns":    int64(ch.sendBlocked),
+			"receive_blocked_ns": int64(ch.receiveBlocked),
+		}
+	}))
+}
+
+// Stats is a snapshot of a Channel's counters, for profiling the balance between
+// producers and consumers.
+type Stats struct {
+	Len, Cap       int
+	Sends          int64         // Messages accepted (including ones evicted later by OverflowDropOldest)
+	Receives       int64         // Messages received
+	Dropped        int64         // Messages discarded by the overflow policy
+	FailedSends    int64         // Sends rejected because the channel was closed
+	SendBlocked    time.Duration // Total time senders spent waiting for buffer space
+	ReceiveBlocked time.Duration // Total time receivers spent waiting for messages
+}
+
+// Stats returns a snapshot of the channel's counters. Blocked times are measured with
+// the channel's clock (see WithClock); waits inside Select are not included.
+func (ch *Channel[G]) Stats() Stats {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return Stats{
+		Len:            ch.bufferedLocked(),
+		Cap:            ch.capacity,
+		Sends:          ch.sends,
+		Receives:       ch.receives,
+		Dropped:        ch.dropped,
+		FailedSends:    ch.failedSends,
+		SendBlocked:    ch.sendBlocked,
+		ReceiveBlocked: ch.receiveBlocked,
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+// ToNative returns a native channel that receives every message from ch, in order,
+// and is closed once ch is closed and drained, so ch can take part in a select.
+// A background goroutine pumps the messages: it owns the one message in flight and
+// keeps running until ch is drained, so the native channel must be read to the end
+// (or ch closed and drained) for it to exit.
+func (ch *Channel[G]) ToNative() <-chan G {
+	native := make(chan G)
+	go func() {
+		defer close(native)
+		for message := range ch.All() {
+			native <- message
+		}
+	}()
+	return native
+}
+
+// FromNative returns a Channel that receives every value from native, in order, and
+// is closed once native is closed. It has native's capacity. Closing the result
+// early stops the background goroutine pumping the values; the value it was sending
+// at that point is lost.
+func FromNative[G any](native <-chan G, opts ...Option) *Channel[G] {
+	ch := NewChannel[G](cap(native), opts...)
+	go func() {
+		defer ch.Close()
+		for message := range native {
+			if ch.Send(message) != nil {
+				return // ch was closed
+			}
+		}
+	}()
+	return ch
+}
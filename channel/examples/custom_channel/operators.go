@@ -0,0 +1,48 @@
+package main
+
+// MapChannel returns a Channel of fn applied to every message received from src, in
+// order. It has src's capacity and is closed, with src's error, once src is closed
+// and drained.
+func MapChannel[G, H any](src *Channel[G], fn func(G) H) *Channel[H] {
+	return pipe(src, func(message G, out *Channel[H]) error {
+		return out.Send(fn(message))
+	})
+}
+
+// FilterChannel returns a Channel of the messages received from src for which pred
+// returns true, in order. It has src's capacity and is closed, with src's error, once
+// src is closed and drained.
+func FilterChannel[G any](src *Channel[G], pred func(G) bool) *Channel[G] {
+	return pipe(src, func(message G, out *Channel[G]) error {
+		if !pred(message) {
+			return nil
+		}
+		return out.Send(message)
+	})
+}
+
+// FlatMapChannel returns a Channel of the messages fn expands every message received
+// from src into, in order. It has src's capacity and is closed, with src's error, once
+// src is closed and drained.
+func FlatMapChannel[G, H any](src *Channel[G], fn func(G) []H) *Channel[H] {
+	return pipe(src, func(message G, out *Channel[H]) error {
+		return out.SendAll(fn(message))
+	})
+}
+
+// pipe starts the goroutine behind an operator: it hands every message from src to
+// stage, which sends its results to the returned Channel, and closes that Channel when
+// src is drained, passing on the error src was closed with. Closing the result early
+// stops the goroutine.
+func pipe[G, H any](src *Channel[G], stage func(G, *Channel[H]) error) *Channel[H] {
+	out := NewChannel[H](src.Cap())
+	go func() {
+		defer func() { out.CloseWithError(src.Err()) }()
+		for message := range src.All() {
+			if stage(message, out) != nil {
+				return // The result was closed
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+type options struct {
+	clock       clock.Clock
+	logger      *slog.Logger
+	metricsName string
+	overflow    Overflow
+	shrink      Shrink
+	watchdog    watchdog
+	spillDir    string
+}
+
+// Overflow selects what Send does when the buffer is full.
+type Overflow int
+
+const (
+	// OverflowBlock waits for space, like a native channel (the default).
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest discards the message being sent; Send returns nil at once.
+	OverflowDropNewest
+	// OverflowDropOldest evicts the oldest buffered message to make room. On an
+	// unbuffered channel with no receiver waiting there is nothing to evict, so the
+	// message being sent is discarded instead.
+	OverflowDropOldest
+)
+
+// Shrink selects what Resize does when the new capacity is below the number of
+// buffered messages.
+type Shrink int
+
+const (
+	// ShrinkBlock applies the new capacity at once; the excess messages stay
+	// receivable and senders block until the buffer drains below it (the default).
+	ShrinkBlock Shrink = iota
+	// ShrinkReject leaves the capacity unchanged and makes Resize return ErrShrink.
+	ShrinkReject
+)
+
+// Option configures a Channel created by NewChannel.
+type Option func(*options)
+
+// WithClock sets the time source used for timeouts and blocking statistics.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// WithLogger sets the logger used for lifecycle events such as Close (debug level).
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithMetrics exports the channel's metrics via expvar under custom_channel.<name>.
+func WithMetrics(name string) Option {
+	return func(o *options) { o.metricsName = name }
+}
+
+// WithOverflow sets the policy for Send on a full buffer. The drop policies suit
+// telemetry-style streams where losing stale data is better than blocking the sender;
+// dropped messages are counted in the "dropped" metric.
+func WithOverflow(policy Overflow) Option {
+	return func(o *options) { o.overflow = policy }
+}
+
+// WithShrink sets the policy for Resize below the current length.
+func WithShrink(policy Shrink) Option {
+	return func(o *options) { o.shrink = policy }
+}
+
+// WithWatchdog calls report for every Send or Receive that stays blocked for longer
+// than threshold, with the goroutine's stack and the channel's state, to help find
+// deadlocks. report runs on its own goroutine, while the operation is still blocked.
+func WithWatchdog(threshold time.Duration, report func(StuckReport)) Option {
+	return func(o *options) { o.watchdog = watchdog{threshold: threshold, report: report} }
+}
+
+// WithSpillover makes Send never block: messages beyond the capacity are gob-encoded
+// to a temporary file in dir ("" for os.TempDir) and read back in order as the buffer
+// drains, so a stalled consumer costs disk instead of memory. It replaces the
+// WithOverflow policy. Messages must be gob-encodable (interface values need
+// gob.Register); a message that is not makes Send fail. The disk I/O happens under the
+// channel's lock, and Len counts the spilled messages too. An unbuffered channel gets
+// a buffer of one, through which spilled messages are read back.
+func WithSpillover(dir string) Option {
+	return func(o *options) { o.overflow, o.spillDir = overflowSpill, dir }
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		clock:  clock.Real,
+		logger: slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// capacity returns the buffer capacity a channel created with capacity gets.
+func (o options) capacity(capacity int) int {
+	if o.overflow == overflowSpill {
+		return max(capacity, 1) // Spilled messages are read back through the buffer
+	}
+	return capacity
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// PipeOption configures a Pipe.
+type PipeOption[G any] func(*pipeConfig[G])
+
+type pipeConfig[G any] struct {
+	ctx       context.Context
+	transform func(G) G
+	interval  time.Duration
+	keepOpen  bool
+}
+
+// WithPipeContext stops the pipe when ctx is done.
+func WithPipeContext[G any](ctx context.Context) PipeOption[G] {
+	return func(c *pipeConfig[G]) { c.ctx = ctx }
+}
+
+// WithPipeTransform applies fn to every message on its way to dst.
+func WithPipeTransform[G any](fn func(G) G) PipeOption[G] {
+	return func(c *pipeConfig[G]) { c.transform = fn }
+}
+
+// WithPipeRate limits the pipe to perSecond messages per second, spaced evenly. A
+// perSecond of zero or less leaves the pipe unlimited; rates above one message per
+// nanosecond are capped at that.
+func WithPipeRate[G any](perSecond int) PipeOption[G] {
+	return func(c *pipeConfig[G]) {
+		if perSecond <= 0 {
+			c.interval = 0
+			return
+		}
+		c.interval = max(time.Second/time.Duration(perSecond), time.Nanosecond)
+	}
+}
+
+// WithPipeKeepOpen leaves dst open when src is closed, e.g. when several pipes feed
+// the same dst.
+func WithPipeKeepOpen[G any]() PipeOption[G] {
+	return func(c *pipeConfig[G]) { c.keepOpen = true }
+}
+
+// Pipe copies every message from src to dst on a background goroutine until src is
+// closed and drained; then it closes dst with src's close error (see CloseWithError)
+// unless WithPipeKeepOpen is given. The returned channel receives the outcome once
+// the goroutine has stopped: nil when src was drained, the context's error when
+// WithPipeContext's ctx was done, or ErrClosed if dst was closed under the pipe.
+// Rate limiting uses dst's clock (see WithClock).
+func Pipe[G any](src, dst *Channel[G], opts ...PipeOption[G]) <-chan error {
+	cfg := pipeConfig[G]{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	result := make(chan error, 1)
+	go func() { result <- cfg.run(src, dst) }()
+	return result
+}
+
+func (c *pipeConfig[G]) run(src, dst *Channel[G]) error {
+	for {
+		message, ok, err := src.ReceiveContext(c.ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if !c.keepOpen {
+				dst.CloseWithError(src.Err())
+			}
+			return nil
+		}
+		if c.transform != nil {
+			message = c.transform(message)
+		}
+		if err := dst.SendContext(c.ctx, message); err != nil {
+			return err
+		}
+		if c.interval > 0 {
+			if err := sleepContext(c.ctx, dst.opts.clock.NewTimer(c.interval)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sleepContext waits for timer to fire or ctx to be done, whichever comes first.
+func sleepContext(ctx context.Context, timer clock.Timer) error {
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotReusable is returned by Reset when the channel is still open, still holds
+// messages, or still has goroutines blocked on it.
+var ErrNotReusable = errors.New("channel is not closed, drained and idle")
+
+// Reset returns a closed and drained channel to a fresh open state with its current
+// capacity and options, clearing the close error and the counters, so it can be used
+// again instead of allocating a new one. The caller must make sure nobody still holds
+// the old channel: a late Send would land in the reused one.
+func (ch *Channel[G]) Reset() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if !ch.close || ch.bufferedLocked() > 0 || ch.senders.Len() > 0 || ch.receivers.Len() > 0 || len(ch.selectors) > 0 {
+		return ErrNotReusable
+	}
+	ch.close, ch.err, ch.done = false, nil, nil
+	ch.sends, ch.receives, ch.dropped, ch.failedSends = 0, 0, 0, 0
+	ch.sendBlocked, ch.receiveBlocked = 0, 0
+	return nil
+}
+
+// ChannelPool recycles Channels of one capacity for hot paths that create a channel
+// per request, such as a reply channel per call.
+type ChannelPool[G any] struct {
+	pool     sync.Pool
+	capacity int // Of the channels the pool creates
+}
+
+// NewChannelPool creates a pool whose channels have the given capacity and options.
+func NewChannelPool[G any](capacity int, opts ...Option) *ChannelPool[G] {
+	p := &ChannelPool[G]{capacity: newOptions(opts).capacity(capacity)}
+	p.pool.New = func() any {
+		ch := NewChannel[G](capacity, opts...)
+		ch.pool = p
+		return ch
+	}
+	return p
+}
+
+// Get returns an open, empty channel, reused if one is available.
+func (p *ChannelPool[G]) Get() *Channel[G] {
+	return p.pool.Get().(*Channel[G])
+}
+
+// Put hands ch back for reuse once it is closed and drained. It reports whether ch
+// was accepted; a channel that cannot be Reset, that this pool did not create, or
+// that was Resized away from the pool's capacity is left to the garbage collector, so
+// Get only ever returns channels with the pool's capacity and options.
+func (p *ChannelPool[G]) Put(ch *Channel[G]) bool {
+	if ch.pool != p || ch.Cap() != p.capacity || ch.Reset() != nil {
+		return false
+	}
+	p.pool.Put(ch)
+	return true
+}
@@ -1,25 +1,63 @@
 package main
 
+import "context"
+
+// Receive returns the next message, blocking until one is available. After Close it
+// keeps returning the buffered messages with ok=true and only reports ok=false (and
+// the zero value) once the buffer is empty, like a native channel.
 func (ch *Channel[G]) Receive() (message G, ok bool) {
-	cond := ch.cond
+	message, ok, _ = ch.ReceiveContext(context.Background())
+	return message, ok
+}
 
-	cond.L.Lock()
-	defer cond.L.Unlock()
+// ReceiveContext is Receive that gives up with ctx.Err() when ctx is cancelled or its
+// deadline expires while waiting for a message. ok reports whether a message was
+// received.
+func (ch *Channel[G]) ReceiveContext(ctx context.Context) (message G, ok bool, err error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.receiveLocked(ctx)
+}
 
-	if ch.close {
-		return message, ok
+// ReceiveErr is Receive that reports the end of the channel as an error: once the
+// channel is closed and drained it returns the error given to CloseWithError, or
+// ErrClosed after a plain Close.
+func (ch *Channel[G]) ReceiveErr() (message G, err error) {
+	message, ok := ch.Receive()
+	if ok {
+		return message, nil
+	}
+	if err = ch.Err(); err == nil {
+		err = ErrClosed
 	}
-	ch.capacity++
-	cond.Broadcast()
+	return message, err
+}
 
-	for ch.store.Len() == 0 {
-		cond.Wait()
+// receiveLocked takes a message without waiting if it can (see takeLocked) or parks
+// until a sender hands one over, the channel is closed, or ctx is done. Must be
+// called with the lock held.
+func (ch *Channel[G]) receiveLocked(ctx context.Context) (message G, ok bool, err error) {
+	if message, ok = ch.takeLocked(); ok || ch.close {
+		return message, ok, nil // A message, or closed and drained
+	}
+	if err = ctx.Err(); err != nil {
+		return message, false, err
 	}
 
-	ch.capacity--
-	item := ch.store.Front()
-	ch.store.Remove(item)
-	message = item.Value.(G)
-	cond.Broadcast()
-	return message, true
+	slot := ch.parkLocked(ch.receivers, message)
+	defer wakeOnDone(ctx, slot.ready)()
+	w := &waiter{op: "receive", blocked: &ch.receiveBlocked}
+	defer w.done()
+	for !slot.done && !ch.close {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		ch.waitLocked(slot.ready, w)
+	}
+	ch.receivers.Remove(slot.elem)
+	if !slot.done {
+		return message, false, err // Closed (the buffer stayed empty while parked), or cancelled
+	}
+	ch.receives++
+	return slot.value, true, nil
 }
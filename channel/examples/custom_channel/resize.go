@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrShrink is returned (wrapped) by Resize under ShrinkReject when more messages are
+// buffered than the new capacity allows.
+var ErrShrink = errors.New("cannot shrink below the buffered length")
+
+// Resize changes the capacity of the buffer. Growing it wakes the senders blocked on
+// a full buffer; shrinking it below the number of buffered messages follows the
+// WithShrink policy. Resizing to 0 makes the channel unbuffered, except with
+// WithSpillover, which keeps a buffer of one to read spilled messages back through.
+func (ch *Channel[G]) Resize(newCap int) error {
+	if newCap < 0 {
+		return fmt.Errorf("negative capacity %d", newCap)
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.close {
+		return ErrClosed
+	}
+	if n := ch.store.Len(); newCap < n && ch.opts.shrink == ShrinkReject {
+		return fmt.Errorf("%w: %d buffered, new capacity %d", ErrShrink, n, newCap)
+	}
+	if ch.spill != nil {
+		newCap = max(newCap, 1)
+	}
+	ch.capacity = newCap
+	ch.refillLocked() // Growing makes room for parked senders
+	ch.notifySelectors()
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import "errors"
+
+// Result carries a value or the error that prevented producing it, so one channel
+// can carry both instead of a data channel paired with an error channel.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// SendResult sends Result{value, err} on ch.
+func SendResult[T any](ch *Channel[Result[T]], value T, err error) error {
+	return ch.Send(Result[T]{Value: value, Err: err})
+}
+
+// ReceiveResult receives the next Result from ch and unpacks it. ok is false (and
+// err nil) once ch is closed and drained.
+func ReceiveResult[T any](ch *Channel[Result[T]]) (value T, ok bool, err error) {
+	result, ok := ch.Receive()
+	return result.Value, ok, result.Err
+}
+
+// CollectResults receives from ch until it is closed and drained. It returns the
+// values of the successful Results, in order, and the errors of the failed ones
+// joined (nil if there were none). The error ch was closed with (see
+// CloseWithError) is included last.
+func CollectResults[T any](ch *Channel[Result[T]]) ([]T, error) {
+	var values []T
+	var errs []error
+	for result := range ch.All() {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+			continue
+		}
+		values = append(values, result.Value)
+	}
+	if err := ch.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return values, errors.Join(errs...)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+)
+
+// SelectDir is the direction of a SelectCase.
+type SelectDir int
+
+const (
+	SelectRecv SelectDir = iota // Receive from Chan
+	SelectSend                  // Send Value on Chan
+)
+
+// SelectCase is one case of a Select call. Build cases with RecvCase and SendCase,
+// which adapt a Channel of any element type to SelectCase[any].
+type SelectCase[G any] struct {
+	Dir   SelectDir
+	Chan  selectable
+	Value G // Value to send for SelectSend cases
+}
+
+// selectable is the type-erased view of a Channel[G] that Select works with.
+type selectable interface {
+	lock()
+	unlock()
+	lockOrder() uint64
+	watchLocked(notify chan struct{}, add bool)
+	trySendLocked(value any) (ok, ready bool)
+	tryRecvLocked() (value any, ok, ready bool)
+	parkCaseLocked(dir SelectDir, value any, sel *selectWait) (unpark func() (value any, done bool))
+}
+
+// selectWait is the state shared by the slots a blocked Select parks on its channels.
+type selectWait struct {
+	fired  atomic.Bool   // A counterpart has claimed one of the slots
+	notify chan struct{} // Wakes the Select; buffered, capacity 1
+}
+
+// claim reports whether the caller won the Select, which then has to complete the
+// case of the slot it found.
+func (sel *selectWait) claim() bool {
+	return sel.fired.CompareAndSwap(false, true)
+}
+
+// signal wakes the Select if it is not already signalled.
+func (sel *selectWait) signal() {
+	select {
+	case sel.notify <- struct{}{}:
+	default:
+	}
+}
+
+// channelIDs numbers Channels so that Select can lock them in a fixed order.
+var channelIDs atomic.Uint64
+
+// RecvCase returns a Select case that receives from ch.
+func RecvCase[G any](ch *Channel[G]) SelectCase[any] {
+	return SelectCase[any]{Dir: SelectRecv, Chan: ch}
+}
+
+// SendCase returns a Select case that sends value on ch.
+func SendCase[G any](ch *Channel[G], value G) SelectCase[any] {
+	return SelectCase[any]{Dir: SelectSend, Chan: ch, Value: value}
+}
+
+// Select blocks until one of cases can proceed, performs it and returns its index,
+// like Go's select statement over native channels. If several cases are ready, one
+// is chosen at random.
+//
+// For a receive case, value and ok are what Receive would return (ok=false once the
+// channel is closed). For a send case, value is nil and ok=false if the channel is
+// closed. With no cases Select blocks forever, like an empty select.
+//
+// Like the runtime's select, Select locks all its channels (in a fixed order, so two
+// Selects cannot deadlock), tries the cases and, if none is ready, parks a handoff
+// slot for every case on its channel before unlocking them. A Send, Receive or other
+// Select that finds one of the slots claims the whole Select and completes that case;
+// the remaining slots are removed when Select wakes up. A notification channel is
+// registered with every Channel as well, so closing or resizing one of them makes
+// Select re-check all cases.
+func Select(cases ...SelectCase[any]) (index int, value any, ok bool) {
+	return selectIn(cases, rand.Perm)
+}
+
+// PrioritySelect is Select that tries the cases in the order given instead of at
+// random: when several are ready, the first one wins. Put control messages before
+// data so a busy data channel cannot delay them. Like any strict priority, a
+// constantly ready early case starves the later ones.
+func PrioritySelect(cases ...SelectCase[any]) (index int, value any, ok bool) {
+	return selectIn(cases, inOrder)
+}
+
+// selectIn implements Select, trying the cases in the order returned by order.
+func selectIn(cases []SelectCase[any], order func(n int) []int) (index int, value any, ok bool) {
+	sel := &selectWait{notify: make(chan struct{}, 1)}
+	chans := lockAll(cases)
+	defer unlockAll(chans)
+	unpark := make([]func() (value any, done bool), len(cases))
+	for {
+		for _, i := range order(len(cases)) {
+			if value, ok, ready := tryLocked(cases[i]); ready {
+				return i, value, ok
+			}
+		}
+		for i, c := range cases {
+			unpark[i] = c.Chan.parkCaseLocked(c.Dir, c.Value, sel)
+		}
+		watchLocked(cases, sel.notify, true)
+		unlockAll(chans)
+		<-sel.notify
+		lockAll(cases)
+		watchLocked(cases, sel.notify, false)
+		index = -1
+		for i, f := range unpark {
+			if v, done := f(); done {
+				index, value = i, v
+			}
+		}
+		if index >= 0 {
+			return index, value, true
+		}
+	}
+}
+
+// lockAll locks every distinct channel of cases, ordered by lockOrder, and returns
+// them for unlockAll.
+func lockAll(cases []SelectCase[any]) []selectable {
+	chans := make([]selectable, len(cases))
+	for i, c := range cases {
+		chans[i] = c.Chan
+	}
+	slices.SortFunc(chans, func(a, b selectable) int { return cmp.Compare(a.lockOrder(), b.lockOrder()) })
+	chans = slices.Compact(chans)
+	for _, c := range chans {
+		c.lock()
+	}
+	return chans
+}
+
+// unlockAll unlocks the channels locked by lockAll.
+func unlockAll(chans []selectable) {
+	for _, c := range chans {
+		c.unlock()
+	}
+}
+
+// TrySelect is Select with a default case: it attempts every case once, in random
+// order, and returns index -1 (value nil, ok false) right away if none is ready.
+func TrySelect(cases ...SelectCase[any]) (index int, value any, ok bool) {
+	for _, i := range rand.Perm(len(cases)) {
+		if value, ok, ready := try(cases[i]); ready {
+			return i, value, ok
+		}
+	}
+	return -1, nil, false
+}
+
+// inOrder returns 0, 1, ..., n-1.
+func inOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// try attempts one case without blocking.
+func try(c SelectCase[any]) (value any, ok, ready bool) {
+	c.Chan.lock()
+	defer c.Chan.unlock()
+	return tryLocked(c)
+}
+
+// tryLocked is try with the lock of c.Chan held.
+func tryLocked(c SelectCase[any]) (value any, ok, ready bool) {
+	if c.Dir == SelectSend {
+		ok, ready = c.Chan.trySendLocked(c.Value)
+		return nil, ok, ready
+	}
+	return c.Chan.tryRecvLocked()
+}
+
+// watchLocked registers (add) or unregisters notify with every channel in cases.
+// Must be called with the locks of all of them held (see lockAll).
+func watchLocked(cases []SelectCase[any], notify chan struct{}, add bool) {
+	for _, c := range cases {
+		c.Chan.watchLocked(notify, add)
+	}
+}
+
+func (ch *Channel[G]) lock()             { ch.mu.Lock() }
+func (ch *Channel[G]) unlock()           { ch.mu.Unlock() }
+func (ch *Channel[G]) lockOrder() uint64 { return ch.id }
+
+// parkCaseLocked parks a slot for a Select case on ch: on senders with value for a
+// send case, on receivers for a receive case. The returned unpark removes it again and
+// reports whether the case was completed, with the received value. Both must be
+// called with the lock held.
+func (ch *Channel[G]) parkCaseLocked(dir SelectDir, value any, sel *selectWait) (unpark func() (value any, done bool)) {
+	queue, message := ch.receivers, *new(G)
+	if dir == SelectSend {
+		queue, message = ch.senders, value.(G)
+	}
+	slot := ch.parkLocked(queue, message)
+	slot.sel = sel
+	return func() (any, bool) {
+		queue.Remove(slot.elem)
+		switch {
+		case !slot.done:
+			return nil, false
+		case dir == SelectSend:
+			return nil, true
+		}
+		ch.receives++
+		return slot.value, true
+	}
+}
+
+// watchLocked adds or removes a Select notification channel. Registrations are
+// counted because the same channel may appear in several cases.
+func (ch *Channel[G]) watchLocked(notify chan struct{}, add bool) {
+	if ch.selectors == nil {
+		ch.selectors = make(map[chan struct{}]int)
+	}
+	if add {
+		ch.selectors[notify]++
+	} else if ch.selectors[notify]--; ch.selectors[notify] <= 0 {
+		delete(ch.selectors, notify)
+	}
+}
+
+// notifySelectors wakes every blocked Select. Must be called with the lock held.
+func (ch *Channel[G]) notifySelectors() {
+	for notify := range ch.selectors {
+		select {
+		case notify <- struct{}{}:
+		default: // Already signalled
+		}
+	}
+}
+
+// trySendLocked sends value if a receiver is parked or there is buffer space.
+func (ch *Channel[G]) trySendLocked(value any) (ok, ready bool) {
+	if ch.close {
+		ch.failedSends++
+		return false, true
+	}
+	if ch.offerLocked(value.(G)) {
+		return true, true
+	}
+	if ch.opts.overflow == OverflowBlock {
+		return false, false
+	}
+	return ch.overflowLocked(value.(G)) == nil, true
+}
+
+// tryRecvLocked receives a buffered value or the value of a parked sender.
+func (ch *Channel[G]) tryRecvLocked() (value any, ok, ready bool) {
+	if message, ok := ch.takeLocked(); ok {
+		return message, true, true
+	}
+	if ch.close {
+		var zero G
+		return zero, false, true
+	}
+	return nil, false, false
+}
@@ -1,18 +1,130 @@
 package main
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when sending on a closed channel.
+var ErrClosed = errors.New("channel is already closed")
 
 func (ch *Channel[G]) Send(message G) error {
-	cond := ch.cond
-	cond.L.Lock()
-	defer cond.L.Unlock()
+	return ch.SendContext(context.Background(), message)
+}
+
+// SendContext is Send that gives up with ctx.Err() when ctx is cancelled or its
+// deadline expires while waiting for buffer space (or a receiver, if unbuffered).
+func (ch *Channel[G]) SendContext(ctx context.Context, message G) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
 	if ch.close {
-		return errors.New("channel is already closed")
+		return ch.closedLocked()
+	}
+	if ch.offerLocked(message) {
+		return nil
+	}
+	if ch.opts.overflow != OverflowBlock {
+		return ch.overflowLocked(message)
+	}
+	return ch.parkSenderLocked(ctx, message)
+}
+
+// parkSenderLocked parks message until a receiver takes it, the channel is closed
+// (ErrClosed) or ctx is done (ctx.Err()). Must be called with the lock held.
+func (ch *Channel[G]) parkSenderLocked(ctx context.Context, message G) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	slot := ch.parkLocked(ch.senders, message)
+	defer wakeOnDone(ctx, slot.ready)()
+	w := &waiter{op: "send", blocked: &ch.sendBlocked}
+	defer w.done()
+	var err error
+	for !slot.done && !ch.close {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		ch.waitLocked(slot.ready, w)
 	}
-	for ch.store.Len() == ch.capacity {
-		cond.Wait()
+	ch.senders.Remove(slot.elem)
+	switch {
+	case slot.done:
+		return nil
+	case ch.close:
+		return ch.closedLocked()
 	}
+	return err
+}
+
+// overflowLocked applies the drop policy to message on a full buffer, or spills it
+// (see WithSpillover). Must be called with the lock held.
+func (ch *Channel[G]) overflowLocked(message G) error {
+	if ch.spill != nil {
+		if err := ch.spill.push(message); err != nil {
+			return err
+		}
+		ch.sends++
+		ch.notifySelectors()
+		return nil
+	}
+	ch.dropped++
+	if ch.opts.overflow == OverflowDropNewest || ch.store.Len() == 0 {
+		return nil
+	}
+	ch.store.Remove(ch.store.Front())
 	ch.store.PushBack(message)
-	cond.Broadcast()
+	ch.sends++
 	return nil
 }
+
+// closedLocked counts a send that failed because the channel is closed and returns
+// ErrClosed. Must be called with the lock held.
+func (ch *Channel[G]) closedLocked() error {
+	ch.failedSends++
+	return ErrClosed
+}
+
+// waiter follows one Send or Receive across its waits on a cond: it adds the time
+// spent waiting to blocked and arms the watchdog (see WithWatchdog) on the first wait.
+type waiter struct {
+	op      string
+	blocked *time.Duration
+	stop    func()
+}
+
+// done disarms the watchdog once the operation has completed or given up.
+func (w *waiter) done() {
+	if w.stop != nil {
+		w.stop()
+	}
+}
+
+// waitLocked waits on cond for w. Must be called with the lock held.
+func (ch *Channel[G]) waitLocked(cond *sync.Cond, w *waiter) {
+	if w.stop == nil && ch.opts.watchdog.threshold > 0 {
+		w.stop = ch.watch(w.op)
+	}
+	start := ch.opts.clock.Now()
+	cond.Wait()
+	*w.blocked += ch.opts.clock.Now().Sub(start)
+}
+
+// full reports whether the buffer holds capacity messages. An unbuffered channel is
+// always full: its messages only pass between parked senders and receivers (see
+// handoff). Must be called with the lock held.
+func (ch *Channel[G]) full() bool {
+	return ch.store.Len() >= ch.capacity
+}
+
+// wakeOnDone arranges for the waiters of cond to be woken when ctx is done, so a
+// cond.Wait loop can notice cancellation (sync.Cond cannot select on ctx.Done()).
+// It returns a function that cancels the arrangement.
+func wakeOnDone(ctx context.Context, cond *sync.Cond) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		cond.L.Lock()
+		cond.Broadcast()
+		cond.L.Unlock()
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import "sync/atomic"
+
+// ShardedChannel is one logical channel spread over several Channels (shards), each
+// with its own lock, so that many producers feeding one consumer stage do not all
+// contend on a single mutex. Send spreads messages over the shards round-robin;
+// Receive scans the shards without blocking, starting at a rotating offset, and only
+// when all are empty waits on all of them at once through Select.
+//
+// Messages from one producer may be received out of order, since consecutive sends
+// can land on different shards. A full shard blocks its sender even if other shards
+// have room.
+type ShardedChannel[G any] struct {
+	shards []*Channel[G]
+	next   atomic.Uint64 // Round-robin position for Send
+	scan   atomic.Uint64 // First shard Receive looks at
+}
+
+// NewShardedChannel creates a ShardedChannel of n shards (at least 1), each
+// buffering up to capacity messages. opts apply to every shard.
+func NewShardedChannel[G any](n, capacity int, opts ...Option) *ShardedChannel[G] {
+	c := &ShardedChannel[G]{shards: make([]*Channel[G], max(n, 1))}
+	for i := range c.shards {
+		c.shards[i] = NewChannel[G](capacity, opts...)
+	}
+	return c
+}
+
+// Send sends message on the next shard, blocking while that shard is full.
+func (c *ShardedChannel[G]) Send(message G) error {
+	return c.shards[c.next.Add(1)%uint64(len(c.shards))].Send(message)
+}
+
+// Receive takes a message from any shard, blocking until one is available. ok is
+// false once every shard is closed and drained.
+func (c *ShardedChannel[G]) Receive() (message G, ok bool) {
+	start := int(c.scan.Add(1) % uint64(len(c.shards)))
+	for i := range c.shards {
+		if message, ok = c.shards[(start+i)%len(c.shards)].tryReceive(); ok {
+			return message, true
+		}
+	}
+
+	cases := make([]SelectCase[any], len(c.shards))
+	for i, shard := range c.shards {
+		cases[i] = RecvCase(shard)
+	}
+	for len(cases) > 0 {
+		i, value, ok := Select(cases...)
+		if ok {
+			return value.(G), true
+		}
+		cases = append(cases[:i], cases[i+1:]...) // Closed and drained
+	}
+	return message, false
+}
+
+// Len returns the number of messages buffered across all shards.
+func (c *ShardedChannel[G]) Len() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Close closes every shard; receivers drain what is buffered.
+func (c *ShardedChannel[G]) Close() error {
+	var err error
+	for _, shard := range c.shards {
+		if closeErr := shard.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// tryReceive takes a message without waiting, if one is available.
+func (ch *Channel[G]) tryReceive() (message G, ok bool) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.takeLocked()
+}
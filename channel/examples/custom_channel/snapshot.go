@@ -0,0 +1,32 @@
+package main
+
+import "fmt"
+
+// Export returns a copy of the messages currently buffered in memory, oldest first,
+// without removing them (spilled messages, see WithSpillover, are not included). Together with NewChannelFromSnapshot it lets the
+// contents be checkpointed during shutdown and restored later (or moved to another
+// channel), and gives tests a deterministic starting state.
+func (ch *Channel[G]) Export() []G {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	items := make([]G, 0, ch.store.Len())
+	for e := ch.store.Front(); e != nil; e = e.Next() {
+		items = append(items, e.Value.(G))
+	}
+	return items
+}
+
+// NewChannelFromSnapshot creates a channel of the given capacity whose buffer already
+// holds items, in order (typically the result of Export). It fails if items do not fit
+// in capacity.
+func NewChannelFromSnapshot[G any](capacity int, items []G, opts ...Option) (*Channel[G], error) {
+	if len(items) > capacity {
+		return nil, fmt.Errorf("snapshot of %d items does not fit in capacity %d", len(items), capacity)
+	}
+	ch := NewChannel[G](capacity, opts...)
+	for _, item := range items {
+		ch.store.PushBack(item)
+	}
+	return ch, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// overflowSpill is the Overflow policy set by WithSpillover.
+const overflowSpill Overflow = -1
+
+// spill is the on-disk tail of a Channel created WithSpillover: a FIFO of
+// gob-encoded messages in a temporary file, each record prefixed by its length.
+// Everything in it is newer than the in-memory buffer, so once it holds a message,
+// new messages go to it too, and the buffer is refilled from it as receivers make
+// room. The file is created on the first spill, truncated whenever it empties, and
+// removed once the channel is closed and drained.
+type spill[G any] struct {
+	dir      string
+	file     *os.File
+	readOff  int64
+	writeOff int64
+	n        int
+}
+
+// push appends message to the file.
+func (s *spill[G]) push(message G) error {
+	if s.file == nil {
+		file, err := os.CreateTemp(s.dir, "custom-channel-spill-*")
+		if err != nil {
+			return fmt.Errorf("spill: %w", err)
+		}
+		s.file = file
+	}
+	var buf bytes.Buffer
+	var prefix [4]byte
+	buf.Write(prefix[:]) // Placeholder for the length
+	if err := gob.NewEncoder(&buf).Encode(&message); err != nil {
+		return fmt.Errorf("spill: encoding message: %w", err)
+	}
+	record := buf.Bytes()
+	binary.BigEndian.PutUint32(record, uint32(len(record)-len(prefix)))
+	if _, err := s.file.WriteAt(record, s.writeOff); err != nil {
+		return fmt.Errorf("spill: %w", err)
+	}
+	s.writeOff += int64(len(record))
+	s.n++
+	return nil
+}
+
+// pop reads back the oldest spilled message. If the file cannot be read, every
+// spilled message is discarded; if just the record cannot be decoded, only it is.
+func (s *spill[G]) pop() (message G, err error) {
+	var prefix [4]byte
+	if _, err = s.file.ReadAt(prefix[:], s.readOff); err != nil {
+		return message, s.discard(err)
+	}
+	record := make([]byte, binary.BigEndian.Uint32(prefix[:]))
+	if _, err = s.file.ReadAt(record, s.readOff+int64(len(prefix))); err != nil {
+		return message, s.discard(err)
+	}
+	s.readOff += int64(len(prefix) + len(record))
+	if s.n--; s.n == 0 {
+		if err := s.reset(); err != nil {
+			return message, err
+		}
+	}
+	if err := gob.NewDecoder(bytes.NewReader(record)).Decode(&message); err != nil {
+		return message, fmt.Errorf("spill: decoding message: %w", err)
+	}
+	return message, nil
+}
+
+// discard drops every spilled message after a read error.
+func (s *spill[G]) discard(err error) error {
+	s.n = 0
+	if resetErr := s.reset(); resetErr != nil {
+		err = resetErr
+	}
+	return fmt.Errorf("spill: %w", err)
+}
+
+// reset empties the file for reuse.
+func (s *spill[G]) reset() error {
+	s.readOff, s.writeOff = 0, 0
+	if err := s.file.Truncate(0); err != nil {
+		return fmt.Errorf("spill: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the file, if any.
+func (s *spill[G]) remove() {
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+		s.file = nil
+	}
+}
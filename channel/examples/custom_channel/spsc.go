@@ -0,0 +1,79 @@
+package main
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// SPSCChannel is a bounded channel for exactly one sending and one receiving
+// goroutine, such as the link between two pipeline stages. It is a ring buffer
+// coordinated with atomics only: the producer alone advances tail and the consumer
+// alone advances head, so neither ever takes a lock. A full or empty buffer is
+// waited out by yielding (runtime.Gosched) instead of parking, which keeps latency
+// low while both sides are busy at the cost of CPU while one of them is idle.
+//
+// Calling Send (or Close) from more than one goroutine, or Receive from more than
+// one goroutine, is a data race.
+type SPSCChannel[G any] struct {
+	buf    []G
+	closed atomic.Bool
+	_      [64]byte     // Keep head and tail on separate cache lines
+	head   atomic.Int64 // Next slot to read; written by the consumer
+	_      [64]byte
+	tail   atomic.Int64 // Next slot to write; written by the producer
+}
+
+// NewSPSCChannel creates an SPSCChannel buffering up to capacity messages. The
+// lock-free ring needs at least one slot, so capacity is raised to 1 if lower.
+func NewSPSCChannel[G any](capacity int) *SPSCChannel[G] {
+	return &SPSCChannel[G]{buf: make([]G, max(capacity, 1))}
+}
+
+// Send appends message, waiting while the buffer is full. It returns ErrClosed after
+// Close.
+func (c *SPSCChannel[G]) Send(message G) error {
+	tail := c.tail.Load()
+	for tail-c.head.Load() == int64(len(c.buf)) {
+		if c.closed.Load() {
+			return ErrClosed
+		}
+		runtime.Gosched()
+	}
+	if c.closed.Load() {
+		return ErrClosed
+	}
+	c.buf[tail%int64(len(c.buf))] = message
+	c.tail.Store(tail + 1) // Publishes the slot to the consumer
+	return nil
+}
+
+// Receive takes the oldest message, waiting while the buffer is empty. After Close
+// it drains the buffer, then returns ok=false.
+func (c *SPSCChannel[G]) Receive() (message G, ok bool) {
+	head := c.head.Load()
+	for head == c.tail.Load() {
+		if c.closed.Load() && head == c.tail.Load() {
+			return message, false
+		}
+		runtime.Gosched()
+	}
+	slot := &c.buf[head%int64(len(c.buf))]
+	message = *slot
+	var zero G
+	*slot = zero           // Don't keep the message reachable
+	c.head.Store(head + 1) // Hands the slot back to the producer
+	return message, true
+}
+
+// Len returns the number of buffered messages.
+func (c *SPSCChannel[G]) Len() int {
+	return int(c.tail.Load() - c.head.Load())
+}
+
+// Close closes the channel. It must be called by the producer, after its last Send.
+func (c *SPSCChannel[G]) Close() error {
+	if c.closed.Swap(true) {
+		return ErrClosed
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+// TeeOption configures the branches created by Tee.
+type TeeOption func(*teeConfig)
+
+type teeConfig struct {
+	capacity int
+	overflow Overflow
+}
+
+// WithBranchBuffer sets the capacity of each branch (0, unbuffered, by default).
+func WithBranchBuffer(capacity int) TeeOption {
+	return func(c *teeConfig) { c.capacity = capacity }
+}
+
+// WithBranchOverflow sets what happens when a branch is full. With OverflowBlock (the
+// default) the slowest branch sets the pace for all of them; with a drop policy a
+// slow branch loses messages instead of holding up the others.
+func WithBranchOverflow(policy Overflow) TeeOption {
+	return func(c *teeConfig) { c.overflow = policy }
+}
+
+// Tee fans out src: every message received from it is sent, in order, to each of the
+// n returned branches. The branches are closed once src is closed and drained. A
+// branch closed by its consumer is skipped from then on.
+func Tee[G any](src *Channel[G], n int, opts ...TeeOption) []*Channel[G] {
+	var cfg teeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	branches := make([]*Channel[G], n)
+	for i := range branches {
+		branches[i] = NewChannel[G](cfg.capacity, WithOverflow(cfg.overflow))
+	}
+
+	go func() {
+		for message := range src.All() {
+			for _, branch := range branches {
+				branch.Send(message) // ErrClosed: the consumer gave up on this branch
+			}
+		}
+		for _, branch := range branches {
+			branch.Close()
+		}
+	}()
+	return branches
+}
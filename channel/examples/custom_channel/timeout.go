@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is returned by SendTimeout and ReceiveTimeout when d elapses first.
+// It wraps context.DeadlineExceeded.
+var ErrTimeout = fmt.Errorf("channel operation timed out: %w", context.DeadlineExceeded)
+
+// SendTimeout is Send bounded to d. The timeout is measured on the channel's clock
+// (WithClock), so tests can drive it with a clock.FakeClock.
+func (ch *Channel[G]) SendTimeout(message G, d time.Duration) error {
+	ctx, cancel := ch.timeoutContext(d)
+	defer cancel()
+	return timeoutErr(ctx, ch.SendContext(ctx, message))
+}
+
+// ReceiveTimeout is Receive bounded to d; see SendTimeout.
+func (ch *Channel[G]) ReceiveTimeout(d time.Duration) (G, bool, error) {
+	ctx, cancel := ch.timeoutContext(d)
+	defer cancel()
+	message, ok, err := ch.ReceiveContext(ctx)
+	return message, ok, timeoutErr(ctx, err)
+}
+
+// timeoutContext returns a context cancelled with ErrTimeout once the channel's clock
+// has advanced by d. A timer goroutine bridges the clock to the context, which is what
+// wakes the sync.Cond wait loop (see wakeOnDone).
+func (ch *Channel[G]) timeoutContext(d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	timer := ch.opts.clock.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(ErrTimeout)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		timer.Stop() // Synchronously, so the clock has no stale timer once the call returns
+		cancel(context.Canceled)
+	}
+}
+
+// timeoutErr reports ErrTimeout instead of the generic context error.
+func timeoutErr(ctx context.Context, err error) error {
+	if err != nil && err == ctx.Err() {
+		return context.Cause(ctx)
+	}
+	return err
+}
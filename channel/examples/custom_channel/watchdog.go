@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+type watchdog struct {
+	threshold time.Duration
+	report    func(StuckReport)
+}
+
+// StuckReport describes a Send or Receive that has been blocked for longer than the
+// WithWatchdog threshold.
+type StuckReport struct {
+	Op               string        // "send" or "receive"
+	Blocked          time.Duration // The threshold that was exceeded
+	Stack            []byte        // Stack of the blocked goroutine, captured when it started waiting
+	Stats            Stats
+	WaitingSenders   int
+	WaitingReceivers int
+}
+
+// String formats the report as a state dump followed by the stack.
+func (r StuckReport) String() string {
+	return fmt.Sprintf("custom channel: %s blocked for over %v (len %d/%d, %d senders and %d receivers waiting)\n%s",
+		r.Op, r.Blocked, r.Stats.Len, r.Stats.Cap, r.WaitingSenders, r.WaitingReceivers, r.Stack)
+}
+
+// watch arms the watchdog for an operation about to wait and returns the function
+// that disarms it. Must be called with the lock held, from the waiting goroutine.
+func (ch *Channel[G]) watch(op string) (stop func()) {
+	stack := make([]byte, 16<<10)
+	stack = stack[:runtime.Stack(stack, false)]
+	timer := ch.opts.clock.NewTimer(ch.opts.watchdog.threshold)
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-timer.C():
+		case <-stopped:
+			return
+		}
+		ch.opts.watchdog.report(StuckReport{
+			Op:               op,
+			Blocked:          ch.opts.watchdog.threshold,
+			Stack:            stack,
+			Stats:            ch.Stats(),
+			WaitingSenders:   ch.WaitingSenders(),
+			WaitingReceivers: ch.WaitingReceivers(),
+		})
+	}()
+	return func() {
+		timer.Stop()
+		close(stopped)
+	}
+}
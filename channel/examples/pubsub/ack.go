@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Defaults for SubscribeAcked when WithAckTimeout or WithMaxDeliveries is not given.
+const (
+	defaultAckTimeout    = 30 * time.Second
+	defaultMaxDeliveries = 5
+)
+
+// ErrMaxDeliveries is the DeadLetter reason for messages of an acked subscription that
+// were delivered WithMaxDeliveries times without being acknowledged.
+var ErrMaxDeliveries = errors.New("delivery attempts exhausted")
+
+// errAckedOptions is returned when WithCatchUp or WithCredits is used with SubscribeAcked.
+var errAckedOptions = errors.New("catch-up mode and credits do not apply to acked subscriptions")
+
+// WithAckTimeout sets how long an acked subscription (SubscribeAcked) waits for Ack or
+// Nack before delivering a message again (30s by default).
+func WithAckTimeout(timeout time.Duration) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.ackTimeout = timeout
+	}
+}
+
+// WithMaxDeliveries caps how many times an acked subscription (SubscribeAcked) delivers
+// a message (5 by default). A message still unacknowledged after the last attempt goes
+// to the dead-letter topic (see WithDeadLetters) with ErrMaxDeliveries.
+func WithMaxDeliveries(n int) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.maxDeliveries = n
+	}
+}
+
+// Delivery is one delivery attempt of a message to an acked subscription. The consumer
+// settles it with Ack once processed, or Nack to have it delivered again right away.
+type Delivery[T any] struct {
+	Message[T]
+	Attempt int // 1 for the first delivery, incremented on every redelivery
+
+	seq   uint64          // Identifies the message across attempts
+	acks  chan<- ackEvent // The subscription's ack loop
+	done  <-chan struct{} // Closed when the subscription ends
+	dedup *dedupWindow    // Records acked IDs (WithDedup), nil when off
+}
+
+// ackEvent settles the message seq, successfully (Ack) or not (Nack).
+type ackEvent struct {
+	seq uint64
+	ok  bool
+}
+
+// Ack marks the message as processed: it is not delivered again. Settling an attempt
+// after the message was redelivered settles the redelivery too.
+func (d *Delivery[T]) Ack() { d.settle(true) }
+
+// Nack hands the message back for redelivery without waiting for the ack timeout.
+func (d *Delivery[T]) Nack() { d.settle(false) }
+
+func (d *Delivery[T]) settle(ok bool) {
+	if ok {
+		d.dedup.ack(d.ID) // Before Ack returns, so a copy published next is filtered
+	}
+	select {
+	case d.acks <- ackEvent{seq: d.seq, ok: ok}:
+	case <-d.done: // Subscription closed, nothing to settle
+	}
+}
+
+// AckedSubscription is a subscription with at-least-once delivery (see SubscribeAcked).
+type AckedSubscription[T any] struct {
+	C <-chan *Delivery[T] // Deliveries, closed by Close or CloseTopic
+
+	sub *Subscription[T]
+}
+
+// Close ends the subscription and closes C. Unacknowledged messages are abandoned.
+func (s *AckedSubscription[T]) Close() error {
+	return s.sub.Close()
+}
+
+// SubscribeAcked subscribes to topic with at-least-once delivery: every message must be
+// acknowledged with Delivery.Ack, and a message that is not (Nack, or no answer within
+// WithAckTimeout) is delivered again, up to WithMaxDeliveries times. Redeliveries join
+// the back of the line, so they may arrive after newer messages.
+//
+// At most the buffer size (WithDefaultBuffer) messages are unacknowledged or waiting
+// to be read at any time; beyond that the publisher sees a full subscriber, handled by
+// the subscription's overflow policy (WithOverflow).
+//
+// Usage example:
+//
+//	sub, err := pub.SubscribeAcked("jobs", WithAckTimeout(time.Minute))
+//	if err != nil { ... }
+//	for d := range sub.C {
+//		if err := process(d.Value); err != nil {
+//			d.Nack()
+//			continue
+//		}
+//		d.Ack()
+//	}
+func (p *Publisher[T]) SubscribeAcked(topic string, opts ...SubscribeOption) (*AckedSubscription[T], error) {
+	if err := p.authorizeSubscribe(Anonymous, topic); err != nil {
+		return nil, err
+	}
+	settings := newSubscribeConfig(opts)
+	if settings.catchUpBatch > 0 || settings.credits {
+		return nil, errAckedOptions
+	}
+	if settings.ackTimeout <= 0 {
+		settings.ackTimeout = defaultAckTimeout
+	}
+	if settings.maxDeliveries <= 0 {
+		settings.maxDeliveries = defaultMaxDeliveries
+	}
+
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.subscribers[topic]; !ok {
+		return nil, errors.New("topic not found")
+	}
+	live := make(chan Message[T], p.config.buffer)
+	sub := &subscriber[T]{msgs: live, done: make(chan struct{}), buffered: p.config.buffer}
+	if err := p.addSubscriberLocked(s, topic, sub, settings); err != nil {
+		return nil, err
+	}
+	if sub.dedup != nil {
+		sub.dedup.onAck = true // Set before the lock lets a publish reach sub
+	}
+	out := make(chan *Delivery[T])
+	p.goroutines.Go(func() { p.ackLoop(topic, sub, out, settings) })
+	return &AckedSubscription[T]{C: out, sub: &Subscription[T]{pub: p, topic: topic, sub: sub}}, nil
+}
+
+// ackLoop hands the messages of an acked subscriber to out and redelivers the ones
+// that are not acknowledged in time. It closes out when the subscriber is removed.
+//
+// Go Concurrency Patterns used:
+//   - Single owner goroutine: the in-flight table is only touched here, so Ack, Nack
+//     and timeouts are serialized by the select instead of a mutex
+//   - Nil channel cases: receiving is disabled while the window is full and sending
+//     while nothing is ready, which turns the select into a small state machine
+//   - One timer for all deadlines: it is re-armed to the earliest in-flight deadline
+func (p *Publisher[T]) ackLoop(topic string, sub *subscriber[T], out chan<- *Delivery[T], settings subscribeConfig) {
+	defer close(out)
+	acks := make(chan ackEvent)
+	window := max(cap(sub.msgs), 1)
+	var ready []*Delivery[T]               // Waiting to be read, oldest first
+	inflight := make(map[uint64]time.Time) // Read but not settled, with their deadline
+	sent := make(map[uint64]*Delivery[T])  // Latest attempt of each in-flight message
+	var seq uint64
+
+	timer := p.config.clock.NewTimer(settings.ackTimeout)
+	defer timer.Stop()
+
+	retry := func(d *Delivery[T]) {
+		if !sub.dedup.admit(d.ID) {
+			p.metrics.deduplicated.Add(1) // Another copy was acked meanwhile
+			return
+		}
+		if d.Attempt >= settings.maxDeliveries {
+			p.deadLetter(topic, sub, d.Message, ErrMaxDeliveries)
+			return
+		}
+		p.metrics.redelivered.Add(1)
+		again := *d
+		again.Attempt++
+		ready = append(ready, &again)
+	}
+
+	for {
+		var in <-chan Message[T]
+		if len(ready)+len(inflight) < window {
+			in = sub.msgs
+		}
+		var send chan<- *Delivery[T]
+		var next *Delivery[T]
+		if len(ready) > 0 {
+			send, next = out, ready[0]
+		}
+
+		select {
+		case msg, ok := <-in:
+			if !ok {
+				return
+			}
+			seq++
+			ready = append(ready, &Delivery[T]{Message: msg, Attempt: 1, seq: seq, acks: acks, done: sub.done, dedup: sub.dedup})
+		case send <- next:
+			ready = ready[1:]
+			if len(inflight) == 0 {
+				timer.Reset(settings.ackTimeout)
+			}
+			inflight[next.seq] = p.config.clock.Now().Add(settings.ackTimeout)
+			sent[next.seq] = next
+		case e := <-acks:
+			d, ok := sent[e.seq]
+			if !ok {
+				if e.ok { // Its redelivery is not read yet: no need to send it
+					ready = slices.DeleteFunc(ready, func(r *Delivery[T]) bool { return r.seq == e.seq })
+				}
+				continue
+			}
+			delete(inflight, e.seq)
+			delete(sent, e.seq)
+			if !e.ok {
+				retry(d)
+			}
+		case <-timer.C():
+			now := p.config.clock.Now()
+			var earliest time.Time
+			for s, deadline := range inflight {
+				if !deadline.After(now) {
+					retry(sent[s])
+					delete(inflight, s)
+					delete(sent, s)
+				} else if earliest.IsZero() || deadline.Before(earliest) {
+					earliest = deadline
+				}
+			}
+			if !earliest.IsZero() {
+				timer.Reset(earliest.Sub(now))
+			}
+		case <-sub.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// TestAckNack tests that a nacked message is delivered again and an acked one is not
+func TestAckNack(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(2))
+	pub.CreateTopic("jobs")
+	sub, err := pub.SubscribeAcked("jobs")
+	if err != nil {
+		t.Fatalf("SubscribeAcked() returned error: %v", err)
+	}
+	defer sub.Close()
+
+	pub.Publish("jobs", "j1")
+	d := <-sub.C
+	d.Nack()
+	again := <-sub.C
+	if again.Value != "j1" || again.Attempt != 2 {
+		t.Errorf("Expected j1 on attempt 2, got %s on attempt %d", again.Value, again.Attempt)
+	}
+	again.Ack()
+
+	pub.Publish("jobs", "j2")
+	if d := <-sub.C; d.Value != "j2" || d.Attempt != 1 {
+		t.Errorf("Expected j2 on attempt 1 after the ack, got %s on attempt %d", d.Value, d.Attempt)
+	}
+	if n := pub.metrics.redelivered.Load(); n != 1 {
+		t.Errorf("Expected 1 redelivery counted, got %d", n)
+	}
+}
+
+// TestAckTimeout tests redelivery after the ack timeout and dead-lettering once the
+// delivery attempts are exhausted
+func TestAckTimeout(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(1, 0))
+	pub := NewPublisher[string](WithClock(fake), WithDefaultBuffer(2), WithDeadLetters())
+	dead, _ := pub.SubscribeDeadLetters()
+	pub.CreateTopic("jobs")
+	sub, _ := pub.SubscribeAcked("jobs", WithAckTimeout(time.Second), WithMaxDeliveries(2))
+	defer sub.Close()
+
+	pub.Publish("jobs", "j1")
+	pub.Publish("jobs", "j2")
+	d1 := <-sub.C
+	d2 := <-sub.C
+	d2.Ack() // The ack loop handles it after recording both deliveries
+
+	fake.Advance(time.Second)
+	again := <-sub.C
+	if again.Value != "j1" || again.Attempt != 2 {
+		t.Errorf("Expected j1 redelivered on attempt 2, got %s on attempt %d", again.Value, again.Attempt)
+	}
+	d1.Ack() // Late ack of attempt 1 is fine, it is the same message
+	d1.Ack() // Settled: ignored, but syncs with the ack loop
+	if n := len(dead); n != 0 {
+		t.Errorf("Expected no dead letters after the ack, got %d", n)
+	}
+
+	pub.Publish("jobs", "j3")
+	for range 2 { // Never acked
+		<-sub.C
+		d2.Ack()
+		fake.Advance(time.Second)
+	}
+	letter := <-dead
+	if !errors.Is(letter.Reason, ErrMaxDeliveries) || letter.Message.Value != "j3" {
+		t.Errorf("Expected j3 dead-lettered with ErrMaxDeliveries, got %+v", letter)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrSubscriberNotFound is returned by ForceUnsubscribe when the topic has no
+// subscriber with the given id.
+var ErrSubscriberNotFound = errors.New("subscriber not found")
+
+// QuotaError is the error returned when an operation would exceed a quota
+// (WithMaxTopics, WithMaxSubscribers or WithMaxBuffered). It wraps ErrNamespaceLimit,
+// so errors.Is(err, ErrNamespaceLimit) holds; errors.As gives the details.
+type QuotaError struct {
+	Quota     string // Option that set the quota, e.g. "WithMaxTopics"
+	Namespace string // Namespace path, empty for the root Publisher
+	Topic     string // Topic the operation was on, empty for topic counts
+	Limit     int64  // The quota
+	Used      int64  // Usage before the operation
+}
+
+func (e *QuotaError) Error() string {
+	scope := fmt.Sprintf("namespace %q", e.Namespace)
+	if e.Topic != "" {
+		scope = fmt.Sprintf("topic %q", strings.TrimPrefix(e.Namespace+"/"+e.Topic, "/"))
+	}
+	return fmt.Sprintf("%v: %s at %d of %d (%s)", ErrNamespaceLimit, scope, e.Used, e.Limit, e.Quota)
+}
+
+func (e *QuotaError) Unwrap() error { return ErrNamespaceLimit }
+
+// WithMaxSubscribers caps the number of subscribers of each topic; subscribing beyond
+// it fails with a QuotaError.
+func WithMaxSubscribers(n int) NamespaceOption {
+	return func(l *namespaceLimits) {
+		l.maxSubscribers = n
+	}
+}
+
+// SetQuotas applies quotas to p itself, the root Publisher or a namespace view: for
+// example WithMaxTopics caps the topics of the whole Publisher when p is the root.
+// Quotas only apply to later operations; topics and subscribers beyond a lowered quota
+// are left alone.
+//
+// Usage example:
+//
+//	pub.SetQuotas(WithMaxTopics(100), WithMaxSubscribers(50))
+func (p *Publisher[T]) SetQuotas(opts ...NamespaceOption) {
+	p.Lock() // Serializes limit changes, readers load the limits without locking
+	defer p.Unlock()
+	limits := *p.limits.Load()
+	for _, opt := range opts {
+		opt(&limits)
+	}
+	p.limits.Store(&limits)
+}
+
+// Subscribers returns the ids of topic's subscribers, in subscription order. The ids
+// are those of SubscriberStats and OnSubscribe, and ForceUnsubscribe takes them.
+func (p *Publisher[T]) Subscribers(topic string) ([]uint64, error) {
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.RLock()
+	defer s.RUnlock()
+
+	subscribers, ok := s.subscribers[topic]
+	if !ok {
+		return nil, errors.New("topic not found")
+	}
+	ids := make([]uint64, len(subscribers))
+	for i, sub := range subscribers {
+		ids[i] = sub.id
+	}
+	return ids, nil
+}
+
+// ForceUnsubscribe removes the subscriber with the given id from topic, as if its
+// owner had closed it: its channel is closed, so its consumer sees the end of the
+// stream, and the messages still buffered are dropped. It is meant for operators
+// ejecting a misbehaving client; the client may subscribe again unless an Authorizer
+// or a quota stops it.
+func (p *Publisher[T]) ForceUnsubscribe(topic string, id uint64) error {
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	subscribers, ok := s.subscribers[topic]
+	if !ok {
+		return errors.New("topic not found")
+	}
+	i := slices.IndexFunc(subscribers, func(sub *subscriber[T]) bool { return sub.id == id })
+	if i < 0 {
+		return fmt.Errorf("%w: %d on %q", ErrSubscriberNotFound, id, topic)
+	}
+	p.dropSubscriberLocked(s, topic, subscribers[i])
+	p.config.logger.Info("pubsub: subscriber removed by an operator", "topic", p.qualified(topic), "subscriber", id)
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestForceUnsubscribe tests that an operator can list a topic's subscribers and
+// remove one of them by id
+func TestForceUnsubscribe(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	pub.CreateTopic("chat")
+	first, _ := pub.Subscribe("chat")
+	second, _ := pub.Subscribe("chat")
+
+	ids, err := pub.Subscribers("chat")
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("Subscribers() = %v, %v", ids, err)
+	}
+	if err := pub.ForceUnsubscribe("chat", ids[0]); err != nil {
+		t.Fatalf("ForceUnsubscribe() returned error: %v", err)
+	}
+	if _, ok := <-first; ok {
+		t.Error("Expected the removed subscriber's channel to be closed")
+	}
+	pub.Publish("chat", "hello")
+	if got := <-second; got != "hello" {
+		t.Errorf("Expected the other subscriber to get 'hello', got %q", got)
+	}
+	if left, _ := pub.Subscribers("chat"); !slices.Equal(left, ids[1:]) {
+		t.Errorf("Expected subscribers %v, got %v", ids[1:], left)
+	}
+	if err := pub.ForceUnsubscribe("chat", ids[0]); !errors.Is(err, ErrSubscriberNotFound) {
+		t.Errorf("Expected ErrSubscriberNotFound, got %v", err)
+	}
+	if _, err := pub.Subscribers("missing"); err == nil {
+		t.Error("Expected an error for an unknown topic")
+	}
+}
+
+// TestSetQuotas tests that the root Publisher enforces topic and subscriber quotas with
+// QuotaErrors
+func TestSetQuotas(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.SetQuotas(WithMaxTopics(1), WithMaxSubscribers(1))
+	pub.CreateTopic("a")
+
+	var quota *QuotaError
+	err := pub.CreateTopic("b")
+	if !errors.As(err, &quota) || quota.Quota != "WithMaxTopics" || quota.Limit != 1 {
+		t.Errorf("Expected a WithMaxTopics QuotaError, got %v", err)
+	}
+	if !errors.Is(err, ErrNamespaceLimit) {
+		t.Errorf("Expected the QuotaError to match ErrNamespaceLimit, got %v", err)
+	}
+
+	pub.Subscribe("a")
+	_, err = pub.Subscribe("a")
+	if !errors.As(err, &quota) || quota.Quota != "WithMaxSubscribers" || quota.Topic != "a" {
+		t.Errorf("Expected a WithMaxSubscribers QuotaError, got %v", err)
+	}
+
+	pub.SetQuotas(WithMaxSubscribers(0)) // Lifted, the topic quota stays
+	if _, err := pub.Subscribe("a"); err != nil {
+		t.Errorf("Expected Subscribe to succeed without a subscriber quota, got %v", err)
+	}
+	if err := pub.CreateTopic("b"); !errors.Is(err, ErrNamespaceLimit) {
+		t.Errorf("Expected the topic quota to remain, got %v", err)
+	}
+}
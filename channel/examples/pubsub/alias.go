@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrAliasCycle is returned by AliasTopic when the alias would resolve back to itself.
+var ErrAliasCycle = errors.New("alias would create a cycle")
+
+// errTopicExists is returned when an alias or a new topic name is already taken by a
+// topic or an alias.
+var errTopicExists = errors.New("topic or alias already exists")
+
+// aliasTable maps alias names to the topic (or alias) they stand for. Like routeTable
+// it is never modified once installed in Publisher.aliases.
+type aliasTable map[string]string
+
+// AliasTopic makes alias another name for target: publishing and subscribing to alias
+// reach target, so publishers can move to a new name one at a time. Aliases may point
+// to other aliases; an alias that would resolve back to itself is rejected with
+// ErrAliasCycle. Authorization (see WithAuthorizer) is checked against the topic the
+// alias resolves to.
+//
+// Aliases apply to publishing, subscribing and to the calls a publisher or subscriber
+// makes on its topic (PauseTopic, ResumeTopic, SetSnapshotProvider, TopicPressure,
+// Subscribers, ...); the rest of topic administration (CloseTopic, SetValidator, Stats,
+// ...) takes the real name. An alias cannot be created
+// as a topic while it exists, see RemoveAlias.
+//
+// Returns:
+//   - error: "topic not found" if target does not resolve to a topic, an error if
+//     alias is already a topic or an alias, ErrAliasCycle, or ErrCrossNamespace
+//     (wrapped) if alias or target names a topic of one of p's namespaces
+func (p *Publisher[T]) AliasTopic(alias, target string) error {
+	p.Lock()
+	defer p.Unlock()
+	for _, topic := range []string{alias, target} {
+		if err := p.checkNamespaceLocked(topic); err != nil {
+			return err
+		}
+	}
+	resolved := p.resolve(target)                     // Stable: aliases only change under the write lock
+	unlock := p.lockShards([]string{alias, resolved}) // CreateTopic checks for aliases under the alias's shard lock
+	defer unlock()
+
+	if _, ok := p.shard(alias).topics[alias]; ok || p.isAlias(alias) {
+		return fmt.Errorf("%w: %q", errTopicExists, alias)
+	}
+	if alias == target || p.resolveVia(target, alias) {
+		return fmt.Errorf("%w: %q -> %q", ErrAliasCycle, alias, target)
+	}
+	if _, ok := p.shard(resolved).topics[resolved]; !ok {
+		return fmt.Errorf("topic not found: %q", target)
+	}
+	p.setAliasLocked(alias, target)
+	return nil
+}
+
+// RemoveAlias deletes alias, once every publisher has moved to the topic's real name.
+// Subscriptions made through the alias keep working.
+func (p *Publisher[T]) RemoveAlias(alias string) error {
+	p.Lock()
+	defer p.Unlock()
+	if !p.isAlias(alias) {
+		return fmt.Errorf("alias not found: %q", alias)
+	}
+	p.setAliasLocked(alias, "")
+	return nil
+}
+
+// RenameTopic renames the topic old to name, moving its subscribers, settings, routes
+// and per-topic Options (WithTopicCodec, WithCipher, WithDeliveryConcurrency), and
+// leaves old behind as an alias of name (see AliasTopic): existing
+// subscriptions keep receiving, and publishers still using old keep reaching them
+// until RemoveAlias(old). Messages published from now on carry name as their topic.
+//
+// Topics of a Publisher with a store cannot be renamed, as their stored log is kept
+// under the old name. Renaming into one of p's namespaces ("tenant/topic") fails with
+// ErrCrossNamespace (wrapped).
+func (p *Publisher[T]) RenameTopic(old, name string) error {
+	if p.config.store != nil {
+		return errors.New("cannot rename the topics of a Publisher with a store")
+	}
+	p.Lock()
+	defer p.Unlock()
+	if err := p.checkNamespaceLocked(name); err != nil {
+		return err
+	}
+	unlock := p.lockShards([]string{old, name})
+	defer unlock()
+
+	from, to := p.shard(old), p.shard(name)
+	state, ok := from.topics[old]
+	if !ok {
+		return fmt.Errorf("topic not found: %q", old)
+	}
+	if _, ok := to.topics[name]; ok || p.isAlias(name) {
+		return fmt.Errorf("%w: %q", errTopicExists, name)
+	}
+	to.topics[name], to.subscribers[name] = state, from.subscribers[old]
+	delete(from.topics, old)
+	delete(from.subscribers, old)
+
+	table := p.routes.Load()
+	if routes := table.from(old); routes != nil {
+		p.setRoutesLocked(name, routes)
+		p.setRoutesLocked(old, nil)
+	}
+	p.config.updateTopicOptions(func(o *topicOptions) {
+		from, to := p.qualified(old), p.qualified(name)
+		moveKey(o.deliveryConcurrency, from, to)
+		moveKey(o.ciphers, from, to)
+		moveKey(o.codecs, from, to)
+	})
+	p.setAliasLocked(old, name)
+	return nil
+}
+
+// moveKey moves the entry of m at from to to, or removes the one at to if from has
+// none, so to ends up with exactly from's setting.
+func moveKey[V any](m map[string]V, from, to string) {
+	v, ok := m[from]
+	delete(m, from)
+	delete(m, to)
+	if ok {
+		m[to] = v
+	}
+}
+
+// resolve returns the topic name resolves to through aliases: name itself unless it
+// is an alias.
+func (p *Publisher[T]) resolve(name string) string {
+	table := p.aliases.Load()
+	if table == nil {
+		return name
+	}
+	for {
+		target, ok := (*table)[name]
+		if !ok {
+			return name
+		}
+		name = target
+	}
+}
+
+// resolveVia reports whether resolving name passes through alias.
+func (p *Publisher[T]) resolveVia(name, alias string) bool {
+	table := p.aliases.Load()
+	for table != nil {
+		if name == alias {
+			return true
+		}
+		target, ok := (*table)[name]
+		if !ok {
+			return false
+		}
+		name = target
+	}
+	return false
+}
+
+// isAlias reports whether name is an alias.
+func (p *Publisher[T]) isAlias(name string) bool {
+	table := p.aliases.Load()
+	if table == nil {
+		return false
+	}
+	_, ok := (*table)[name]
+	return ok
+}
+
+// setAliasLocked installs a copy of the alias table with alias pointing to target, or
+// without alias if target is empty. Called with the write lock held.
+func (p *Publisher[T]) setAliasLocked(alias, target string) {
+	table := aliasTable{}
+	if old := p.aliases.Load(); old != nil {
+		table = maps.Clone(*old)
+	}
+	if target == "" {
+		delete(table, alias)
+	} else {
+		table[alias] = target
+	}
+	p.aliases.Store(&table)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// TestAliasTopic tests publishing and subscribing through alias chains, and that
+// cycles are rejected
+func TestAliasTopic(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	pub.CreateTopic("orders")
+	if err := pub.AliasTopic("purchases", "orders"); err != nil {
+		t.Fatalf("AliasTopic() returned error: %v", err)
+	}
+	if err := pub.AliasTopic("buys", "purchases"); err != nil {
+		t.Fatalf("AliasTopic() to an alias returned error: %v", err)
+	}
+	if err := pub.AliasTopic("orders", "buys"); err == nil {
+		t.Error("Expected aliasing an existing topic to fail")
+	}
+	if err := pub.AliasTopic("loop", "loop"); !errors.Is(err, ErrAliasCycle) {
+		t.Errorf("Expected ErrAliasCycle, got %v", err)
+	}
+	if err := pub.CreateTopic("buys"); err == nil {
+		t.Error("Expected creating a topic named like an alias to fail")
+	}
+
+	sub, err := pub.Subscribe("buys")
+	if err != nil {
+		t.Fatalf("Subscribe() through an alias returned error: %v", err)
+	}
+	pub.Publish("purchases", "o1")
+	if msg := <-sub; msg != "o1" {
+		t.Errorf("Expected o1, got %q", msg)
+	}
+
+	pub.RemoveAlias("buys")
+	if err := pub.Publish("buys", "o2"); err == nil {
+		t.Error("Expected a removed alias to be unknown")
+	}
+}
+
+// TestRenameTopic tests that subscribers and publishers using the old name keep
+// working after a rename
+func TestRenameTopic(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	pub.CreateTopic("orders")
+	pub.CreateTopic("audit")
+	plain, _ := pub.Subscribe("orders")
+	envelopes, _ := pub.SubscribeMessages("orders")
+	pub.AddRoute("orders", nil, "audit")
+	audit, _ := pub.Subscribe("audit")
+
+	if err := pub.RenameTopic("orders", "audit"); err == nil {
+		t.Error("Expected renaming onto an existing topic to fail")
+	}
+	if err := pub.RenameTopic("orders", "sales.orders"); err != nil {
+		t.Fatalf("RenameTopic() returned error: %v", err)
+	}
+	pub.Publish("orders", "o1")
+	pub.Publish("sales.orders", "o2")
+	for _, want := range []string{"o1", "o2"} {
+		if msg := <-plain; msg != want {
+			t.Errorf("Expected %s, got %q", want, msg)
+		}
+		if msg := <-envelopes.C; msg.Value != want || msg.Topic != "sales.orders" {
+			t.Errorf("Expected %s on sales.orders, got %+v", want, msg)
+		}
+		if msg := <-audit; msg != want {
+			t.Errorf("Expected the route to move with the topic, got %q", msg)
+		}
+	}
+	if _, ok := pub.Stats()["orders"]; ok {
+		t.Error("Expected the old name to be gone from Stats")
+	}
+	if err := envelopes.Close(); err != nil {
+		t.Errorf("Close() after a rename returned error: %v", err)
+	}
+	if err := pub.CloseSubscriber("orders", plain); err != nil {
+		t.Errorf("CloseSubscriber() with the old name returned error: %v", err)
+	}
+}
+
+// TestRenameTopicOptions tests that a renamed topic keeps its per-topic codec, cipher
+// and delivery concurrency, and that they no longer apply to the old name
+func TestRenameTopicOptions(t *testing.T) {
+	ring, _ := NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	pub := NewPublisher[string](WithTopicCodec("orders", GobCodec), WithCipher("orders", ring),
+		WithDeliveryConcurrency("orders", 4))
+	pub.CreateTopic("orders")
+	if err := pub.RenameTopic("orders", "purchases"); err != nil {
+		t.Fatalf("RenameTopic() returned error: %v", err)
+	}
+
+	if pub.codecFor("purchases") != GobCodec || pub.config.cipherFor("purchases") != ring {
+		t.Error("Expected the renamed topic to keep its codec and cipher")
+	}
+	if pub.config.topicOptions.Load().deliveryConcurrency["purchases"] != 4 {
+		t.Error("Expected the renamed topic to keep its delivery concurrency")
+	}
+	if pub.config.codecFor("orders") != JSONCodec || pub.config.cipherFor("orders") != nil {
+		t.Error("Expected the old name to lose the topic's options")
+	}
+	event, err := pub.gatewayEvent(Message[string]{Topic: "purchases", Value: "o1"})
+	if err != nil {
+		t.Fatalf("gatewayEvent() returned error: %v", err)
+	}
+	sealed, _ := base64.StdEncoding.DecodeString(event.Data)
+	var value string
+	if err := pub.config.decodePayload("purchases", sealed, &value); err != nil || value != "o1" {
+		t.Errorf("Expected an encrypted gob payload for o1, got %q (%v)", value, err)
+	}
+}
+
+// TestAliasTopicAdministration tests that pausing, resuming, snapshot providers and
+// pressure reach the topic an alias stands for
+func TestAliasTopicAdministration(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	pub.CreateTopic("orders")
+	pub.AliasTopic("legacy", "orders")
+	ch, _ := pub.Subscribe("orders")
+
+	if err := pub.PauseTopic("legacy", WithPauseBuffer(1)); err != nil {
+		t.Fatalf("PauseTopic() through an alias returned error: %v", err)
+	}
+	pub.Publish("orders", "held")
+	if got := drain(ch); len(got) != 0 {
+		t.Errorf("Expected the topic to be paused, got %v", got)
+	}
+	if err := pub.ResumeTopic("legacy"); err != nil {
+		t.Fatalf("ResumeTopic() through an alias returned error: %v", err)
+	}
+	if info := pub.TopicPressure("legacy"); info.Subscribers != 1 || info.Backlog != 1 {
+		t.Errorf("Expected 1 subscriber with 1 pending message, got %+v", info)
+	}
+	if got := drain(ch); len(got) != 1 || got[0] != "held" {
+		t.Errorf("Expected the held message after resuming, got %v", got)
+	}
+
+	if err := pub.SetSnapshotProvider("legacy", func() []string { return []string{"state"} }); err != nil {
+		t.Fatalf("SetSnapshotProvider() through an alias returned error: %v", err)
+	}
+	snap, _ := pub.Subscribe("orders")
+	if msg := <-snap; msg != "state" {
+		t.Errorf("Expected the snapshot, got %q", msg)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrQueueFull is returned by Publish in async mode when the delivery queue of the
+// topic's worker has no room. The message was not published.
+var ErrQueueFull = errors.New("delivery queue full")
+
+// ErrShutdown is returned by Publish and CreateTopic once Shutdown has been called.
+var ErrShutdown = errors.New("publisher shut down")
+
+// WithAsyncDelivery makes Publish return as soon as the message is queued: a pool of
+// workers goroutines fans it out to the subscribers in the background. Each
+// worker has its own queue of up to queue pending messages, and every topic is served
+// by one worker, so the messages of a topic are still delivered in publish order.
+//
+// Errors found while delivering (a closed topic, a store failure, a full OverflowError
+// subscriber) can no longer be returned to the publisher and are logged instead.
+// Call Shutdown to stop accepting messages and wait for the queues to drain (or
+// WithDropOnShutdown to discard them instead).
+func WithAsyncDelivery(workers, queue int) Option {
+	return func(c *config) {
+		c.asyncWorkers = max(workers, 1)
+		c.asyncQueue = max(queue, 0)
+	}
+}
+
+// asyncDelivery is the worker pool behind WithAsyncDelivery.
+//
+// Go Concurrency Patterns used:
+//   - Worker pool: a fixed number of goroutines drain the queues, so a burst of
+//     publishes does not start a goroutine per message
+//   - Bounded buffered channels: the queues cap the pending messages, and a
+//     non-blocking send turns a full queue into ErrQueueFull instead of a stall
+//   - Sharding by key: hashing the topic picks the worker, keeping per-topic order
+//     (per-partition order on partitioned topics, see WithPartitions)
+//   - RWMutex around close: enqueues hold the read lock, so Shutdown (write lock)
+//     never closes a queue while a send to it is in progress
+type asyncDelivery[T any] struct {
+	mu      sync.RWMutex
+	closed  bool
+	queues  []chan asyncJob[T]
+	seed    maphash.Seed
+	pending atomic.Int64   // Queued or being delivered
+	done    sync.WaitGroup // Running workers
+}
+
+// asyncJob is one queued publish.
+type asyncJob[T any] struct {
+	topic string
+	msg   Message[T]
+}
+
+// startAsync starts the worker pool configured by WithAsyncDelivery.
+func (p *Publisher[T]) startAsync() {
+	a := &asyncDelivery[T]{
+		queues: make([]chan asyncJob[T], p.config.asyncWorkers),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range a.queues {
+		queue := make(chan asyncJob[T], p.config.asyncQueue)
+		a.queues[i] = queue
+		a.done.Go(func() {
+			for job := range queue {
+				if p.config.dropOnShutdown && p.stopping.Load() {
+					p.metrics.dropped.Add(1) // Discarded by Shutdown (WithDropOnShutdown)
+					a.pending.Add(-1)
+					continue
+				}
+				if err := p.deliver(context.Background(), job.topic, job.msg); err != nil {
+					p.config.logger.Warn("pubsub: async delivery failed", "topic", job.topic, "error", err)
+				}
+				a.pending.Add(-1)
+			}
+		})
+	}
+	p.async = a
+}
+
+// enqueue queues msg for delivery to topic by the topic's worker.
+func (p *Publisher[T]) enqueue(topic string, msg Message[T]) error {
+	s := p.shard(topic)
+	s.RLock()
+	state, ok := s.topics[topic]
+	s.RUnlock()
+	if !ok {
+		return errors.New("topic not found") // Reported now, not logged by the worker
+	}
+
+	a := p.async
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return ErrShutdown
+	}
+	shard := maphash.String(a.seed, topic)
+	p.stampExpiry(state, &msg) // The TTL counts time spent in the queue
+	if partition, ok := state.partition(msg); ok {
+		shard += uint64(partition) // Partitions of one topic go to different workers
+	}
+	queue := a.queues[shard%uint64(len(a.queues))]
+	a.pending.Add(1)
+	select {
+	case queue <- asyncJob[T]{topic: topic, msg: msg}:
+		return nil
+	default:
+		a.pending.Add(-1)
+		return ErrQueueFull
+	}
+}
+
+// stopAsync closes the delivery queues and returns once the workers have emptied them.
+func (p *Publisher[T]) stopAsync() {
+	a := p.async
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		for _, queue := range a.queues {
+			close(queue) // Workers exit once they have drained it
+		}
+	}
+	a.mu.Unlock()
+	a.done.Wait()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// queued returns the number of messages waiting in the async delivery queues
+func queued(pub *Publisher[string]) int {
+	n := 0
+	for _, queue := range pub.async.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+// TestAsyncDelivery tests that Publish returns while a subscriber is not reading, that
+// the bounded queue rejects excess messages, and that Shutdown delivers the rest in order
+func TestAsyncDelivery(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(0), WithAsyncDelivery(2, 3))
+	pub.CreateTopic("events")
+	ch, _ := pub.Subscribe("events")
+
+	// The worker blocks on the first message (nobody reads), three more fill its queue
+	accepted := 0
+	var err error
+	for i := range 10 {
+		if err = pub.Publish("events", fmt.Sprint(i)); err != nil {
+			break
+		}
+		accepted++
+		for i == 0 && queued(pub) > 0 {
+			runtime.Gosched() // Let the worker pick it up
+		}
+	}
+	if !errors.Is(err, ErrQueueFull) || accepted != 4 {
+		t.Fatalf("Expected ErrQueueFull after 4 messages, got %v after %d", err, accepted)
+	}
+
+	received := make(chan []string)
+	go func() {
+		var msgs []string
+		for msg := range ch {
+			msgs = append(msgs, msg)
+			if len(msgs) == accepted {
+				break
+			}
+		}
+		received <- msgs
+	}()
+	if err := pub.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+	if msgs := <-received; fmt.Sprint(msgs) != "[0 1 2 3]" {
+		t.Errorf("Expected [0 1 2 3] in order, got %v", msgs)
+	}
+	if err := pub.Publish("events", "late"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected ErrShutdown after Shutdown, got %v", err)
+	}
+}
+
+// TestAsyncShutdownTimeout tests that Shutdown gives up when a subscriber never drains
+func TestAsyncShutdownTimeout(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(0), WithAsyncDelivery(1, 1))
+	pub.CreateTopic("events")
+	ch, _ := pub.Subscribe("events")
+	pub.Publish("events", "stuck")
+	if err := pub.Publish("missing", "x"); err == nil {
+		t.Error("Expected an unknown topic to be reported by Publish")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pub.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	<-ch // Unblock the worker
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Anonymous is the principal used by Publish and Subscribe when an Authorizer is configured.
+const Anonymous = ""
+
+// AnyTopic grants a permission on every topic when passed to ACL.Allow*.
+const AnyTopic = "*"
+
+// ErrUnauthorized is returned (wrapped) when the Authorizer rejects an operation.
+var ErrUnauthorized = errors.New("not authorized")
+
+// Authorizer decides which principal may publish to or subscribe to which topic.
+// It is consulted by the Publisher (PublishAs/SubscribeAs and their anonymous
+// counterparts) and is meant to be shared with anything exposing the broker to
+// other users, such as network bridges.
+//
+// Implementations must be safe for concurrent use: publishers and subscribers call
+// it from many goroutines.
+type Authorizer interface {
+	CanPublish(principal, topic string) bool
+	CanSubscribe(principal, topic string) bool
+}
+
+// PublishAs publishes message to topic on behalf of principal.
+//
+// Parameters:
+//   - principal: string - the identity of the caller (user name, client ID, ...)
+//   - topic: string - the topic name to publish to
+//   - message: T - the message content to broadcast
+//
+// Returns:
+//   - error: ErrUnauthorized (wrapped) if the Authorizer denies the publish,
+//     otherwise the same errors as Publish
+func (p *Publisher[T]) PublishAs(principal, topic string, message T) error {
+	if err := p.authorizePublish(principal, topic); err != nil {
+		return err
+	}
+	return p.publish(context.Background(), topic, Message[T]{Value: message})
+}
+
+// SubscribeAs subscribes to topic on behalf of principal.
+//
+// Returns:
+//   - <-chan T: receive-only channel for receiving messages
+//   - error: ErrUnauthorized (wrapped) if the Authorizer denies the subscription,
+//     otherwise the same errors as Subscribe
+func (p *Publisher[T]) SubscribeAs(principal, topic string, opts ...SubscribeOption) (<-chan T, error) {
+	if err := p.authorizeSubscribe(principal, topic); err != nil {
+		return nil, err
+	}
+	return p.subscribe(topic, false, opts)
+}
+
+// authorizePublish returns ErrUnauthorized (wrapped) if principal may not publish to
+// topic, and ErrCrossNamespace (wrapped) if topic is in one of p's namespaces.
+func (p *Publisher[T]) authorizePublish(principal, topic string) error {
+	if err := p.checkNamespace(topic); err != nil {
+		return err
+	}
+	topic = p.resolve(topic)
+	if a := p.config.authorizer; a != nil && !a.CanPublish(principal, p.qualified(topic)) {
+		return fmt.Errorf("%w: %q may not publish to %q", ErrUnauthorized, principal, p.qualified(topic))
+	}
+	return nil
+}
+
+// authorizeSubscribe returns ErrUnauthorized (wrapped) if principal may not subscribe
+// to topic, and ErrCrossNamespace (wrapped) if topic is in one of p's namespaces.
+func (p *Publisher[T]) authorizeSubscribe(principal, topic string) error {
+	if err := p.checkNamespace(topic); err != nil {
+		return err
+	}
+	topic = p.resolve(topic)
+	if a := p.config.authorizer; a != nil && !a.CanSubscribe(principal, p.qualified(topic)) {
+		return fmt.Errorf("%w: %q may not subscribe to %q", ErrUnauthorized, principal, p.qualified(topic))
+	}
+	return nil
+}
+
+// ACL is a simple allow-list Authorizer: nothing is permitted unless granted.
+//
+// Go Concurrency Patterns used:
+//   - RWMutex: permission checks happen on every publish (many readers), grants are rare (writer)
+type ACL struct {
+	mu        sync.RWMutex
+	publish   map[string]map[string]bool // principal -> topic -> allowed
+	subscribe map[string]map[string]bool
+}
+
+// NewACL returns an empty ACL that denies everything.
+func NewACL() *ACL {
+	return &ACL{
+		publish:   make(map[string]map[string]bool),
+		subscribe: make(map[string]map[string]bool),
+	}
+}
+
+// AllowPublish lets principal publish to topics (AnyTopic for all topics).
+func (a *ACL) AllowPublish(principal string, topics ...string) {
+	a.grant(a.publish, principal, topics)
+}
+
+// AllowSubscribe lets principal subscribe to topics (AnyTopic for all topics).
+func (a *ACL) AllowSubscribe(principal string, topics ...string) {
+	a.grant(a.subscribe, principal, topics)
+}
+
+// CanPublish implements Authorizer.
+func (a *ACL) CanPublish(principal, topic string) bool {
+	return a.allowed(a.publish, principal, topic)
+}
+
+// CanSubscribe implements Authorizer.
+func (a *ACL) CanSubscribe(principal, topic string) bool {
+	return a.allowed(a.subscribe, principal, topic)
+}
+
+func (a *ACL) grant(rules map[string]map[string]bool, principal string, topics []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if rules[principal] == nil {
+		rules[principal] = make(map[string]bool)
+	}
+	for _, topic := range topics {
+		rules[principal][topic] = true
+	}
+}
+
+func (a *ACL) allowed(rules map[string]map[string]bool, principal, topic string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return rules[principal][topic] || rules[principal][AnyTopic]
+}
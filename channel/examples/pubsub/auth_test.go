@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAuthorizerACL tests that the ACL gates publishing and subscribing per principal
+func TestAuthorizerACL(t *testing.T) {
+	acl := NewACL()
+	acl.AllowPublish("alice", "news")
+	acl.AllowSubscribe("bob", AnyTopic)
+
+	pub := NewPublisher[string](WithAuthorizer(acl))
+	pub.CreateTopic("news")
+
+	ch, err := pub.SubscribeAs("bob", "news")
+	if err != nil {
+		t.Fatalf("SubscribeAs(bob) returned error: %v", err)
+	}
+	if _, err := pub.SubscribeAs("alice", "news"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized for alice subscribing, got %v", err)
+	}
+
+	if err := pub.PublishAs("bob", "news", "forged"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized for bob publishing, got %v", err)
+	}
+	if err := pub.PublishAs("alice", "news", "hello"); err != nil {
+		t.Fatalf("PublishAs(alice) returned error: %v", err)
+	}
+	if msg := <-ch; msg != "hello" {
+		t.Errorf("Expected 'hello', got '%s'", msg)
+	}
+}
+
+// TestAuthorizerAnonymous tests that Publish/Subscribe act as the Anonymous principal
+func TestAuthorizerAnonymous(t *testing.T) {
+	acl := NewACL()
+	pub := NewPublisher[string](WithAuthorizer(acl))
+	pub.CreateTopic("news")
+
+	if _, err := pub.Subscribe("news"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected anonymous Subscribe to be rejected, got %v", err)
+	}
+	if err := pub.Publish("news", "message"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected anonymous Publish to be rejected, got %v", err)
+	}
+
+	acl.AllowPublish(Anonymous, "news")
+	if err := pub.Publish("news", "message"); err != nil {
+		t.Errorf("Expected anonymous Publish to be allowed after grant, got %v", err)
+	}
+}
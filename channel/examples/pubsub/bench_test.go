@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// BenchmarkSubscribeChurn measures allocations of subscribe/unsubscribe cycles
+func BenchmarkSubscribeChurn(b *testing.B) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("churn")
+	b.ReportAllocs()
+	for b.Loop() {
+		ch, _ := pub.Subscribe("churn")
+		pub.CloseSubscriber("churn", ch)
+	}
+}
+
+// BenchmarkPublish measures a single-subscriber publish
+func BenchmarkPublish(b *testing.B) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("bench")
+	ch, _ := pub.Subscribe("bench")
+	b.ReportAllocs()
+	for b.Loop() {
+		pub.Publish("bench", "message")
+		<-ch
+	}
+}
+
+// BenchmarkManyTopics measures parallel publishes over thousands of topics while
+// subscribers come and go, with all topics behind one lock and spread over shards
+func BenchmarkManyTopics(b *testing.B) {
+	const topics = 4096
+	for _, shards := range []int{1, defaultShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			pub := NewPublisher[string](WithTopicShards(shards))
+			names := make([]string, topics)
+			for i := range names {
+				names[i] = fmt.Sprintf("topic-%d", i)
+				pub.CreateTopic(names[i])
+				pub.Subscribe(names[i], WithOverflow(OverflowDropNewest))
+			}
+			var next atomic.Uint64
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := next.Add(1); pb.Next(); i++ {
+					topic := names[(i*7919)%topics]
+					if i%16 == 0 { // Subscription churn takes the write lock
+						ch, _ := pub.Subscribe(topic, WithOverflow(OverflowDropNewest))
+						pub.CloseSubscriber(topic, ch)
+						continue
+					}
+					pub.Publish(topic, "message")
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+// WithCatchUp puts a log subscription (SubscribeLog, SubscribeGroup) in catch-up mode.
+// When the subscriber falls behind and its buffer is full, the publisher stops
+// delivering to it instead of blocking; the subscription then reads the missed messages
+// from the topic log in batches of batch records and rejoins live delivery once it has
+// caught up. Publishers are never slowed down by a lagging consumer, buffers stay
+// bounded, and no message is lost or duplicated.
+//
+// The initial replay of a catch-up subscription is read the same way, in batches,
+// instead of being loaded into memory at once.
+func WithCatchUp(batch int) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.catchUpBatch = max(batch, 1)
+	}
+}
+
+// deliverLive hands msg to a catch-up subscriber without blocking. If the subscriber's
+// buffer is full it is marked lagging and stops receiving live messages until its pump
+// has caught up from the store. Called with the read lock held.
+func (p *Publisher[T]) deliverLive(sub *subscriber[T], msg Message[T]) bool {
+	if sub.lagging.Load() {
+		return false // The pump will read msg from the store
+	}
+	select {
+	case sub.msgs <- msg:
+		return true
+	default:
+		sub.lagging.Store(true)
+		p.metrics.lagged.Add(1)
+		return false
+	}
+}
+
+// catchUpPump delivers a catch-up subscription: live messages while the subscriber
+// keeps up, batched store reads while it is lagging. next is the offset of the first
+// message the subscriber has not received.
+//
+// Go Concurrency Patterns used:
+//   - Atomic flag: publishers (read lock) mark the subscriber lagging, the pump clears it
+//   - Write lock as a barrier: the last catch-up batch is read and the flag cleared while
+//     no publish is in flight, so the next live message follows the batch exactly
+//   - Offset deduplication: live messages older than next are dropped
+func (p *Publisher[T]) catchUpPump(topic string, sub *subscriber[T], next uint64, batch int, out chan<- Message[T]) {
+	defer close(out)
+	send := func(msg Message[T]) bool {
+		if msg.Offset < next {
+			return true // Already delivered from the store
+		}
+		if !sub.credits.acquire(sub.done) {
+			return false
+		}
+		select {
+		case out <- msg:
+			next = msg.Offset + 1
+			return true
+		case <-sub.done:
+			return false
+		}
+	}
+
+	for {
+		if sub.lagging.Load() {
+			// Deliver what was buffered before the subscriber started lagging
+			for drained := false; !drained; {
+				select {
+				case msg, ok := <-sub.msgs:
+					if !ok || !send(msg) {
+						return
+					}
+				default:
+					drained = true
+				}
+			}
+			if !p.catchUp(topic, sub, batch, send, &next) {
+				return
+			}
+		}
+
+		msg, ok := <-sub.msgs
+		if !ok || !send(msg) {
+			return
+		}
+	}
+}
+
+// catchUp reads the store from *next on and delivers through send until the subscriber
+// is level with the log, then clears its lagging flag. It reports false if the
+// subscription ended meanwhile.
+func (p *Publisher[T]) catchUp(topic string, sub *subscriber[T], batch int, send func(Message[T]) bool, next *uint64) bool {
+	for {
+		messages, err := p.readBatch(topic, *next, batch)
+		if err != nil {
+			p.config.logger.Warn("pubsub: catch-up read failed", "topic", topic, "error", err)
+			return false
+		}
+		for _, msg := range messages {
+			if !send(msg) {
+				return false
+			}
+		}
+		if len(messages) < batch {
+			break // Close to the head of the log: finish under the lock
+		}
+	}
+
+	// No publish to topic can run while its shard's write lock is held, so whatever the
+	// store holds now is everything the subscriber missed. Messages published after
+	// Unlock are delivered live.
+	s := p.shard(topic)
+	s.Lock()
+	select {
+	case <-sub.done:
+		s.Unlock()
+		return false
+	default:
+	}
+	rest, err := p.replayLocked(topic, *next, func(Record) bool { return true })
+	if err == nil {
+		sub.lagging.Store(false)
+	}
+	s.Unlock()
+	if err != nil {
+		p.config.logger.Warn("pubsub: catch-up read failed", "topic", topic, "error", err)
+		return false
+	}
+	for _, msg := range rest {
+		if !send(msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// readBatch reads and decodes up to limit stored messages of topic from offset on.
+func (p *Publisher[T]) readBatch(topic string, offset uint64, limit int) ([]Message[T], error) {
+	records, err := p.config.store.ReadFrom(p.qualified(topic), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message[T], len(records))
+	for i, rec := range records {
+		if messages[i], err = p.toMessage(topic, rec); err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
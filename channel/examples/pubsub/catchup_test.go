@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestCatchUpDoesNotBlockPublisher tests that a lagging subscriber is served from the store
+func TestCatchUpDoesNotBlockPublisher(t *testing.T) {
+	pub := NewPublisher[string](WithStore(NewMemoryStore()), WithDefaultBuffer(1))
+	topic := "test-topic"
+	pub.CreateTopic(topic)
+	sub, err := pub.SubscribeLog(topic, 0, WithCatchUp(4))
+	if err != nil {
+		t.Fatalf("SubscribeLog() returned error: %v", err)
+	}
+	defer sub.Close()
+
+	// Nobody reads yet: with a buffer of 1 these publishes would block without catch-up
+	for i := range 20 {
+		if err := pub.Publish(topic, fmt.Sprint(i)); err != nil {
+			t.Fatalf("Publish() returned error: %v", err)
+		}
+	}
+	for i := range 20 {
+		if msg := <-sub.C; msg.Offset != uint64(i) || msg.Value != fmt.Sprint(i) {
+			t.Fatalf("Expected message %d, got offset %d value %s", i, msg.Offset, msg.Value)
+		}
+	}
+
+	pub.Publish(topic, "live")
+	if msg := <-sub.C; msg.Offset != 20 || msg.Value != "live" {
+		t.Errorf("Expected live message at offset 20, got %d (%s)", msg.Offset, msg.Value)
+	}
+}
+
+// TestCatchUpConcurrent tests that a slow consumer sees every message exactly once, in
+// order, while a publisher keeps running
+func TestCatchUpConcurrent(t *testing.T) {
+	const n = 2000
+	pub := NewPublisher[string](WithStore(NewMemoryStore()), WithDefaultBuffer(2))
+	topic := "test-topic"
+	pub.CreateTopic(topic)
+	sub, err := pub.SubscribeLog(topic, 0, WithCatchUp(16))
+	if err != nil {
+		t.Fatalf("SubscribeLog() returned error: %v", err)
+	}
+	defer sub.Close()
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := range n {
+			pub.Publish(topic, fmt.Sprint(i))
+		}
+	})
+
+	for i := range n {
+		msg := <-sub.C
+		if msg.Offset != uint64(i) {
+			t.Fatalf("Expected offset %d, got %d", i, msg.Offset)
+		}
+	}
+	wg.Wait()
+	select {
+	case msg := <-sub.C:
+		t.Errorf("Expected no duplicate messages, got offset %d", msg.Offset)
+	default:
+	}
+}
+
+// TestCatchUpRequiresLog tests that plain subscriptions reject WithCatchUp
+func TestCatchUpRequiresLog(t *testing.T) {
+	pub := NewPublisher[string](WithStore(NewMemoryStore()))
+	pub.CreateTopic("news")
+	if _, err := pub.Subscribe("news", WithCatchUp(8)); err == nil {
+		t.Error("Expected Subscribe with WithCatchUp to fail")
+	}
+}
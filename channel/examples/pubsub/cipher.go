@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownKey is returned when decrypting a payload sealed with a key the KeyRing
+// does not hold (never added, or retired).
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Cipher encrypts the payloads of a topic (see WithCipher). Implementations must be
+// safe for concurrent use, and Decrypt must accept everything Encrypt produced with a
+// key that has not been retired, so payloads stay readable across key rotations.
+type Cipher interface {
+	// Encrypt seals plaintext.
+	Encrypt(plaintext []byte) ([]byte, error)
+	// Decrypt opens a payload sealed by Encrypt.
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// WithCipher encrypts the payloads of topic with c wherever they are encoded to leave
+// the process: in the store (WithStore), on the gRPC bridge (RegisterGRPC) and through
+// the HTTP gateway (HTTPHandler). Records at rest, anything that copies the store, and
+// payloads on the wire only ever hold ciphertext, so only holders of the key can read
+// them: subscribers of this Publisher that passed authorization get them decrypted
+// (SubscribeFrom, SubscribeLog, SubscribeWithReplay after a restart, ...), a
+// GRPCClient given the same option decrypts what it receives and encrypts what it
+// publishes, and gateway clients must seal and open payloads themselves (see
+// HTTPHandler). Live subscribers in the process receive the values as published.
+// Keys, headers, message times and tombstones are not encrypted.
+//
+// topic is qualified with its namespace path, as for WithDeliveryConcurrency. Use a
+// KeyRing to rotate keys without losing the records written with the old ones.
+//
+// Usage example:
+//
+//	ring, _ := NewKeyRing("2024-06", key)
+//	pub := NewPublisher[string](WithStore(store), WithCipher("payments", ring))
+//	...
+//	ring.Rotate("2024-07", newKey) // New records use the new key, old ones stay readable
+func WithCipher(topic string, c Cipher) Option {
+	return func(cfg *config) {
+		cfg.updateTopicOptions(func(o *topicOptions) { o.ciphers[topic] = c })
+	}
+}
+
+// cipherFor returns the cipher of the topic with the qualified name topic, or nil.
+func (c *config) cipherFor(topic string) Cipher {
+	return c.topicOptions.Load().ciphers[topic]
+}
+
+// KeyRing is a Cipher using AES-GCM with named keys: it encrypts with the current key
+// and decrypts with whichever key a payload was sealed with, which makes key rotation
+// a matter of calling Rotate (and, once no record needs it, Retire on the old key).
+//
+// A sealed payload is the key name's length (one byte), the key name, the GCM nonce
+// and the ciphertext with its authentication tag.
+//
+// Go Concurrency Patterns used:
+//   - RWMutex: encryption and decryption share the read lock, so rotation only blocks
+//     them while the key map changes
+type KeyRing struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyRing returns a KeyRing encrypting with key, named id. key must be 16, 24 or 32
+// bytes long (AES-128, AES-192 or AES-256).
+func NewKeyRing(id string, key []byte) (*KeyRing, error) {
+	k := &KeyRing{keys: make(map[string]cipher.AEAD)}
+	if err := k.Rotate(id, key); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Rotate adds key, named id, and makes it the key new payloads are encrypted with.
+// Payloads encrypted with earlier keys can still be decrypted.
+func (k *KeyRing) Rotate(id string, key []byte) error {
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("key id must be 1 to 255 bytes, got %d", len(id))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[id] = aead
+	k.current = id
+	return nil
+}
+
+// Retire forgets the key named id, so payloads encrypted with it can no longer be
+// decrypted. The current key cannot be retired.
+func (k *KeyRing) Retire(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if id == k.current {
+		return fmt.Errorf("key %q is the current key", id)
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+// Encrypt seals plaintext with the current key.
+func (k *KeyRing) Encrypt(plaintext []byte) ([]byte, error) {
+	k.mu.RLock()
+	id, aead := k.current, k.keys[k.current]
+	k.mu.RUnlock()
+
+	out := make([]byte, 0, 1+len(id)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out = append(out, byte(len(id)))
+	out = append(out, id...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte(id)), nil
+}
+
+// Decrypt opens a payload sealed by Encrypt with any key still in the ring.
+func (k *KeyRing) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 1 || len(ciphertext) < 1+int(ciphertext[0]) {
+		return nil, errors.New("ciphertext too short")
+	}
+	id := string(ciphertext[1 : 1+ciphertext[0]])
+	k.mu.RLock()
+	aead, ok := k.keys[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	sealed := ciphertext[1+len(id):]
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, []byte(id))
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// TestWithCipher tests that stored payloads are encrypted, that subscribers replaying
+// the store get them decrypted, and that records stay readable across a key rotation
+func TestWithCipher(t *testing.T) {
+	ring, err := NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("NewKeyRing() returned error: %v", err)
+	}
+	store := NewMemoryStore()
+	pub := NewPublisher[string](WithStore(store), WithCipher("secrets", ring), WithDefaultBuffer(4))
+	pub.CreateTopic("secrets")
+	pub.CreateTopic("public")
+	pub.Publish("secrets", "launch code")
+	if err := ring.Rotate("k2", bytes.Repeat([]byte{2}, 16)); err != nil {
+		t.Fatalf("Rotate() returned error: %v", err)
+	}
+	pub.Publish("secrets", "new launch code")
+	pub.Publish("public", "hello")
+
+	records, _ := store.ReadFrom("secrets", 0, 0)
+	for _, rec := range records {
+		if bytes.Contains(rec.Payload, []byte("launch code")) {
+			t.Errorf("Expected an encrypted payload, got %q", rec.Payload)
+		}
+	}
+	if records, _ := store.ReadFrom("public", 0, 0); string(records[0].Payload) != `"hello"` {
+		t.Errorf("Expected other topics in the clear, got %q", records[0].Payload)
+	}
+
+	ch, err := pub.SubscribeFrom("secrets", 0)
+	if err != nil {
+		t.Fatalf("SubscribeFrom() returned error: %v", err)
+	}
+	for _, want := range []string{"launch code", "new launch code"} {
+		if got := <-ch; got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+
+	if err := ring.Retire("k2"); err == nil {
+		t.Error("Expected an error retiring the current key")
+	}
+	ring.Retire("k1")
+	if _, err := pub.SubscribeFrom("secrets", 0); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey after retiring k1, got %v", err)
+	}
+}
+
+// sniffConn records the bytes that pass through a connection in either direction.
+type sniffConn struct {
+	net.Conn
+	mu   *sync.Mutex
+	seen *bytes.Buffer
+}
+
+func (c sniffConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.mu.Lock()
+	c.seen.Write(b[:n])
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c sniffConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.mu.Lock()
+	c.seen.Write(b[:n])
+	c.mu.Unlock()
+	return n, err
+}
+
+// TestCipherGRPCBridge tests that a topic's payloads cross the gRPC bridge encrypted in
+// both directions, and that only a client holding the key can publish or read them
+func TestCipherGRPCBridge(t *testing.T) {
+	ring, _ := NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	pub := NewPublisher[string](WithDefaultBuffer(8), WithCipher("secrets", ring))
+	pub.CreateTopic("secrets")
+	lis := bufconn.Listen(1 << 16)
+	server := grpc.NewServer()
+	pub.RegisterGRPC(server)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var mu sync.Mutex
+	var seen bytes.Buffer
+	conn, err := grpc.NewClient("passthrough:///pubsub",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			conn, err := lis.DialContext(ctx)
+			return sniffConn{conn, &mu, &seen}, err
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() returned error: %v", err)
+	}
+	defer conn.Close()
+	client := NewGRPCClient[string](conn, WithDefaultBuffer(8), WithCipher("secrets", ring))
+	defer client.Close()
+
+	remote, _ := client.Subscribe("secrets")
+	local, _ := pub.Subscribe("secrets")
+	waitFor(t, func() bool { return pub.Stats()["secrets"].Subscribers == 2 })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Publish(ctx, "secrets", "launch code"); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	if msg := <-local; msg != "launch code" {
+		t.Errorf("Expected the server to decrypt the publish, got %q", msg)
+	}
+	if msg := <-remote; msg.Value != "launch code" {
+		t.Errorf("Expected the client to decrypt the message, got %q", msg.Value)
+	}
+	// The bridge's JSON messages carry payloads base64-encoded
+	clear := base64.StdEncoding.EncodeToString([]byte(`"launch code"`))[:12]
+	mu.Lock()
+	if bytes.Contains(seen.Bytes(), []byte(clear)) {
+		t.Error("Expected no plaintext payload on the wire")
+	}
+	mu.Unlock()
+
+	// A client without the key cannot publish to the topic
+	plain, _ := newGRPCBridge(t, pub)
+	if err := plain.Publish(ctx, "secrets", "forged"); err == nil {
+		t.Error("Expected a publish without the key to be refused")
+	}
+}
+
+// TestCipherGateway tests that a topic's payloads go through the HTTP gateway sealed:
+// POST bodies must be encrypted with the topic's Cipher, and streamed events carry the
+// sealed payload base64-encoded
+func TestCipherGateway(t *testing.T) {
+	ring, _ := NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	pub := NewPublisher[string](WithDefaultBuffer(8), WithCipher("secrets", ring))
+	pub.CreateTopic("secrets")
+	server := httptest.NewServer(pub.HTTPHandler())
+	defer server.Close()
+
+	ws, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+"/topics/secrets/stream", "", server.URL)
+	if err != nil {
+		t.Fatalf("websocket.Dial() returned error: %v", err)
+	}
+	defer ws.Close()
+	waitFor(t, func() bool { return pub.Stats()["secrets"].Subscribers == 1 })
+
+	post := func(body []byte) int {
+		resp, err := http.Post(server.URL+"/topics/secrets", "application/octet-stream", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST returned error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post([]byte(`"forged"`)); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a body that is not sealed, got %d", code)
+	}
+	sealed, _ := ring.Encrypt([]byte(`"launch code"`))
+	if code := post(sealed); code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for a sealed body, got %d", code)
+	}
+
+	var frame gatewayEvent
+	if err := websocket.JSON.Receive(ws, &frame); err != nil {
+		t.Fatalf("Receive() returned error: %v", err)
+	}
+	if strings.Contains(frame.Data, "launch code") {
+		t.Errorf("Expected a sealed payload, got %q", frame.Data)
+	}
+	data, err := base64.StdEncoding.DecodeString(frame.Data)
+	if err != nil {
+		t.Fatalf("Expected base64 data, got %q (%v)", frame.Data, err)
+	}
+	if payload, err := ring.Decrypt(data); err != nil || string(payload) != `"launch code"` {
+		t.Errorf("Expected the sealed payload to open to \"launch code\", got %q (%v)", payload, err)
+	}
+}
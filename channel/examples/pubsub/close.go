@@ -22,23 +22,36 @@ import "errors"
 //   - error: returns error if topic doesn't exist
 //
 // Usage: Call this when you want to stop a topic and notify all subscribers to stop listening.
-func (p *Publisher) CloseTopic(topic string) error {
-	p.Lock()         // Acquire exclusive write lock (modifying map)
-	defer p.Unlock() // Ensure lock is released
+func (p *Publisher[T]) CloseTopic(topic string) error {
+	s := p.shard(topic)
+	s.Lock()         // Acquire exclusive write lock (modifying map)
+	defer s.Unlock() // Ensure lock is released
 
-	if _, ok := p.subscribers[topic]; !ok {
+	if _, ok := s.subscribers[topic]; !ok {
 		return errors.New("topic not found")
 	}
+	p.closeTopicLocked(s, topic)
+	return nil
+}
 
+// closeTopicLocked closes the subscribers of an existing topic of shard s and removes
+// it. Called with the shard's write lock held.
+func (p *Publisher[T]) closeTopicLocked(s *topicShard[T], topic string) {
 	// Close all subscriber channels for this topic
 	// This causes all "for msg := range ch" loops in subscribers to exit
-	for _, ch := range p.subscribers[topic] {
-		close(ch) // Signal no more messages will be sent
+	for _, sub := range s.subscribers[topic] {
+		p.removeSubscriberLocked(topic, sub) // Signal no more messages will be sent
 	}
 
-	// Remove topic from map
-	delete(p.subscribers, topic)
-	return nil
+	// Remove topic from map (its stored log, if any, is kept for replay)
+	delete(s.subscribers, topic)
+	close(s.topics[topic].done) // Stop the compactor, if any
+	delete(s.topics, topic)
+	p.releaseTopic()
+	p.topicClosedLocked(topic)
+	if p.debugEnabled() {
+		p.config.logger.Debug("pubsub: topic closed", "topic", topic)
+	}
 }
 
 // CloseSubscriber removes a specific subscriber from a topic by closing their channel
@@ -51,30 +64,35 @@ func (p *Publisher) CloseTopic(topic string) error {
 //
 // Parameters:
 //   - topic: string - the topic name
-//   - subscriberChannel: <-chan string - the subscriber's channel to remove
+//   - subscriberChannel: <-chan T - the subscriber's channel to remove
 //
 // Returns:
 //   - error: returns error if topic or subscriber not found
 //
 // Note: This method closes the channel, which will cause the subscriber's range loop to exit.
-func (p *Publisher) CloseSubscriber(topic string, subscriberChannel <-chan string) error {
-	p.Lock()         // Acquire exclusive write lock
-	defer p.Unlock() // Ensure lock is released
+func (p *Publisher[T]) CloseSubscriber(topic string, subscriberChannel <-chan T) error {
+	topic = p.resolve(topic) // The topic may have been renamed since Subscribe
+	s := p.shard(topic)
+	s.Lock()         // Acquire exclusive write lock
+	defer s.Unlock() // Ensure lock is released
 
-	if _, ok := p.subscribers[topic]; !ok {
+	if _, ok := s.subscribers[topic]; !ok {
 		return errors.New("topic not found")
 	}
 
 	// Find and remove the subscriber's channel from the list
-	for i, subscriber := range p.subscribers[topic] {
+	for i, subscriber := range s.subscribers[topic] {
 		// Compare channels (receive-only channel can be compared with bidirectional channel)
-		if subscriber == subscriberChannel {
+		if subscriber.out == subscriberChannel {
 			// Close the bidirectional channel stored in map (not the receive-only parameter)
 			// This signals the subscriber that no more messages will be sent
-			close(subscriber)
+			p.removeSubscriberLocked(topic, subscriber)
 
 			// Remove channel from slice using slice slicing
-			p.subscribers[topic] = append(p.subscribers[topic][:i], p.subscribers[topic][i+1:]...)
+			s.subscribers[topic] = append(s.subscribers[topic][:i], s.subscribers[topic][i+1:]...)
+			if p.debugEnabled() {
+				p.config.logger.Debug("pubsub: unsubscribed", "topic", topic, "subscribers", len(s.subscribers[topic]))
+			}
 			return nil
 		}
 	}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Codec turns messages into bytes and back whenever they leave the process:
+// persistent topic storage, network bridges, or anything else that needs a wire format.
+// Using one interface everywhere means a message type is serialized the same way no
+// matter which transport or backend carries it.
+//
+// Implementations must be safe for concurrent use.
+type Codec interface {
+	// Name identifies the format (e.g. "json"), useful for content negotiation and headers.
+	Name() string
+	// Encode serializes v.
+	Encode(v any) ([]byte, error)
+	// Decode deserializes data into the value pointed to by v.
+	Decode(data []byte, v any) error
+}
+
+// Built-in codecs.
+var (
+	JSONCodec     Codec = jsonCodec{}
+	GobCodec      Codec = gobCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                    { return "json" }
+func (jsonCodec) Encode(v any) ([]byte, error)    { return json.Marshal(v) }
+func (jsonCodec) Decode(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Decode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// protobufCodec encodes proto.Message values. Plain strings (a Publisher[string]'s
+// payload) are carried as google.protobuf.StringValue so string topics can use it too.
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string { return "protobuf" }
+
+func (protobufCodec) Encode(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case proto.Message:
+		return proto.Marshal(m)
+	case string:
+		return proto.Marshal(wrapperspb.String(m))
+	case *string:
+		return proto.Marshal(wrapperspb.String(*m))
+	}
+	return nil, fmt.Errorf("protobuf codec: cannot encode %T", v)
+}
+
+func (protobufCodec) Decode(data []byte, v any) error {
+	switch m := v.(type) {
+	case proto.Message:
+		return proto.Unmarshal(data, m)
+	case *string:
+		var wrapped wrapperspb.StringValue
+		if err := proto.Unmarshal(data, &wrapped); err != nil {
+			return err
+		}
+		*m = wrapped.GetValue()
+		return nil
+	}
+
+	// v points at a message pointer (e.g. **pb.Event): allocate the message
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Pointer {
+		if msg, ok := reflect.New(rv.Elem().Type().Elem()).Interface().(proto.Message); ok {
+			if err := proto.Unmarshal(data, msg); err != nil {
+				return err
+			}
+			rv.Elem().Set(reflect.ValueOf(msg))
+			return nil
+		}
+	}
+	return fmt.Errorf("protobuf codec: cannot decode into %T", v)
+}
+
+// WithTopicCodec makes codec the one topic's payloads are encoded with, in place of the
+// Publisher's (WithCodec): the store, the gRPC bridge and the HTTP gateway all use it,
+// so a topic carrying protobuf events can sit next to JSON topics. topic is qualified
+// with its namespace path, as for WithCipher. A GRPCClient must be given the same
+// option for the topic.
+//
+// Usage example:
+//
+//	pub := NewPublisher[string](
+//		WithStore(store),
+//		WithTopicCodec("telemetry", ProtobufCodec),
+//	)
+func WithTopicCodec(topic string, codec Codec) Option {
+	return func(c *config) {
+		c.updateTopicOptions(func(o *topicOptions) { o.codecs[topic] = codec })
+	}
+}
+
+// codecFor returns the codec of the topic with the qualified name topic.
+func (c *config) codecFor(topic string) Codec {
+	if codec, ok := c.topicOptions.Load().codecs[topic]; ok {
+		return codec
+	}
+	return c.codec
+}
+
+// codecFor returns the codec of topic, a name as given to p (an alias, say).
+func (p *Publisher[T]) codecFor(topic string) Codec {
+	return p.config.codecFor(p.qualified(p.resolve(topic)))
+}
+
+// encodePayload encodes value with the codec of the topic with the qualified name
+// topic, and encrypts the result if the topic has a cipher (WithCipher).
+func (c *config) encodePayload(topic string, value any) ([]byte, error) {
+	payload, err := c.codecFor(topic).Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	if cipher := c.cipherFor(topic); cipher != nil {
+		if payload, err = cipher.Encrypt(payload); err != nil {
+			return nil, fmt.Errorf("encrypt %s: %w", topic, err)
+		}
+	}
+	return payload, nil
+}
+
+// decodePayload reverses encodePayload into v.
+func (c *config) decodePayload(topic string, payload []byte, v any) error {
+	if cipher := c.cipherFor(topic); cipher != nil {
+		var err error
+		if payload, err = cipher.Decrypt(payload); err != nil {
+			return fmt.Errorf("decrypt %s: %w", topic, err)
+		}
+	}
+	return c.codecFor(topic).Decode(payload, v)
+}
+
+// decodePayload decodes a payload sent to topic, a name as given to p (an alias, say).
+func (p *Publisher[T]) decodePayload(topic string, payload []byte, v any) error {
+	return p.config.decodePayload(p.qualified(p.resolve(topic)), payload, v)
+}
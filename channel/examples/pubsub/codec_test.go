@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestCodecRoundTrip tests that every built-in codec round-trips a string message
+func TestCodecRoundTrip(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec, GobCodec, ProtobufCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			data, err := codec.Encode("Breaking news: Major announcement!")
+			if err != nil {
+				t.Fatalf("Encode() returned error: %v", err)
+			}
+			var got string
+			if err := codec.Decode(data, &got); err != nil {
+				t.Fatalf("Decode() returned error: %v", err)
+			}
+			if got != "Breaking news: Major announcement!" {
+				t.Errorf("Expected original message, got '%s'", got)
+			}
+		})
+	}
+}
+
+// TestProtobufCodecMessages tests protobuf encoding of proto.Message values
+func TestProtobufCodecMessages(t *testing.T) {
+	data, err := ProtobufCodec.Encode(wrapperspb.Int64(42))
+	if err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+
+	var direct wrapperspb.Int64Value
+	if err := ProtobufCodec.Decode(data, &direct); err != nil || direct.GetValue() != 42 {
+		t.Fatalf("Decode(*Int64Value) = %v, %v", direct.GetValue(), err)
+	}
+
+	var ptr *wrapperspb.Int64Value
+	if err := ProtobufCodec.Decode(data, &ptr); err != nil || ptr.GetValue() != 42 {
+		t.Fatalf("Decode(**Int64Value) = %v, %v", ptr.GetValue(), err)
+	}
+
+	if _, err := ProtobufCodec.Encode(struct{}{}); err == nil {
+		t.Error("Expected error encoding a non-protobuf value")
+	}
+}
+
+// TestWithTopicCodec tests that a topic's codec overrides the Publisher's in the store,
+// for aliases of the topic too
+func TestWithTopicCodec(t *testing.T) {
+	store := NewMemoryStore()
+	pub := NewPublisher[string](WithStore(store), WithTopicCodec("telemetry", ProtobufCodec), WithDefaultBuffer(2))
+	pub.CreateTopic("telemetry")
+	pub.CreateTopic("events")
+	pub.AliasTopic("metrics", "telemetry")
+	pub.Publish("metrics", "cpu=42")
+	pub.Publish("events", "started")
+
+	records, _ := store.ReadFrom("telemetry", 0, 0)
+	var decoded string
+	if err := ProtobufCodec.Decode(records[0].Payload, &decoded); err != nil || decoded != "cpu=42" {
+		t.Errorf("Expected a protobuf record, got %q (%v)", records[0].Payload, err)
+	}
+	if records, _ := store.ReadFrom("events", 0, 0); string(records[0].Payload) != `"started"` {
+		t.Errorf("Expected a JSON record for other topics, got %q", records[0].Payload)
+	}
+
+	ch, _ := pub.SubscribeFrom("telemetry", 0)
+	if got := <-ch; got != "cpu=42" {
+		t.Errorf("Expected cpu=42 back from the store, got %q", got)
+	}
+	if pub.codecFor("metrics") != ProtobufCodec {
+		t.Error("Expected the alias to use the topic's codec")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"goconcurrency/testkit"
+)
+
+// latestState replays the n messages of a topic's log and folds them into key -> value
+func latestState(t *testing.T, pub *Publisher[string], topic string, n int) map[string]string {
+	t.Helper()
+	sub, err := pub.SubscribeLog(topic, 0)
+	if err != nil {
+		t.Fatalf("SubscribeLog() returned error: %v", err)
+	}
+	defer sub.Close()
+
+	state := make(map[string]string)
+	for range n {
+		msg := <-sub.C
+		if msg.Deleted {
+			delete(state, msg.Key)
+		} else {
+			state[msg.Key] = msg.Value
+		}
+	}
+	return state
+}
+
+// publishPrices writes a history with overwrites and a delete
+func publishPrices(t *testing.T, pub *Publisher[string], topic string) {
+	t.Helper()
+	steps := []func() error{
+		func() error { return pub.PublishKeyed(topic, "apple", "1.00") },
+		func() error { return pub.PublishKeyed(topic, "pear", "2.00") },
+		func() error { return pub.PublishKeyed(topic, "apple", "1.10") },
+		func() error { return pub.DeleteKey(topic, "pear") },
+		func() error { return pub.PublishKeyed(topic, "plum", "3.00") },
+		func() error { return pub.PublishKeyed(topic, "apple", "1.20") },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("publish returned error: %v", err)
+		}
+	}
+}
+
+// TestCompact tests that compaction keeps the latest record per key and tombstones,
+// and that a late subscriber rebuilds the same state as before compaction
+func TestCompact(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			pub := NewPublisher[string](WithStore(store), WithDefaultBuffer(10))
+			topic := "prices"
+			pub.CreateTopic(topic)
+			publishPrices(t, pub, topic)
+
+			before := latestState(t, pub, topic, 6)
+			if err := pub.Compact(topic); err != nil {
+				t.Fatalf("Compact() returned error: %v", err)
+			}
+
+			records, _ := store.ReadFrom(topic, 0, 0)
+			var offsets []uint64
+			for _, rec := range records {
+				offsets = append(offsets, rec.Offset)
+			}
+			// pear tombstone (3), plum (4), latest apple (5)
+			if len(records) != 3 || offsets[0] != 3 || !records[0].Tombstone || offsets[2] != 5 {
+				t.Errorf("Expected offsets [3 4 5] with a tombstone first, got %v", offsets)
+			}
+
+			after := latestState(t, pub, topic, 3)
+			want := map[string]string{"apple": "1.20", "plum": "3.00"}
+			if !maps.Equal(before, want) || !maps.Equal(after, want) {
+				t.Errorf("Expected state %v before and after compaction, got %v and %v", want, before, after)
+			}
+		})
+	}
+}
+
+// TestCompactionBackground tests the per-topic compactor goroutine driven by the clock
+func TestCompactionBackground(t *testing.T) {
+	testkit.Run(t, func(t *testing.T, s *testkit.Scheduler) {
+		store := NewMemoryStore()
+		pub := NewPublisher[string](WithStore(store), WithClock(s.Clock))
+		topic := "prices"
+		pub.CreateTopic(topic, WithCompaction(time.Minute))
+		publishPrices(t, pub, topic)
+
+		s.Advance(time.Minute - time.Nanosecond)
+		if records, _ := store.ReadFrom(topic, 0, 0); len(records) != 6 {
+			t.Errorf("Expected no compaction before the interval, got %d records", len(records))
+		}
+		s.Advance(time.Nanosecond)
+		if records, _ := store.ReadFrom(topic, 0, 0); len(records) != 3 {
+			t.Errorf("Expected 3 records after the interval, got %d", len(records))
+		}
+
+		// CloseTopic stops the compactor; Run fails if it is still waiting on its timer
+		pub.CloseTopic(topic)
+	})
+}
+
+// TestCompactUnsupported tests stores without Compactor
+func TestCompactUnsupported(t *testing.T) {
+	pub := NewPublisher[string](WithStore(struct{ TopicStore }{NewMemoryStore()}))
+	pub.CreateTopic("prices")
+	if err := pub.Compact("prices"); !errors.Is(err, ErrCompactionUnsupported) {
+		t.Errorf("Expected ErrCompactionUnsupported, got %v", err)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
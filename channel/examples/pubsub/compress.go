@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/snappy"
+)
+
+// Compression is the algorithm a CompressedCodec compresses large payloads with.
+type Compression int
+
+const (
+	// Gzip compresses well at a moderate CPU cost (compress/gzip, default level).
+	Gzip Compression = iota
+	// Snappy compresses less but much faster (snappy framing format).
+	Snappy
+)
+
+// DefaultMaxDecodedSize is the largest payload a CompressedCodec decompresses unless
+// SetMaxDecodedSize says otherwise.
+const DefaultMaxDecodedSize = 64 << 20
+
+// ErrDecodedTooLarge is returned (wrapped) by CompressedCodec.Decode when a payload
+// decompresses to more than the codec's limit (see SetMaxDecodedSize).
+var ErrDecodedTooLarge = errors.New("decompressed payload too large")
+
+// Magic prefixes of the compressed formats, which tell Decode how a payload was written.
+var (
+	gzipMagic   = []byte{0x1f, 0x8b}
+	snappyMagic = []byte("\xff\x06\x00\x00sNaPpY")
+)
+
+// CompressedCodec is a Codec stage that compresses the output of another codec when
+// it is at least a threshold in size, and decompresses it again on decode (see
+// NewCompressedCodec).
+type CompressedCodec struct {
+	inner       Codec
+	compression Compression
+	threshold   int
+	writers     sync.Pool    // compressors of the codec's algorithm, reset for each payload
+	maxDecoded  atomic.Int64 // Largest decompressed payload Decode accepts, 0 for no limit
+
+	compressed   atomic.Int64
+	uncompressed atomic.Int64
+	saved        atomic.Int64
+}
+
+// CompressionStats reports what a CompressedCodec did so far.
+type CompressionStats struct {
+	Compressed   int64 // Payloads encoded compressed
+	Uncompressed int64 // Payloads below the threshold, or that compression did not shrink
+	BytesSaved   int64 // Bytes the compressed payloads are smaller than inner's encoding
+}
+
+// NewCompressedCodec wraps inner so that payloads of threshold bytes or more are
+// compressed on encode, for instance when topics carry large event bodies that are
+// stored (WithStore) or sent over the gRPC bridge: pass it to WithCodec. Smaller
+// payloads, and those compression does not shrink, are left as inner encodes them.
+//
+// Decode recognizes gzip and snappy payloads by their magic prefix, whichever
+// algorithm the codec compresses with, and hands anything else to inner unchanged, so
+// a store written before compression was turned on stays readable. A payload that
+// decompresses to more than DefaultMaxDecodedSize bytes is rejected with
+// ErrDecodedTooLarge, so a small payload from a gRPC peer cannot inflate into an
+// unbounded allocation; SetMaxDecodedSize changes the limit. inner's encoding
+// must therefore not start with those prefixes (JSON, gob and protobuf do not).
+// Compressed payloads are binary: the HTTP gateway, which writes payloads as text,
+// needs a codec without this stage.
+//
+// Go Concurrency Patterns used:
+//   - sync.Pool: compressors are reused across payloads and goroutines instead of
+//     allocating their large internal state for every publish
+//   - Atomic counters: Stats can be read while publishes encode concurrently
+//
+// Usage example:
+//
+//	codec := NewCompressedCodec(JSONCodec, Gzip, 1024)
+//	pub := NewPublisher[Event](WithStore(store), WithCodec(codec))
+//	...
+//	log.Printf("saved %d bytes", codec.Stats().BytesSaved)
+func NewCompressedCodec(inner Codec, compression Compression, threshold int) *CompressedCodec {
+	c := &CompressedCodec{inner: inner, compression: compression, threshold: threshold}
+	c.maxDecoded.Store(DefaultMaxDecodedSize)
+	return c
+}
+
+// SetMaxDecodedSize sets the largest size, in bytes, a payload may decompress to in
+// Decode; n <= 0 removes the limit. It may be called while the codec is in use.
+func (c *CompressedCodec) SetMaxDecodedSize(n int64) {
+	c.maxDecoded.Store(max(n, 0))
+}
+
+// Name returns inner's name with the compression appended, e.g. "json+gzip".
+func (c *CompressedCodec) Name() string {
+	if c.compression == Snappy {
+		return c.inner.Name() + "+snappy"
+	}
+	return c.inner.Name() + "+gzip"
+}
+
+// Encode encodes v with inner and compresses the result if it is large enough.
+func (c *CompressedCodec) Encode(v any) ([]byte, error) {
+	data, err := c.inner.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	packed, _, err := c.pack(data)
+	return packed, err
+}
+
+// pack compresses data if it is at least the threshold in size and compression
+// shrinks it, and reports whether it did.
+func (c *CompressedCodec) pack(data []byte) (packed []byte, compressed bool, err error) {
+	if len(data) < c.threshold {
+		c.uncompressed.Add(1)
+		return data, false, nil
+	}
+	packed, err = c.compress(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(packed) >= len(data) {
+		c.uncompressed.Add(1)
+		return data, false, nil
+	}
+	c.compressed.Add(1)
+	c.saved.Add(int64(len(data) - len(packed)))
+	return packed, true, nil
+}
+
+// compressor is what gzip.Writer and snappy.Writer have in common.
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compress writes data through a pooled compressor.
+func (c *CompressedCodec) compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w compressor
+	if pooled := c.writers.Get(); pooled != nil {
+		w = pooled.(compressor)
+		w.Reset(&buf)
+	} else if c.compression == Snappy {
+		w = snappy.NewBufferedWriter(&buf)
+	} else {
+		w = gzip.NewWriter(&buf)
+	}
+	defer c.writers.Put(w)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decompresses data if it is compressed, then decodes it with inner.
+func (c *CompressedCodec) Decode(data []byte, v any) error {
+	plain, err := c.unpack(data)
+	if err != nil {
+		return err
+	}
+	return c.inner.Decode(plain, v)
+}
+
+// unpack decompresses data if it starts with the gzip or snappy magic prefix, and
+// returns it unchanged otherwise.
+func (c *CompressedCodec) unpack(data []byte) ([]byte, error) {
+	var r io.Reader
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s codec: %w", c.Name(), err)
+		}
+		r = zr
+	case bytes.HasPrefix(data, snappyMagic):
+		r = snappy.NewReader(bytes.NewReader(data))
+	default:
+		return data, nil
+	}
+	limit := c.maxDecoded.Load()
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1) // One byte more tells a payload of exactly limit from a larger one
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s codec: %w", c.Name(), err)
+	}
+	if limit > 0 && int64(len(plain)) > limit {
+		return nil, fmt.Errorf("%s codec: %w: more than %d bytes", c.Name(), ErrDecodedTooLarge, limit)
+	}
+	return plain, nil
+}
+
+// Stats returns the codec's counters.
+func (c *CompressedCodec) Stats() CompressionStats {
+	return CompressionStats{
+		Compressed:   c.compressed.Load(),
+		Uncompressed: c.uncompressed.Load(),
+		BytesSaved:   c.saved.Load(),
+	}
+}
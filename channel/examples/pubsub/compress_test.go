@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCompressedCodec tests that large payloads are compressed with either algorithm
+// and round-trip, that small ones are left alone, and that the stats add up
+func TestCompressedCodec(t *testing.T) {
+	large := strings.Repeat("Breaking news: Major announcement! ", 100)
+	for _, compression := range []Compression{Gzip, Snappy} {
+		codec := NewCompressedCodec(JSONCodec, compression, 256)
+		t.Run(codec.Name(), func(t *testing.T) {
+			plain, _ := JSONCodec.Encode(large)
+			data, err := codec.Encode(large)
+			if err != nil {
+				t.Fatalf("Encode() returned error: %v", err)
+			}
+			if len(data) >= len(plain) {
+				t.Errorf("Expected fewer than %d bytes, got %d", len(plain), len(data))
+			}
+			var got string
+			if err := codec.Decode(data, &got); err != nil || got != large {
+				t.Fatalf("Decode() = %.20q..., %v", got, err)
+			}
+
+			small, _ := codec.Encode("short")
+			if want, _ := JSONCodec.Encode("short"); !bytes.Equal(small, want) {
+				t.Errorf("Expected a small payload as JSON, got %q", small)
+			}
+			if err := codec.Decode(plain, &got); err != nil || got != large {
+				t.Errorf("Expected uncompressed payloads to decode, got %v", err)
+			}
+
+			stats := codec.Stats()
+			want := CompressionStats{Compressed: 1, Uncompressed: 1, BytesSaved: int64(len(plain) - len(data))}
+			if stats != want {
+				t.Errorf("Expected stats %+v, got %+v", want, stats)
+			}
+		})
+	}
+}
+
+// TestCompressedCodecStore tests that a Publisher replays compressed records from its
+// store
+func TestCompressedCodecStore(t *testing.T) {
+	codec := NewCompressedCodec(JSONCodec, Snappy, 64)
+	pub := NewPublisher[string](WithStore(NewMemoryStore()), WithCodec(codec), WithDefaultBuffer(2))
+	pub.CreateTopic("events")
+	large := strings.Repeat("x", 1000)
+	pub.Publish("events", large)
+	pub.Publish("events", "small")
+
+	ch, err := pub.SubscribeFrom("events", 0)
+	if err != nil {
+		t.Fatalf("SubscribeFrom() returned error: %v", err)
+	}
+	if got := <-ch; got != large {
+		t.Errorf("Expected the large message back, got %d bytes", len(got))
+	}
+	if got := <-ch; got != "small" {
+		t.Errorf("Expected small, got %q", got)
+	}
+	if stats := codec.Stats(); stats.Compressed != 1 || stats.BytesSaved <= 0 {
+		t.Errorf("Expected one compressed record, got %+v", stats)
+	}
+}
+
+// TestCompressedCodecDecodeLimit tests that a payload decompressing beyond the limit
+// is rejected before it is fully inflated, and that the limit can be raised or lifted
+func TestCompressedCodecDecodeLimit(t *testing.T) {
+	bomb := strings.Repeat("0", 1<<20) // Compresses to about a kilobyte
+	for _, compression := range []Compression{Gzip, Snappy} {
+		codec := NewCompressedCodec(JSONCodec, compression, 256)
+		t.Run(codec.Name(), func(t *testing.T) {
+			data, _ := codec.Encode(bomb)
+			codec.SetMaxDecodedSize(64 << 10)
+			var got string
+			if err := codec.Decode(data, &got); !errors.Is(err, ErrDecodedTooLarge) {
+				t.Errorf("Expected ErrDecodedTooLarge, got %v", err)
+			}
+
+			plain, _ := JSONCodec.Encode(bomb)
+			codec.SetMaxDecodedSize(int64(len(plain)))
+			if err := codec.Decode(data, &got); err != nil || got != bomb {
+				t.Errorf("Expected a payload of exactly the limit to decode, got %v", err)
+			}
+			codec.SetMaxDecodedSize(0)
+			if err := codec.Decode(data, &got); err != nil {
+				t.Errorf("Expected no limit to decode, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// WithDeliveryConcurrency lets the delivery workers of WithAsyncDelivery hand each
+// message of topic to up to n of its subscribers at once, instead of one after the
+// other. A slow subscriber then no longer holds up the others' copy of the message, at
+// the cost of up to n goroutines per message in flight; n <= 1 (the default) delivers
+// sequentially. Messages are still delivered in publish order to each subscriber, as
+// the next message of the topic waits for every delivery of the previous one.
+//
+// topic is the name the topic is created with, qualified with its namespace path
+// ("acme/orders", see Namespace), and the setting applies from CreateTopic on. Without
+// WithAsyncDelivery, and for PublishSync, deliveries stay sequential.
+//
+// Usage example:
+//
+//	pub := NewPublisher[Quote](
+//		WithAsyncDelivery(4, 256),
+//		WithDeliveryConcurrency("quotes", 8),
+//	)
+func WithDeliveryConcurrency(topic string, n int) Option {
+	return func(c *config) {
+		c.updateTopicOptions(func(o *topicOptions) { o.deliveryConcurrency[topic] = n })
+	}
+}
+
+// parallelSends delivers one message to several subscribers at once
+// (WithDeliveryConcurrency).
+//
+// Go Concurrency Patterns used:
+//   - Semaphore: a buffered channel with a slot per allowed delivery bounds the
+//     goroutines in flight
+//   - Fan-out/fan-in: each delivery writes its result to its own slot, read after the
+//     WaitGroup is done, so results need no lock
+type parallelSends[T any] struct {
+	p       *Publisher[T]
+	ctx     context.Context
+	topic   string
+	msg     Message[T]
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	results []sentTo[T] // Indexed like the topic's subscribers
+}
+
+// sentTo is the outcome of a send started by parallelSends.
+type sentTo[T any] struct {
+	sub *subscriber[T] // nil when the subscriber was not sent to
+	r   offered[Message[T]]
+	err error
+}
+
+// newParallelSends prepares the delivery of msg to up to subscribers subscribers of
+// topic, n at a time.
+func (p *Publisher[T]) newParallelSends(ctx context.Context, topic string, msg Message[T], n, subscribers int) *parallelSends[T] {
+	return &parallelSends[T]{
+		p:       p,
+		ctx:     ctx,
+		topic:   topic,
+		msg:     msg,
+		slots:   make(chan struct{}, n),
+		results: make([]sentTo[T], subscribers),
+	}
+}
+
+// start sends the message to sub, the i-th subscriber, once fewer than n sends are in
+// flight.
+func (ps *parallelSends[T]) start(i int, sub *subscriber[T]) {
+	ps.slots <- struct{}{}
+	ps.wg.Go(func() {
+		defer func() { <-ps.slots }()
+		r, err := ps.p.sendVia(ps.ctx, ps.topic, sub, ps.msg)
+		ps.results[i] = sentTo[T]{sub: sub, r: r, err: err}
+	})
+}
+
+// wait returns the outcomes once every send has returned.
+func (ps *parallelSends[T]) wait() []sentTo[T] {
+	ps.wg.Wait()
+	return ps.results
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWithDeliveryConcurrency tests that a delivery worker reaches the other
+// subscribers of a topic while one of them blocks, and still delivers in order
+func TestWithDeliveryConcurrency(t *testing.T) {
+	pub := NewPublisher[int](WithAsyncDelivery(1, 8), WithDefaultBuffer(0), WithDeliveryConcurrency("quotes", 2))
+	defer pub.Shutdown(t.Context())
+	pub.CreateTopic("quotes")
+	stuck, _ := pub.Subscribe("quotes") // Not read until the others got both messages
+	fast, _ := pub.Subscribe("quotes")
+	other, _ := pub.Subscribe("quotes")
+
+	pub.Publish("quotes", 1)
+	for _, ch := range []<-chan int{fast, other} {
+		select {
+		case got := <-ch:
+			if got != 1 {
+				t.Errorf("Expected 1, got %d", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected delivery while another subscriber blocks")
+		}
+	}
+
+	pub.Publish("quotes", 2) // Waits for every delivery of 1
+	select {
+	case got := <-fast:
+		t.Fatalf("Expected 2 to wait for the blocked delivery of 1, got %d", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	for want := 1; want <= 2; want++ {
+		if got := <-stuck; got != want {
+			t.Errorf("Expected %d on the blocked subscriber, got %d", want, got)
+		}
+	}
+	if got := <-fast; got != 2 {
+		t.Errorf("Expected 2, got %d", got)
+	}
+	if got := <-other; got != 2 {
+		t.Errorf("Expected 2, got %d", got)
+	}
+}
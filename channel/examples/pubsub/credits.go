@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNoCredits is returned by Request on subscriptions created without WithCredits.
+var ErrNoCredits = errors.New("subscription does not use credit-based flow control")
+
+// WithCredits enables credit-based flow control on a log subscription (SubscribeLog,
+// SubscribeGroup): the subscription starts with initial credits, every message handed
+// to the consumer spends one, and delivery pauses at zero until the consumer grants more
+// with Subscription.Request. The consumer, not a fixed buffer size, decides how many
+// messages may be in flight.
+//
+// While delivery is paused, messages wait in the subscription's buffer and the
+// publisher blocks once it is full; combine with WithCatchUp to keep publishers running
+// and let the paused subscriber read from the store when it resumes.
+func WithCredits(initial int) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.credits = true
+		c.initialCredits = max(initial, 0)
+	}
+}
+
+// Request grants the broker n more message credits. See WithCredits.
+//
+// Usage example (process in batches of 10):
+//
+//	sub, _ := pub.SubscribeLog("jobs", 0, WithCredits(10))
+//	for {
+//		for range 10 {
+//			process(<-sub.C)
+//		}
+//		sub.Request(10)
+//	}
+func (s *Subscription[T]) Request(n int) error {
+	if s.sub.credits == nil {
+		return ErrNoCredits
+	}
+	s.sub.credits.grant(n)
+	return nil
+}
+
+// creditGate counts the credits of one subscription. It has a single waiter, the
+// subscription's pump, so a stale signal token only causes one extra check.
+//
+// Go Concurrency Patterns used:
+//   - Mutex-protected counter with a signal channel: acquire can wait for credits and for
+//     the subscription's done channel in one select, which sync.Cond cannot do
+type creditGate struct {
+	mu     sync.Mutex
+	n      int
+	signal chan struct{} // Holds a token while credits may be available
+}
+
+func newCreditGate(initial int) *creditGate {
+	g := &creditGate{n: initial, signal: make(chan struct{}, 1)}
+	if initial > 0 {
+		g.signal <- struct{}{}
+	}
+	return g
+}
+
+// grant adds n credits and wakes a waiting acquire.
+func (g *creditGate) grant(n int) {
+	if n <= 0 {
+		return
+	}
+	g.mu.Lock()
+	g.n += n
+	g.mu.Unlock()
+	select {
+	case g.signal <- struct{}{}:
+	default: // A wake-up is already pending
+	}
+}
+
+// acquire spends one credit, waiting until one is granted. It reports false if done
+// is closed first. A nil gate always succeeds (no flow control).
+func (g *creditGate) acquire(done <-chan struct{}) bool {
+	if g == nil {
+		return true
+	}
+	for {
+		g.mu.Lock()
+		if g.n > 0 {
+			g.n--
+			g.mu.Unlock()
+			return true
+		}
+		g.mu.Unlock()
+		select {
+		case <-g.signal:
+		case <-done:
+			return false
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// receiveWithin returns the next message on ch, or false if none arrives in time
+func receiveWithin(ch <-chan Message[string], d time.Duration) (Message[string], bool) {
+	select {
+	case msg := <-ch:
+		return msg, true
+	case <-time.After(d):
+		return Message[string]{}, false
+	}
+}
+
+// TestCreditsLimitDelivery tests that the broker delivers exactly the granted credits
+func TestCreditsLimitDelivery(t *testing.T) {
+	pub := NewPublisher[string](WithStore(NewMemoryStore()), WithDefaultBuffer(1))
+	topic := "jobs"
+	pub.CreateTopic(topic)
+	for i := range 10 {
+		pub.Publish(topic, fmt.Sprint(i))
+	}
+
+	for _, opts := range [][]SubscribeOption{
+		{WithCredits(2)},
+		{WithCredits(2), WithCatchUp(3)},
+	} {
+		sub, err := pub.SubscribeLog(topic, 0, opts...)
+		if err != nil {
+			t.Fatalf("SubscribeLog() returned error: %v", err)
+		}
+		for i := range 2 {
+			if msg, ok := receiveWithin(sub.C, time.Second); !ok || msg.Offset != uint64(i) {
+				t.Fatalf("Expected offset %d, got %d (ok=%v)", i, msg.Offset, ok)
+			}
+		}
+		if msg, ok := receiveWithin(sub.C, 20*time.Millisecond); ok {
+			t.Fatalf("Expected no delivery without credits, got offset %d", msg.Offset)
+		}
+
+		sub.Request(3)
+		for i := 2; i < 5; i++ {
+			if msg, ok := receiveWithin(sub.C, time.Second); !ok || msg.Offset != uint64(i) {
+				t.Fatalf("Expected offset %d after Request, got %d (ok=%v)", i, msg.Offset, ok)
+			}
+		}
+		if _, ok := receiveWithin(sub.C, 20*time.Millisecond); ok {
+			t.Fatal("Expected delivery to pause after the granted credits")
+		}
+		sub.Close()
+	}
+}
+
+// TestCreditsErrors tests Request without flow control and plain subscriptions with WithCredits
+func TestCreditsErrors(t *testing.T) {
+	pub := NewPublisher[string](WithStore(NewMemoryStore()))
+	pub.CreateTopic("jobs")
+	sub, _ := pub.SubscribeLog("jobs", 0)
+	defer sub.Close()
+	if err := sub.Request(1); !errors.Is(err, ErrNoCredits) {
+		t.Errorf("Expected ErrNoCredits, got %v", err)
+	}
+	if _, err := pub.Subscribe("jobs", WithCredits(1)); err == nil {
+		t.Error("Expected Subscribe with WithCredits to fail")
+	}
+}
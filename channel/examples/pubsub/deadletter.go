@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// deadLetterTopic is the only topic of a Publisher's dead-letter Publisher.
+const deadLetterTopic = "dead-letters"
+
+// ErrNoDeadLetters is returned by SubscribeDeadLetters on a Publisher created without
+// WithDeadLetters.
+var ErrNoDeadLetters = errors.New("publisher has no dead-letter topic")
+
+// DeadLetter is a message that did not reach one of its subscribers, with what
+// operators need to audit or reprocess it. Message.Value holds the original payload
+// (a T for a Publisher[T]); the dead-letter topic is shared by every payload type, as
+// a Publisher[DeadLetter[T]] per Publisher[T] would be an endless chain of types.
+type DeadLetter struct {
+	Topic      string       // Topic the message was published to
+	Message    Message[any] // The message; Offset is set with a store only
+	Subscriber uint64       // Id of the subscriber that missed it, unique per Publisher; 0 if rejected by SetValidator
+	Reason     error        // ErrDropped, ErrSubscriberFull, ErrExpired, ... or the PublishContext ctx.Err()
+	Time       time.Time    // When delivery failed
+}
+
+// WithDeadLetters routes messages that could not be delivered to a subscriber to the
+// Publisher's dead-letter topic, which operators read with SubscribeDeadLetters.
+// A message ends up there when an overflow policy discards it (see WithOverflow),
+// when PublishContext gives up before reaching the subscriber, or when the topic's
+// validator rejects it (see SetValidator).
+func WithDeadLetters() Option {
+	return func(c *config) {
+		c.deadLetters = true
+	}
+}
+
+// SubscribeDeadLetters subscribes to the dead-letter topic (see WithDeadLetters).
+// Dead letters are published while the failed publish is still running, so by default
+// they are dropped rather than stalling publishers when this subscriber falls behind;
+// pass WithOverflow to choose otherwise.
+func (p *Publisher[T]) SubscribeDeadLetters(opts ...SubscribeOption) (<-chan DeadLetter, error) {
+	if p.deadLetters == nil {
+		return nil, ErrNoDeadLetters
+	}
+	opts = append([]SubscribeOption{WithOverflow(OverflowDropNewest)}, opts...)
+	return p.deadLetters.Subscribe(deadLetterTopic, opts...)
+}
+
+// startDeadLetters creates the dead-letter Publisher. It shares the buffer size,
+// clock and logger, but nothing that would make dead letters fail themselves.
+func (p *Publisher[T]) startDeadLetters() {
+	cfg := p.config
+	cfg.store, cfg.authorizer, cfg.metricsName = nil, nil, ""
+	cfg.asyncWorkers, cfg.deadLetters = 0, false
+	p.deadLetters = newPublisher[DeadLetter](cfg)
+	p.deadLetters.CreateTopic(deadLetterTopic)
+}
+
+// deadLetter records that msg published to topic did not reach sub because of reason.
+// A nil sub means the message reached no subscriber at all.
+func (p *Publisher[T]) deadLetter(topic string, sub *subscriber[T], msg Message[T], reason error) {
+	if p.deadLetters == nil {
+		return
+	}
+	var id uint64
+	if sub != nil {
+		id = sub.id
+	}
+	p.metrics.deadLettered.Add(1)
+	p.deadLetters.Publish(deadLetterTopic, DeadLetter{
+		Topic: p.qualified(topic),
+		Message: Message[any]{
+			Offset:  msg.Offset,
+			ID:      msg.ID,
+			Topic:   msg.Topic,
+			Time:    msg.Time,
+			Key:     msg.Key,
+			Headers: msg.Headers,
+			Value:   msg.Value,
+			Deleted: msg.Deleted,
+			Expires: msg.Expires,
+		},
+		Subscriber: id,
+		Reason:     reason,
+		Time:       p.config.clock.Now(),
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestDeadLetters tests that dropped, rejected and canceled deliveries reach the
+// dead-letter topic with their reason
+func TestDeadLetters(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(1), WithDeadLetters())
+	dead, err := pub.SubscribeDeadLetters(WithOverflow(OverflowBlock))
+	if err != nil {
+		t.Fatalf("SubscribeDeadLetters() returned error: %v", err)
+	}
+	topic := "orders"
+	pub.CreateTopic(topic)
+	pub.Subscribe(topic, WithOverflow(OverflowDropNewest))
+	pub.Subscribe(topic, WithOverflow(OverflowError))
+	pub.Subscribe(topic) // Blocks once full
+	pub.Publish(topic, "o1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	go pub.PublishContext(ctx, topic, "o2")
+	for _, want := range []error{ErrDropped, ErrSubscriberFull, context.DeadlineExceeded} {
+		letter := <-dead
+		if !errors.Is(letter.Reason, want) || letter.Topic != topic || letter.Message.Value != "o2" {
+			t.Errorf("Expected o2 on %s with %v, got %+v", topic, want, letter)
+		}
+	}
+	if n := pub.metrics.deadLettered.Load(); n != 3 {
+		t.Errorf("Expected 3 dead letters counted, got %d", n)
+	}
+}
+
+// TestDeadLettersOff tests SubscribeDeadLetters without WithDeadLetters
+func TestDeadLettersOff(t *testing.T) {
+	if _, err := NewPublisher[string]().SubscribeDeadLetters(); !errors.Is(err, ErrNoDeadLetters) {
+		t.Errorf("Expected ErrNoDeadLetters, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// PublishWithID is Publish with a caller-chosen message ID, typically an idempotency
+// key: a producer that retries a publish after an error reuses the ID, and subscribers
+// created WithDedup receive the message only once. Publish assigns unique IDs itself.
+func (p *Publisher[T]) PublishWithID(topic, id string, message T) error {
+	if err := p.authorizePublish(Anonymous, topic); err != nil {
+		return err
+	}
+	return p.publish(context.Background(), topic, Message[T]{ID: id, Value: message})
+}
+
+// WithDedup filters duplicate messages for this subscriber: a message whose ID was
+// delivered within window is skipped. On an acked subscription (SubscribeAcked) a
+// message counts as delivered once acknowledged, so redeliveries and republished copies
+// of a processed message are dropped while unprocessed ones are still retried, which
+// gives idempotency-sensitive handlers effectively-once processing.
+//
+// Message IDs are not stored: messages replayed from the store (SubscribeFrom,
+// SubscribeLog) have no ID and are never filtered.
+func WithDedup(window time.Duration) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.dedupWindow = window
+	}
+}
+
+// newMessageID returns the next ID Publish assigns: the Publisher's random prefix
+// and a sequence number, unique across Publishers and processes.
+func (p *Publisher[T]) newMessageID() string {
+	return p.idPrefix + strconv.FormatUint(p.idSeq.Add(1), 36)
+}
+
+// newIDPrefix returns a random prefix for the message IDs of one Publisher.
+func newIDPrefix() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:]) + "-"
+}
+
+// dedupWindow remembers the message IDs a subscriber received during the last window.
+// A nil *dedupWindow admits everything.
+//
+// Go Concurrency Patterns used:
+//   - Mutex: concurrent publishers check and record IDs of the same subscriber, and the
+//     check and the record must be one step so two copies cannot both get through
+type dedupWindow struct {
+	mu     sync.Mutex
+	clock  clock.Clock
+	window time.Duration
+	onAck  bool                 // IDs are recorded by Ack (SubscribeAcked), not by delivery
+	seen   map[string]time.Time // ID -> when it was recorded
+	order  []dedupEntry         // Recorded IDs, oldest first, for expiry
+}
+
+type dedupEntry struct {
+	id string
+	at time.Time
+}
+
+func newDedupWindow(c clock.Clock, window time.Duration) *dedupWindow {
+	return &dedupWindow{clock: c, window: window, seen: make(map[string]time.Time)}
+}
+
+// admit reports whether the message id may be delivered. Unless IDs are recorded on
+// Ack, an admitted id is recorded right away; release undoes that if delivery fails.
+func (w *dedupWindow) admit(id string) bool {
+	if w == nil || id == "" {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.clock.Now()
+	w.expireLocked(now)
+	if _, ok := w.seen[id]; ok {
+		return false
+	}
+	if !w.onAck {
+		w.recordLocked(id, now)
+	}
+	return true
+}
+
+// release forgets id after a failed delivery, so a later copy is admitted.
+func (w *dedupWindow) release(id string) {
+	if w == nil || id == "" || w.onAck {
+		return
+	}
+	w.mu.Lock()
+	delete(w.seen, id)
+	w.mu.Unlock()
+}
+
+// ack records id as processed (acked subscriptions only).
+func (w *dedupWindow) ack(id string) {
+	if w == nil || id == "" {
+		return
+	}
+	w.mu.Lock()
+	w.recordLocked(id, w.clock.Now())
+	w.mu.Unlock()
+}
+
+func (w *dedupWindow) recordLocked(id string, now time.Time) {
+	w.seen[id] = now
+	w.order = append(w.order, dedupEntry{id: id, at: now})
+}
+
+// expireLocked forgets the IDs recorded more than window ago.
+func (w *dedupWindow) expireLocked(now time.Time) {
+	cutoff := now.Add(-w.window)
+	i := 0
+	for ; i < len(w.order) && !w.order[i].at.After(cutoff); i++ {
+		if at, ok := w.seen[w.order[i].id]; ok && at.Equal(w.order[i].at) {
+			delete(w.seen, w.order[i].id) // Not recorded again since
+		}
+	}
+	w.order = w.order[i:]
+}
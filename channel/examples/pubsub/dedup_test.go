@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// TestDedup tests that a subscriber with a dedup window receives a republished
+// message once, until the window has passed
+func TestDedup(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(1, 0))
+	pub := NewPublisher[string](WithClock(fake), WithDefaultBuffer(8))
+	pub.CreateTopic("payments")
+	deduped, _ := pub.Subscribe("payments", WithDedup(time.Minute))
+	plain, _ := pub.Subscribe("payments")
+
+	pub.PublishWithID("payments", "p1", "charge")
+	pub.PublishWithID("payments", "p1", "charge") // Producer retry
+	pub.PublishWithID("payments", "p2", "refund")
+	fake.Advance(time.Minute)
+	pub.PublishWithID("payments", "p1", "charge")
+
+	if len(deduped) != 3 || len(plain) != 4 {
+		t.Errorf("Expected 3 messages after dedup and 4 without, got %d and %d", len(deduped), len(plain))
+	}
+	if n := pub.metrics.deduplicated.Load(); n != 1 {
+		t.Errorf("Expected 1 duplicate filtered, got %d", n)
+	}
+}
+
+// TestDedupAcked tests that on an acked subscription only acknowledged IDs are
+// filtered, and that Publish assigns distinct IDs
+func TestDedupAcked(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(2))
+	pub.CreateTopic("payments")
+	sub, _ := pub.SubscribeAcked("payments", WithDedup(time.Minute))
+	defer sub.Close()
+
+	pub.PublishWithID("payments", "p1", "charge")
+	d := <-sub.C
+	d.Nack() // Not processed: the redelivery is not a duplicate
+	if again := <-sub.C; again.ID != "p1" || again.Attempt != 2 {
+		t.Fatalf("Expected p1 redelivered, got %s on attempt %d", again.ID, again.Attempt)
+	} else {
+		again.Ack()
+	}
+
+	pub.PublishWithID("payments", "p1", "charge") // Processed already: filtered
+	pub.Publish("payments", "a")
+	pub.Publish("payments", "b")
+	a, b := <-sub.C, <-sub.C
+	if a.Value != "a" || b.Value != "b" {
+		t.Errorf("Expected a then b after the filtered duplicate, got %s then %s", a.Value, b.Value)
+	}
+	if a.ID == "" || a.ID == b.ID {
+		t.Errorf("Expected distinct message IDs, got %q and %q", a.ID, b.ID)
+	}
+}
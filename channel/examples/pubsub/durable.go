@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrDurableInUse is returned by SubscribeDurable when the named subscription is
+// already open.
+var ErrDurableInUse = errors.New("durable subscription already open")
+
+// DurableSubscription is a named subscription whose progress survives reconnects and
+// restarts (see SubscribeDurable).
+type DurableSubscription[T any] struct {
+	C <-chan Message[T] // Messages after the last acknowledged one, closed by Close or CloseTopic
+
+	pub     *Publisher[T]
+	sub     *Subscription[T]
+	topic   string
+	group   string // Key of the acknowledged offset in the OffsetStore
+	key     string // Key in Publisher.durables
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	pending []uint64        // Offsets handed out on C and not committed yet, in order
+	acked   map[uint64]bool // Offsets of pending acknowledged out of order
+}
+
+// SubscribeDurable opens the durable subscription name on topic: it resumes right
+// after the last message acknowledged with Ack, replaying from the store everything
+// published while it was closed, so a consumer that disconnects, or a process that
+// restarts on the same FileStore (WithSyncWrites makes it a write-ahead log that
+// survives crashes), loses nothing. The first time, it starts at the beginning of the
+// log. Only one subscription of a name may be open at a time per topic.
+//
+// Acknowledgements may come in any order; the persisted offset only moves past a
+// message once every message before it was acknowledged, so delivery is at least once:
+// whatever was not acknowledged before Close is delivered again on resume. Messages of
+// the log that are compacted or truncated away meanwhile are skipped.
+//
+// Go Concurrency Patterns used:
+//   - Forwarding goroutine: records the offsets handed to the consumer, in order,
+//     before passing each message on
+//   - Low-water mark: out-of-order acks are held in a set until the oldest pending
+//     offset is acked, which moves the committed offset forward
+//
+// Usage example:
+//
+//	sub, err := pub.SubscribeDurable("orders", "billing")
+//	if err != nil { ... }
+//	defer sub.Close()
+//	for msg := range sub.C {
+//		bill(msg.Value)
+//		sub.Ack(msg.Offset)
+//	}
+func (p *Publisher[T]) SubscribeDurable(topic, name string, opts ...SubscribeOption) (*DurableSubscription[T], error) {
+	offsets, _ := p.config.store.(OffsetStore)
+	if p.config.store != nil && offsets == nil {
+		return nil, ErrNoOffsetStore
+	}
+	topic = p.resolve(topic)
+	d := &DurableSubscription[T]{
+		pub:   p,
+		topic: topic,
+		group: "durable:" + name,
+		key:   p.qualified(topic) + "\x00" + name,
+		done:  make(chan struct{}),
+		acked: make(map[uint64]bool),
+	}
+	p.Lock()
+	if p.durables[d.key] {
+		p.Unlock()
+		return nil, fmt.Errorf("%w: %q on %q", ErrDurableInUse, name, topic)
+	}
+	if p.durables == nil {
+		p.durables = make(map[string]bool)
+	}
+	p.durables[d.key] = true
+	p.Unlock()
+
+	sub, err := p.subscribeLog(topic, "", func(OffsetStore) (uint64, error) {
+		committed, ok, err := offsets.CommittedOffset(d.group, p.qualified(topic))
+		if err != nil || !ok {
+			return 0, err
+		}
+		return committed + 1, nil
+	}, opts)
+	if err != nil {
+		d.release()
+		return nil, err
+	}
+	d.sub = sub
+	out := make(chan Message[T])
+	d.C = out
+	go d.forward(out)
+	return d, nil
+}
+
+// forward hands the messages of the underlying subscription to out, recording their
+// offsets.
+func (d *DurableSubscription[T]) forward(out chan<- Message[T]) {
+	defer close(out)
+	for msg := range d.sub.C {
+		d.mu.Lock()
+		d.pending = append(d.pending, msg.Offset)
+		d.mu.Unlock()
+		select {
+		case out <- msg:
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// Ack acknowledges the message at offset. Once every earlier message is acknowledged
+// too, the subscription's position is persisted past it.
+func (d *DurableSubscription[T]) Ack(offset uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !slices.Contains(d.pending, offset) {
+		return fmt.Errorf("offset %d is not pending", offset)
+	}
+	d.acked[offset] = true
+	n := 0
+	for n < len(d.pending) && d.acked[d.pending[n]] {
+		delete(d.acked, d.pending[n])
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	committed := d.pending[n-1]
+	d.pending = d.pending[n:]
+	offsets := d.pub.config.store.(OffsetStore)
+	return offsets.CommitOffset(d.group, d.pub.qualified(d.topic), committed)
+}
+
+// Close ends the subscription and closes C, so that name can be opened again.
+// Unacknowledged messages are delivered again on resume.
+func (d *DurableSubscription[T]) Close() error {
+	var err error
+	d.once.Do(func() {
+		close(d.done)
+		err = d.sub.Close()
+		d.release()
+	})
+	return err
+}
+
+// release frees the subscription's name.
+func (d *DurableSubscription[T]) release() {
+	d.pub.Lock()
+	delete(d.pub.durables, d.key)
+	d.pub.Unlock()
+}
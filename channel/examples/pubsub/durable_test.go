@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSubscribeDurable tests that a durable subscription resumes after its last
+// acknowledged message, across reconnects and a restart on the same FileStore
+func TestSubscribeDurable(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := OpenFileStore(dir, WithSyncWrites())
+	pub := NewPublisher[string](WithStore(store), WithDefaultBuffer(8))
+	pub.CreateTopic("orders")
+	pub.Publish("orders", "o1")
+	pub.Publish("orders", "o2")
+
+	sub, err := pub.SubscribeDurable("orders", "billing")
+	if err != nil {
+		t.Fatalf("SubscribeDurable() returned error: %v", err)
+	}
+	if _, err := pub.SubscribeDurable("orders", "billing"); !errors.Is(err, ErrDurableInUse) {
+		t.Errorf("Expected ErrDurableInUse for a second open, got %v", err)
+	}
+	first, second := <-sub.C, <-sub.C
+	if first.Value != "o1" || second.Value != "o2" {
+		t.Fatalf("Expected o1 and o2, got %q and %q", first.Value, second.Value)
+	}
+	sub.Ack(second.Offset) // Out of order: o1 is still pending
+	sub.Close()
+
+	sub, _ = pub.SubscribeDurable("orders", "billing")
+	if msg := <-sub.C; msg.Value != "o1" {
+		t.Errorf("Expected the unacknowledged o1 again, got %q", msg.Value)
+	}
+	if msg := <-sub.C; msg.Value != "o2" {
+		t.Errorf("Expected o2 again, got %q", msg.Value)
+	}
+	sub.Ack(first.Offset)
+	sub.Ack(second.Offset)
+	if err := sub.Ack(first.Offset); err == nil {
+		t.Error("Expected acknowledging twice to fail")
+	}
+	sub.Close()
+
+	pub.Publish("orders", "o3") // While nobody is subscribed
+	pub.Shutdown(t.Context())
+	store.Close()
+
+	store, _ = OpenFileStore(dir)
+	defer store.Close()
+	pub = NewPublisher[string](WithStore(store), WithDefaultBuffer(8))
+	pub.CreateTopic("orders")
+	sub, _ = pub.SubscribeDurable("orders", "billing")
+	defer sub.Close()
+	if msg := <-sub.C; msg.Value != "o3" {
+		t.Errorf("Expected o3 after the restart, got %q", msg.Value)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrSlowSubscriber is the DeadLetter reason for messages that a stalled subscriber
+// missed when it was evicted (see WithSlowSubscriberEviction).
+var ErrSlowSubscriber = errors.New("slow subscriber evicted")
+
+// Eviction describes a subscriber removed by WithSlowSubscriberEviction.
+type Eviction struct {
+	Topic      string        // Topic the subscriber was removed from
+	Subscriber uint64        // Id of the subscriber, as in SubscriberStats
+	Pending    int           // Messages it had not read yet
+	Blocked    time.Duration // Total time publishers waited on it
+}
+
+// SubscriberStats is a snapshot of one subscriber's delivery health.
+type SubscriberStats struct {
+	ID        uint64        // Unique per Publisher
+	Pending   int           // Messages buffered for the subscriber, not read yet
+	Delivered int64         // Messages delivered to it
+	Blocked   time.Duration // Total time publishers waited for room in its channel
+}
+
+// WithSlowSubscriberEviction protects healthy subscribers from a stuck one: a
+// subscriber is closed and removed from its topic when a publish has waited threshold
+// for room in its channel (OverflowBlock), or when its channel has stayed full for
+// threshold (the other overflow policies). onEvict, if not nil, is called after each
+// eviction so the application can react, e.g. resubscribe or alert; it runs on the
+// publishing goroutine and must not block.
+//
+// Times come from the Publisher's clock (see WithClock).
+func WithSlowSubscriberEviction(threshold time.Duration, onEvict func(Eviction)) Option {
+	return func(c *config) {
+		c.stallLimit = threshold
+		c.onEvict = onEvict
+	}
+}
+
+// SubscriberStats returns a snapshot of the delivery health of every subscriber of topic.
+func (p *Publisher[T]) SubscriberStats(topic string) ([]SubscriberStats, error) {
+	s := p.shard(topic)
+	s.RLock()
+	defer s.RUnlock()
+	subscribers, ok := s.subscribers[topic]
+	if !ok {
+		return nil, errors.New("topic not found")
+	}
+	stats := make([]SubscriberStats, len(subscribers))
+	for i, sub := range subscribers {
+		stats[i] = sub.stats()
+	}
+	return stats, nil
+}
+
+// stats returns the subscriber's SubscriberStats.
+func (s *subscriber[T]) stats() SubscriberStats {
+	return SubscriberStats{
+		ID:        s.id,
+		Pending:   s.pending(),
+		Delivered: s.delivered.Load(),
+		Blocked:   time.Duration(s.blocked.Load()),
+	}
+}
+
+// pending returns the number of messages buffered for the subscriber.
+func (s *subscriber[T]) pending() int {
+	if s.msgs != nil {
+		return len(s.msgs) + len(s.outLog)
+	}
+	n := len(s.ch)
+	if s.out != s.ch {
+		n += len(s.out) // Channel behind a replay pump
+	}
+	return n
+}
+
+// stalled reports whether sub should be evicted after an offer that delivered or not,
+// and that timed out after the stall limit or not. Called with the shard's read lock
+// held, possibly by several publishers at once.
+func (p *Publisher[T]) stalled(sub *subscriber[T], delivered, timedOut bool) bool {
+	switch {
+	case p.config.stallLimit <= 0:
+		return false
+	case timedOut:
+		return true
+	case delivered:
+		if sub.fullSince.Load() != 0 {
+			sub.fullSince.Store(0)
+		}
+		return false
+	case sub.overflow == OverflowBlock:
+		return false // Canceled by PublishContext, not stalled
+	}
+	now := max(p.config.clock.Now().UnixNano(), 1) // 0 means "not full"
+	if sub.fullSince.CompareAndSwap(0, now) {
+		return false
+	}
+	return time.Duration(now-sub.fullSince.Load()) >= p.config.stallLimit
+}
+
+// evict removes the stalled subscribers of topic and reports each eviction. It takes
+// the shard's write lock, so it must be called without holding its read lock.
+func (p *Publisher[T]) evict(topic string, slow []*subscriber[T]) {
+	if len(slow) == 0 {
+		return
+	}
+	var evictions []Eviction
+	s := p.shard(topic)
+	s.Lock()
+	for _, sub := range slow {
+		subscribers := s.subscribers[topic]
+		i := slices.Index(subscribers, sub)
+		if i < 0 {
+			continue // Already evicted by a concurrent publish, or closed
+		}
+		evictions = append(evictions, Eviction{
+			Topic:      p.qualified(topic),
+			Subscriber: sub.id,
+			Pending:    sub.pending(),
+			Blocked:    time.Duration(sub.blocked.Load()),
+		})
+		p.removeSubscriberLocked(topic, sub)
+		s.subscribers[topic] = slices.Delete(subscribers, i, i+1)
+	}
+	s.Unlock()
+
+	for _, e := range evictions {
+		p.metrics.evicted.Add(1)
+		p.config.logger.Warn("pubsub: slow subscriber evicted", "topic", e.Topic, "subscriber", e.Subscriber, "pending", e.Pending)
+		if p.config.onEvict != nil {
+			p.config.onEvict(e)
+		}
+	}
+}
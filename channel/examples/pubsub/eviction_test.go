@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+	"goconcurrency/testkit"
+)
+
+// TestEvictStalledSubscriber tests that a subscriber blocking a publish for the
+// threshold is closed and reported, while a healthy subscriber keeps receiving
+func TestEvictStalledSubscriber(t *testing.T) {
+	testkit.Run(t, func(t *testing.T, s *testkit.Scheduler) {
+		evicted := make(chan Eviction, 1)
+		pub := NewPublisher[string](WithClock(s.Clock), WithDefaultBuffer(1), WithDeadLetters(),
+			WithSlowSubscriberEviction(time.Second, func(e Eviction) { evicted <- e }))
+		dead, _ := pub.SubscribeDeadLetters()
+		topic := "ticks"
+		pub.CreateTopic(topic)
+		stuck, _ := pub.Subscribe(topic)
+		healthy, _ := pub.Subscribe(topic)
+
+		pub.Publish(topic, "t1")
+		<-healthy
+		s.Go(func() {
+			if err := pub.Publish(topic, "t2"); err != nil {
+				t.Errorf("Publish() returned error: %v", err)
+			}
+		})
+		s.Advance(time.Second) // The publish blocks on stuck for the threshold
+		s.Wait()
+
+		e := <-evicted
+		if e.Topic != topic || e.Pending != 1 || e.Blocked != time.Second {
+			t.Errorf("Expected an eviction from %s with 1 pending after 1s, got %+v", topic, e)
+		}
+		if letter := <-dead; !errors.Is(letter.Reason, ErrSlowSubscriber) || letter.Message.Value != "t2" {
+			t.Errorf("Expected t2 dead-lettered as ErrSlowSubscriber, got %+v", letter)
+		}
+		if msg := <-healthy; msg != "t2" {
+			t.Errorf("Expected the healthy subscriber to get t2, got %q", msg)
+		}
+		if _, ok := <-stuck; !ok {
+			t.Error("Expected the buffered t1 before the channel closes")
+		}
+		if _, ok := <-stuck; ok {
+			t.Error("Expected the evicted subscriber's channel to be closed")
+		}
+	})
+}
+
+// TestEvictFullSubscriber tests eviction of a non-blocking subscriber whose channel
+// stays full for the threshold
+func TestEvictFullSubscriber(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(1, 0))
+	pub := NewPublisher[string](WithClock(fake), WithDefaultBuffer(1), WithSlowSubscriberEviction(time.Second, nil))
+	topic := "ticks"
+	pub.CreateTopic(topic)
+	pub.Subscribe(topic, WithOverflow(OverflowDropNewest))
+
+	for _, step := range []time.Duration{0, 0, 500 * time.Millisecond, 500 * time.Millisecond} {
+		fake.Advance(step)
+		pub.Publish(topic, "tick")
+	}
+	if stats, _ := pub.SubscriberStats(topic); len(stats) != 0 {
+		t.Errorf("Expected the full subscriber to be evicted, got %+v", stats)
+	}
+	if n := pub.metrics.evicted.Load(); n != 1 {
+		t.Errorf("Expected 1 eviction counted, got %d", n)
+	}
+}
+
+// TestSubscriberStats tests the pending and delivered counts of a subscriber
+func TestSubscriberStats(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	pub.CreateTopic("ticks")
+	ch, _ := pub.Subscribe("ticks")
+	for range 3 {
+		pub.Publish("ticks", "tick")
+	}
+	<-ch
+	stats, err := pub.SubscriberStats("ticks")
+	if err != nil || len(stats) != 1 {
+		t.Fatalf("Expected stats for one subscriber, got %v (err=%v)", stats, err)
+	}
+	if stats[0].Pending != 2 || stats[0].Delivered != 3 || stats[0].Blocked != 0 {
+		t.Errorf("Expected 2 pending, 3 delivered and no blocking, got %+v", stats[0])
+	}
+}
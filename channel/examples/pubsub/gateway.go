@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// gatewayHeartbeat is how often an idle Server-Sent Events stream gets a comment line,
+// so proxies and browsers do not time the connection out.
+const gatewayHeartbeat = 15 * time.Second
+
+// gatewayMaxBody caps the size of a POST body the gateway reads.
+const gatewayMaxBody = 1 << 20
+
+// gatewayEvent is a message as the gateway sends it over WebSocket, one JSON text
+// frame per event.
+type gatewayEvent struct {
+	Topic   string            `json:"topic"`
+	ID      string            `json:"id,omitempty"`
+	Time    time.Time         `json:"time"`
+	Key     string            `json:"key,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Data    string            `json:"data,omitempty"` // The value, encoded with the Publisher's Codec (see gatewayEvent)
+	Deleted bool              `json:"deleted,omitempty"`
+	Closed  bool              `json:"closed,omitempty"` // The topic was closed; no events follow
+}
+
+// HTTPHandler returns an http.Handler that bridges browser clients to the Publisher's
+// topics, for demo dashboards:
+//
+//   - GET /topics/{name}/stream: streams the topic's messages as Server-Sent Events
+//     (event "message", or "delete" for tombstones, with the message ID as the event
+//     ID), or over WebSocket when the request asks for an upgrade (one JSON
+//     gatewayEvent per text frame). The stream ends with a "close" event when the
+//     topic is closed.
+//   - POST /topics/{name}: publishes the request body, decoded with the Publisher's
+//     Codec, with the optional "key" query parameter as the message key. Answers 204
+//     once published, 404 for an unknown topic, 400 for a body that does not decode
+//     or fails the topic's validator, 403 when the Authorizer refuses, and 503 after
+//     Shutdown or when the delivery queue is full.
+//
+// Payloads are written as text, so the gateway suits text codecs such as JSONCodec
+// (the default). Topics with a cipher (WithCipher) stay encrypted through the gateway:
+// streamed events carry the sealed payload base64-encoded, and POST bodies must be
+// sealed with the topic's Cipher. Streams subscribe with OverflowDropOldest: a slow browser misses
+// messages instead of stalling publishers. Like Publish and SubscribeMessages, the
+// gateway acts as Anonymous towards the Authorizer; WebSocket upgrades are accepted
+// from any origin, so put the handler behind your own checks outside of demos.
+//
+// Go Concurrency Patterns used:
+//   - Context cancellation: a stream ends when the request's context is done (the
+//     browser went away), which closes its subscription
+//   - Reader goroutine: a WebSocket connection is read by its own goroutine, whose
+//     exit on close or error stops the writer
+//
+// Usage example:
+//
+//	http.Handle("/", pub.HTTPHandler())
+//	http.ListenAndServe(":8080", nil)
+//
+//	// In the browser:
+//	new EventSource("/topics/orders/stream").onmessage = e => render(JSON.parse(e.data))
+func (p *Publisher[T]) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /topics/{name}/stream", p.serveStream)
+	mux.HandleFunc("POST /topics/{name}", p.servePost)
+	return mux
+}
+
+// servePost publishes the body of a POST request.
+func (p *Publisher[T]) servePost(w http.ResponseWriter, r *http.Request) {
+	topic := r.PathValue("name")
+	if !p.hasTopic(p.resolve(topic)) {
+		http.Error(w, "topic not found", http.StatusNotFound)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, gatewayMaxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	var value T
+	if err := p.decodePayload(topic, body, &value); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = p.PublishMessage(topic, Message[T]{Key: r.URL.Query().Get("key"), Value: value})
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrInvalidMessage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrShutdown), errors.Is(err, ErrQueueFull):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// serveStream subscribes to the topic and streams its messages over WebSocket or as
+// Server-Sent Events, depending on the request.
+func (p *Publisher[T]) serveStream(w http.ResponseWriter, r *http.Request) {
+	topic := r.PathValue("name")
+	sub, err := p.SubscribeMessages(topic, WithOverflow(OverflowDropOldest))
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer sub.Close()
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		websocket.Server{Handler: func(ws *websocket.Conn) { p.streamWebSocket(ws, sub) }}.ServeHTTP(w, r)
+		return
+	}
+	p.streamEvents(w, r, sub)
+}
+
+// streamEvents writes the messages of sub as Server-Sent Events until the topic is
+// closed or the client goes away.
+func (p *Publisher[T]) streamEvents(w http.ResponseWriter, r *http.Request, sub *Subscription[T]) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := p.config.clock.NewTimer(gatewayHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				io.WriteString(w, "event: close\ndata:\n\n")
+				flusher.Flush()
+				return
+			}
+			event, err := p.gatewayEvent(msg)
+			if err != nil {
+				p.config.logger.Warn("pubsub: gateway encode failed", "topic", msg.Topic, "error", err)
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C():
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+		heartbeat.Reset(gatewayHeartbeat)
+	}
+}
+
+// writeEvent writes event in the Server-Sent Events format. Each line of the payload
+// becomes a data line, which the browser joins back with newlines.
+func writeEvent(w io.Writer, event gatewayEvent) error {
+	var b strings.Builder
+	name, data := "message", event.Data
+	if event.Deleted {
+		name, data = "delete", event.Key
+	}
+	fmt.Fprintf(&b, "event: %s\n", name)
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", event.ID)
+	}
+	for line := range strings.SplitSeq(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// streamWebSocket sends the messages of sub as JSON frames over ws until the topic is
+// closed or the connection breaks. Frames from the client are read and discarded.
+func (p *Publisher[T]) streamWebSocket(ws *websocket.Conn, sub *Subscription[T]) {
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		io.Copy(io.Discard, ws) // Returns once the client closes the connection
+	}()
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				websocket.JSON.Send(ws, gatewayEvent{Topic: p.qualified(sub.topic), Closed: true})
+				return
+			}
+			event, err := p.gatewayEvent(msg)
+			if err != nil {
+				p.config.logger.Warn("pubsub: gateway encode failed", "topic", msg.Topic, "error", err)
+				continue
+			}
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// gatewayEvent converts msg for the gateway, encoding its value with the Codec. The
+// payload of a topic with a cipher is binary, so it is sent base64-encoded.
+func (p *Publisher[T]) gatewayEvent(msg Message[T]) (gatewayEvent, error) {
+	event := gatewayEvent{Topic: msg.Topic, ID: msg.ID, Time: msg.Time, Key: msg.Key, Headers: msg.Headers, Deleted: msg.Deleted}
+	if !msg.Deleted {
+		data, err := p.config.encodePayload(msg.Topic, msg.Value)
+		if err != nil {
+			return gatewayEvent{}, err
+		}
+		event.Data = string(data)
+		if p.config.cipherFor(msg.Topic) != nil {
+			event.Data = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	return event, nil
+}
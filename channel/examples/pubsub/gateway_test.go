@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// TestHTTPGateway tests publishing with POST and receiving over Server-Sent Events and
+// WebSocket
+func TestHTTPGateway(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(8))
+	pub.CreateTopic("orders")
+	server := httptest.NewServer(pub.HTTPHandler())
+	defer server.Close()
+
+	post := func(topic, body string) int {
+		resp, err := http.Post(server.URL+"/topics/"+topic+"?key=k1", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST returned error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("missing", `"x"`); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown topic, got %d", code)
+	}
+	if code := post("orders", `not json`); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an undecodable body, got %d", code)
+	}
+
+	resp, err := http.Get(server.URL + "/topics/orders/stream")
+	if err != nil {
+		t.Fatalf("GET stream returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+	ws, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+"/topics/orders/stream", "", server.URL)
+	if err != nil {
+		t.Fatalf("websocket.Dial() returned error: %v", err)
+	}
+	defer ws.Close()
+	waitFor(t, func() bool { return pub.Stats()["orders"].Subscribers == 2 })
+
+	if code := post("orders", `"o1"`); code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for a publish, got %d", code)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	var event []string
+	for lines.Scan() && lines.Text() != "" {
+		event = append(event, lines.Text())
+	}
+	if len(event) != 3 || event[0] != "event: message" || !strings.HasPrefix(event[1], "id: ") || event[2] != `data: "o1"` {
+		t.Errorf("Unexpected SSE event %q", event)
+	}
+
+	var frame gatewayEvent
+	if err := websocket.JSON.Receive(ws, &frame); err != nil {
+		t.Fatalf("Receive() returned error: %v", err)
+	}
+	if frame.Topic != "orders" || frame.Key != "k1" || frame.Data != `"o1"` {
+		t.Errorf("Unexpected WebSocket frame %+v", frame)
+	}
+
+	pub.CloseTopic("orders")
+	if err := websocket.JSON.Receive(ws, &frame); err != nil || !frame.Closed {
+		t.Errorf("Expected a closed frame, got %+v (%v)", frame, err)
+	}
+}
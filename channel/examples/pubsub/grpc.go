@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+)
+
+// The gRPC bridge lets two processes share topics: one serves its Publisher with
+// RegisterGRPC, the other publishes and subscribes through a GRPCClient. The service
+// has two bidirectional streams:
+//
+//   - Publish: the client sends PublishRequests, the server answers each with a
+//     PublishResponse
+//   - SubscribeStream: the client sends SubscribeRequests to add or drop topics, the
+//     server streams the messages of every subscribed topic as StreamMessages
+//
+// The wire messages are plain structs carried as JSON (see grpcWireCodec), so the
+// bridge needs no generated code; payloads inside them are encoded with the
+// Publisher's Codec (WithCodec), which client and server must agree on, and may be
+// compressed (WithGRPCCompression).
+
+// grpcServiceName is the full name of the bridge's gRPC service.
+const grpcServiceName = "goconcurrency.pubsub.PubSub"
+
+// grpcCodecName is the content subtype the bridge's streams are opened with.
+const grpcCodecName = "pubsub-json"
+
+// PublishRequest publishes one message (the client side of the Publish stream).
+type PublishRequest struct {
+	Topic   string            `json:"topic"`
+	ID      string            `json:"id,omitempty"` // Kept when the client retries, see PublishWithID
+	Key     string            `json:"key,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload []byte            `json:"payload"` // The value, encoded with the Publisher's Codec
+
+	Compressed bool `json:"compressed,omitempty"` // Payload is compressed, see WithGRPCCompression
+}
+
+// PublishResponse answers one PublishRequest, in order.
+type PublishResponse struct {
+	Error string `json:"error,omitempty"` // Empty if the message was published
+}
+
+// SubscribeRequest adds a topic to a SubscribeStream, or drops it.
+type SubscribeRequest struct {
+	Topic       string `json:"topic"`
+	Unsubscribe bool   `json:"unsubscribe,omitempty"`
+}
+
+// StreamMessage is one message of a subscribed topic (the server side of the
+// SubscribeStream), or the end of a topic's subscription when Closed is set.
+type StreamMessage struct {
+	Topic   string            `json:"topic"`
+	ID      string            `json:"id,omitempty"`
+	Time    time.Time         `json:"time"`
+	Key     string            `json:"key,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload []byte            `json:"payload,omitempty"`
+	Deleted bool              `json:"deleted,omitempty"`
+	Closed  bool              `json:"closed,omitempty"` // The subscription ended (topic closed, or Error)
+	Error   string            `json:"error,omitempty"`  // Why the subscription failed or ended
+
+	Compressed bool `json:"compressed,omitempty"` // Payload is compressed, see WithGRPCCompression
+}
+
+// grpcWireCodec carries the bridge's wire messages as JSON.
+type grpcWireCodec struct{}
+
+func (grpcWireCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (grpcWireCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (grpcWireCodec) Name() string                       { return grpcCodecName }
+
+func init() {
+	encoding.RegisterCodec(grpcWireCodec{})
+}
+
+// RegisterGRPC serves the Publisher's topics on s (see GRPCClient for the other side).
+// Remote publishes and subscriptions act as Anonymous towards the Authorizer, like
+// Publish and SubscribeMessages. Call it before s.Serve.
+//
+// Usage example:
+//
+//	s := grpc.NewServer()
+//	pub.RegisterGRPC(s)
+//	lis, _ := net.Listen("tcp", ":7070")
+//	go s.Serve(lis)
+func (p *Publisher[T]) RegisterGRPC(s *grpc.Server) {
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: grpcServiceName,
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    "Publish",
+				Handler:       func(_ any, stream grpc.ServerStream) error { return p.servePublish(stream) },
+				ServerStreams: true,
+				ClientStreams: true,
+			},
+			{
+				StreamName:    "SubscribeStream",
+				Handler:       func(_ any, stream grpc.ServerStream) error { return p.serveSubscribe(stream) },
+				ServerStreams: true,
+				ClientStreams: true,
+			},
+		},
+	}, p)
+}
+
+// servePublish publishes every request of a Publish stream and answers it. The first
+// answer carries the compressions the server decodes, for the client to pick from.
+func (p *Publisher[T]) servePublish(stream grpc.ServerStream) error {
+	if err := stream.SetHeader(metadata.Pairs(grpcCompressionHeader, p.config.grpcAccepted())); err != nil {
+		return err
+	}
+	for {
+		var req PublishRequest
+		if err := stream.RecvMsg(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		var resp PublishResponse
+		var value T
+		payload, err := unpackPayload(req.Payload, req.Compressed)
+		if err == nil {
+			err = p.decodePayload(req.Topic, payload, &value)
+		}
+		if err == nil {
+			err = p.PublishMessage(req.Topic, Message[T]{ID: req.ID, Key: req.Key, Headers: req.Headers, Value: value})
+		}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if err := stream.SendMsg(&resp); err != nil {
+			return err
+		}
+	}
+}
+
+// serveSubscribe runs a SubscribeStream: it subscribes to the requested topics and
+// sends their messages until the client goes away, compressed if the client accepts
+// the server's compression.
+//
+// Go Concurrency Patterns used:
+//   - Fan-in: one goroutine per subscribed topic forwards into a single channel, as
+//     only one goroutine may send on a gRPC stream
+//   - Context cancellation: the stream's context stops the forwarders once the handler
+//     returns, so none is left blocked on the fan-in channel
+func (p *Publisher[T]) serveSubscribe(stream grpc.ServerStream) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	md, _ := metadata.FromIncomingContext(ctx)
+	packer := p.config.grpcPacker(md)
+
+	requests := make(chan SubscribeRequest)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			var req SubscribeRequest
+			if err := stream.RecvMsg(&req); err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out := make(chan streamItem[T])
+	subs := make(map[string]*Subscription[T])
+	defer func() {
+		for _, sub := range subs {
+			sub.Close()
+		}
+	}()
+	for {
+		select {
+		case req := <-requests:
+			if req.Unsubscribe {
+				if sub, ok := subs[req.Topic]; ok {
+					sub.Close()
+					delete(subs, req.Topic)
+					if err := stream.SendMsg(&StreamMessage{Topic: req.Topic, Closed: true}); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if _, ok := subs[req.Topic]; ok {
+				continue
+			}
+			sub, err := p.SubscribeMessages(req.Topic)
+			if err != nil {
+				if err := stream.SendMsg(&StreamMessage{Topic: req.Topic, Closed: true, Error: err.Error()}); err != nil {
+					return err
+				}
+				continue
+			}
+			subs[req.Topic] = sub
+			go p.forwardStream(ctx, req.Topic, sub, packer, out)
+		case item := <-out:
+			if subs[item.msg.Topic] != item.sub {
+				continue // Left over from a subscription dropped by an Unsubscribe
+			}
+			if item.msg.Closed {
+				delete(subs, item.msg.Topic)
+			}
+			if err := stream.SendMsg(item.msg); err != nil {
+				return err
+			}
+		case err := <-recvErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// streamItem is a StreamMessage on its way from the forwarder of sub to the stream.
+type streamItem[T any] struct {
+	sub *Subscription[T]
+	msg *StreamMessage
+}
+
+// forwardStream encodes the messages of sub into out, compressing them with packer if
+// it is not nil, then reports the end of the subscription.
+func (p *Publisher[T]) forwardStream(ctx context.Context, topic string, sub *Subscription[T], packer *CompressedCodec, out chan<- streamItem[T]) {
+	send := func(m *StreamMessage) bool {
+		select {
+		case out <- streamItem[T]{sub: sub, msg: m}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for msg := range sub.C {
+		m := &StreamMessage{Topic: topic, ID: msg.ID, Time: msg.Time, Key: msg.Key, Headers: msg.Headers, Deleted: msg.Deleted}
+		if !msg.Deleted {
+			payload, err := p.config.encodePayload(msg.Topic, msg.Value)
+			if err == nil {
+				m.Payload, m.Compressed, err = packPayload(packer, payload)
+			}
+			if err != nil {
+				p.config.logger.Warn("pubsub: grpc encode failed", "topic", topic, "error", err)
+				continue
+			}
+		}
+		if !send(m) {
+			return
+		}
+	}
+	send(&StreamMessage{Topic: topic, Closed: true})
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Reconnect delays of GRPCClient subscriptions: the first retry waits grpcMinBackoff,
+// and every failed attempt doubles the delay up to grpcMaxBackoff.
+const (
+	grpcMinBackoff = 50 * time.Millisecond
+	grpcMaxBackoff = 5 * time.Second
+)
+
+// The bridge's streams as the client opens them.
+var (
+	grpcPublishDesc   = grpc.StreamDesc{StreamName: "Publish", ServerStreams: true, ClientStreams: true}
+	grpcSubscribeDesc = grpc.StreamDesc{StreamName: "SubscribeStream", ServerStreams: true, ClientStreams: true}
+)
+
+// GRPCClient publishes and subscribes to the topics of a Publisher in another process,
+// served with RegisterGRPC, so two processes can share topics.
+//
+// The client survives server restarts: a Publish on a broken stream waits for the
+// connection to come back (bounded by its ctx) and retries once with the same message
+// ID, so subscribers created WithDedup see it once; subscriptions reconnect in the
+// background with exponential backoff and resubscribe to their topics. Messages
+// published while a subscription was disconnected are not delivered to it.
+//
+// Go Concurrency Patterns used:
+//   - Single receiver goroutine: one loop owns the SubscribeStream and dispatches what
+//     it receives to the per-topic channels, which only it sends on and closes
+//   - Mutex around sends: Subscribe and the reconnecting loop both send requests on the
+//     stream, which allows one sender at a time
+//   - Retry with exponential backoff: reconnect attempts slow down while the server is
+//     away instead of spinning
+type GRPCClient[T any] struct {
+	conn     *grpc.ClientConn
+	config   config
+	idPrefix string
+	idSeq    atomic.Uint64
+	ctx      context.Context    // Canceled by Close, ends every stream
+	cancel   context.CancelFunc // Cancels ctx
+	done     chan struct{}      // Closed when the subscription loop has exited
+
+	pubMu     sync.Mutex        // Serializes publishes: one request, then its response
+	pubStream grpc.ClientStream // Publish stream, nil until needed or after a failure
+	pubPacker *CompressedCodec  // Compresses publishes once the server accepted it, else nil
+	pubHeard  bool              // The server's header on pubStream has been read
+
+	mu     sync.Mutex                 // Protects subs and sends on stream
+	subs   map[string]chan Message[T] // Topic -> channel handed out by Subscribe
+	stream grpc.ClientStream          // Current SubscribeStream, nil while reconnecting
+}
+
+// NewGRPCClient returns a client of the bridge served at the other end of conn, which
+// the caller creates (grpc.NewClient) and closes after Close. It takes the Publisher
+// options that apply to it: WithCodec and WithTopicCodec (must match the server's), WithDefaultBuffer
+// (capacity of subscription channels), WithGRPCCompression, WithLogger and WithClock.
+//
+// Usage example:
+//
+//	conn, err := grpc.NewClient("broker:7070", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	if err != nil { ... }
+//	client := NewGRPCClient[Order](conn)
+//	defer client.Close()
+//	orders, _ := client.Subscribe("orders")
+//	client.Publish(ctx, "orders", order)
+func NewGRPCClient[T any](conn *grpc.ClientConn, opts ...Option) *GRPCClient[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &GRPCClient[T]{
+		conn:     conn,
+		config:   newConfig(opts),
+		idPrefix: newIDPrefix(),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		subs:     make(map[string]chan Message[T]),
+	}
+	go c.subscribeLoop()
+	return c
+}
+
+// Publish publishes value to topic on the server. ctx bounds waiting for the
+// connection; once the request is sent, Publish waits for the server's answer.
+func (c *GRPCClient[T]) Publish(ctx context.Context, topic string, value T) error {
+	payload, err := c.config.encodePayload(topic, value)
+	if err != nil {
+		return err
+	}
+	req := &PublishRequest{Topic: topic, ID: c.idPrefix + strconv.FormatUint(c.idSeq.Add(1), 36), Payload: payload}
+
+	c.pubMu.Lock()
+	defer c.pubMu.Unlock()
+	for attempt := 1; ; attempt++ {
+		resp, err := c.publishOnce(ctx, req)
+		if err == nil {
+			if resp.Error != "" {
+				return fmt.Errorf("remote publish to %q: %s", topic, resp.Error)
+			}
+			return nil
+		}
+		if c.pubStream != nil {
+			c.pubStream.CloseSend()
+			c.pubStream = nil // Reopened by the next attempt
+		}
+		if attempt == 2 || ctx.Err() != nil || c.ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// publishOnce sends req on the publish stream, opening it first if needed, and reads
+// the answer. The first request on a stream goes uncompressed: the server's answer to
+// it tells whether it accepts compressed ones. Called with pubMu held.
+func (c *GRPCClient[T]) publishOnce(ctx context.Context, req *PublishRequest) (*PublishResponse, error) {
+	if c.pubStream == nil {
+		if err := c.waitReady(ctx); err != nil {
+			return nil, err
+		}
+		stream, err := c.conn.NewStream(c.config.grpcOutgoing(c.ctx), &grpcPublishDesc, "/"+grpcServiceName+"/Publish",
+			grpc.CallContentSubtype(grpcCodecName))
+		if err != nil {
+			return nil, err
+		}
+		c.pubStream, c.pubPacker, c.pubHeard = stream, nil, false
+	}
+	sent := *req // req keeps the plain payload for a retry on a new stream
+	var err error
+	if sent.Payload, sent.Compressed, err = packPayload(c.pubPacker, req.Payload); err != nil {
+		return nil, err
+	}
+	if err := c.pubStream.SendMsg(&sent); err != nil {
+		return nil, err
+	}
+	var resp PublishResponse
+	if err := c.pubStream.RecvMsg(&resp); err != nil {
+		return nil, err
+	}
+	if !c.pubHeard {
+		if md, err := c.pubStream.Header(); err == nil {
+			c.pubPacker = c.config.grpcPacker(md)
+		}
+		c.pubHeard = true
+	}
+	return &resp, nil
+}
+
+// waitReady waits until the connection is up, or ctx is done.
+func (c *GRPCClient[T]) waitReady(ctx context.Context) error {
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if state == connectivity.Shutdown {
+			return errors.New("grpc connection closed")
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Subscribe subscribes to topic on the server and returns the channel its messages
+// arrive on. The channel is closed when the server ends the subscription (the topic
+// was closed, or does not exist after a reconnect) and by Close.
+func (c *GRPCClient[T]) Subscribe(topic string) (<-chan Message[T], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ctx.Err() != nil {
+		return nil, errors.New("grpc client closed")
+	}
+	if _, ok := c.subs[topic]; ok {
+		return nil, fmt.Errorf("already subscribed to %q", topic)
+	}
+	ch := make(chan Message[T], c.config.buffer)
+	c.subs[topic] = ch
+	if c.stream != nil { // Otherwise the loop subscribes once it reconnects
+		c.stream.SendMsg(&SubscribeRequest{Topic: topic})
+	}
+	return ch, nil
+}
+
+// Close ends every subscription, closing their channels, and the publish stream. It
+// does not close the connection.
+func (c *GRPCClient[T]) Close() error {
+	c.cancel()
+	<-c.done
+	c.pubMu.Lock()
+	if c.pubStream != nil {
+		c.pubStream.CloseSend()
+		c.pubStream = nil
+	}
+	c.pubMu.Unlock()
+	return nil
+}
+
+// subscribeLoop keeps a SubscribeStream open until Close, reconnecting with backoff.
+func (c *GRPCClient[T]) subscribeLoop() {
+	defer close(c.done)
+	defer func() {
+		c.mu.Lock()
+		for topic, ch := range c.subs {
+			close(ch)
+			delete(c.subs, topic)
+		}
+		c.mu.Unlock()
+	}()
+
+	backoff := grpcMinBackoff
+	for {
+		connected, err := c.subscribeOnce()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = grpcMinBackoff // The stream worked: start over after it broke
+		}
+		c.config.logger.Warn("pubsub: grpc subscribe stream lost, reconnecting", "error", err, "backoff", backoff)
+		timer := c.config.clock.NewTimer(backoff)
+		select {
+		case <-timer.C():
+		case <-c.ctx.Done():
+			timer.Stop()
+			return
+		}
+		backoff = min(2*backoff, grpcMaxBackoff)
+	}
+}
+
+// subscribeOnce opens a SubscribeStream, subscribes to every topic and dispatches
+// messages until the stream breaks. connected reports whether the stream was set up.
+func (c *GRPCClient[T]) subscribeOnce() (connected bool, err error) {
+	stream, err := c.conn.NewStream(c.config.grpcOutgoing(c.ctx), &grpcSubscribeDesc, "/"+grpcServiceName+"/SubscribeStream",
+		grpc.WaitForReady(true), grpc.CallContentSubtype(grpcCodecName))
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	for topic := range c.subs {
+		if err := stream.SendMsg(&SubscribeRequest{Topic: topic}); err != nil {
+			c.mu.Unlock()
+			return false, err
+		}
+	}
+	c.stream = stream
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.stream = nil
+		c.mu.Unlock()
+	}()
+
+	for {
+		var m StreamMessage
+		if err := stream.RecvMsg(&m); err != nil {
+			return true, err
+		}
+		c.dispatch(&m)
+	}
+}
+
+// dispatch hands m to the channel of its topic, or closes the channel if the server
+// ended the subscription.
+func (c *GRPCClient[T]) dispatch(m *StreamMessage) {
+	c.mu.Lock()
+	ch, ok := c.subs[m.Topic]
+	if ok && m.Closed {
+		delete(c.subs, m.Topic)
+	}
+	c.mu.Unlock()
+	switch {
+	case !ok:
+		return
+	case m.Closed:
+		if m.Error != "" {
+			c.config.logger.Warn("pubsub: grpc subscription ended", "topic", m.Topic, "error", m.Error)
+		}
+		close(ch)
+		return
+	}
+
+	msg := Message[T]{ID: m.ID, Topic: m.Topic, Time: m.Time, Key: m.Key, Headers: m.Headers, Deleted: m.Deleted}
+	if !m.Deleted {
+		payload, err := unpackPayload(m.Payload, m.Compressed)
+		if err == nil {
+			err = c.config.decodePayload(m.Topic, payload, &msg.Value)
+		}
+		if err != nil {
+			c.config.logger.Warn("pubsub: grpc decode failed", "topic", m.Topic, "error", err)
+			return
+		}
+	}
+	select {
+	case ch <- msg:
+	case <-c.ctx.Done():
+	}
+}
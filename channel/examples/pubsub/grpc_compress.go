@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcCompressionHeader is the stream metadata key in which each side of the gRPC
+// bridge lists the compressions it can decode, in order of preference.
+const grpcCompressionHeader = "pubsub-compression"
+
+// grpcCompressions names the compressions this bridge decodes, for the header.
+var grpcCompressions = map[Compression]string{Gzip: "gzip", Snappy: "snappy"}
+
+// WithGRPCCompression makes the gRPC bridge (RegisterGRPC, NewGRPCClient) compress
+// message payloads of threshold bytes or more with compression before they go on the
+// wire, for topics with large payloads on slow or metered links. Smaller payloads,
+// and those compression does not shrink, are sent as they are, as compressing them
+// costs CPU for no gain; see BenchmarkGRPCCompression for the trade-off.
+//
+// Compression is negotiated per connection: each side lists the compressions it
+// decodes in the stream metadata, and a side only compresses towards a peer that
+// listed its algorithm. A peer without compression support therefore keeps receiving
+// plain payloads, and the two sides may use different algorithms or thresholds.
+// Compressed payloads are decompressed up to DefaultMaxDecodedSize.
+//
+// Usage example:
+//
+//	pub := NewPublisher[Event](WithGRPCCompression(Snappy, 1024))
+//	pub.RegisterGRPC(server)
+//	...
+//	client := NewGRPCClient[Event](conn, WithGRPCCompression(Gzip, 4096))
+func WithGRPCCompression(compression Compression, threshold int) Option {
+	return func(c *config) {
+		c.grpcCompression = NewCompressedCodec(rawCodec{}, compression, threshold)
+	}
+}
+
+// rawCodec is the inner codec of the bridge's compression: payloads are already
+// encoded with the Publisher's Codec, so it passes them through.
+type rawCodec struct{}
+
+func (rawCodec) Name() string { return "raw" }
+
+func (rawCodec) Encode(v any) ([]byte, error) { return v.([]byte), nil }
+
+func (rawCodec) Decode(data []byte, v any) error {
+	*v.(*[]byte) = data
+	return nil
+}
+
+// grpcUnpacker decompresses the payloads a peer sent compressed, whether or not this
+// side compresses itself.
+var grpcUnpacker = NewCompressedCodec(rawCodec{}, Gzip, 0)
+
+// grpcAccepted is the header value announcing the compressions this side decodes,
+// its own one first.
+func (c *config) grpcAccepted() string {
+	names := make([]string, 0, len(grpcCompressions))
+	if c.grpcCompression != nil {
+		names = append(names, grpcCompressions[c.grpcCompression.compression])
+	}
+	for _, compression := range []Compression{Gzip, Snappy} {
+		if name := grpcCompressions[compression]; !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// grpcPacker returns the codec to compress payloads towards a peer that sent md, or
+// nil if this side does not compress or the peer cannot decode its algorithm.
+func (c *config) grpcPacker(md metadata.MD) *CompressedCodec {
+	if c.grpcCompression == nil {
+		return nil
+	}
+	want := grpcCompressions[c.grpcCompression.compression]
+	for _, value := range md.Get(grpcCompressionHeader) {
+		if slices.Contains(strings.Split(value, ","), want) {
+			return c.grpcCompression
+		}
+	}
+	return nil
+}
+
+// grpcOutgoing returns ctx with this side's compression header attached, for opening a
+// stream.
+func (c *config) grpcOutgoing(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, grpcCompressionHeader, c.grpcAccepted())
+}
+
+// packPayload compresses payload with packer, if there is one and compression is
+// worth it, and reports whether it did.
+func packPayload(packer *CompressedCodec, payload []byte) ([]byte, bool, error) {
+	if packer == nil {
+		return payload, false, nil
+	}
+	return packer.pack(payload)
+}
+
+// unpackPayload returns payload decompressed if the peer sent it compressed.
+func unpackPayload(payload []byte, compressed bool) ([]byte, error) {
+	if !compressed {
+		return payload, nil
+	}
+	return grpcUnpacker.unpack(payload)
+}
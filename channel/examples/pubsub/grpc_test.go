@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// TestGRPCBridge tests publishing and subscribing through the gRPC bridge, and that the
+// client reconnects and resubscribes after the server restarts
+func TestGRPCBridge(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(8))
+	pub.CreateTopic("orders")
+	var listener atomic.Pointer[bufconn.Listener]
+	serve := func() *grpc.Server {
+		lis := bufconn.Listen(1 << 16)
+		listener.Store(lis)
+		server := grpc.NewServer()
+		pub.RegisterGRPC(server)
+		go server.Serve(lis)
+		return server
+	}
+	server := serve()
+
+	conn, err := grpc.NewClient("passthrough:///pubsub",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Load().DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() returned error: %v", err)
+	}
+	defer conn.Close()
+	client := NewGRPCClient[string](conn, WithDefaultBuffer(8))
+	defer client.Close()
+
+	remote, err := client.Subscribe("orders")
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	local, _ := pub.Subscribe("orders")
+	waitFor(t, func() bool { return pub.Stats()["orders"].Subscribers == 2 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Publish(ctx, "orders", "o1"); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	if msg := <-local; msg != "o1" {
+		t.Errorf("Expected o1 on the server, got %q", msg)
+	}
+	if msg := <-remote; msg.Value != "o1" || msg.Topic != "orders" || msg.ID == "" {
+		t.Errorf("Expected o1 with topic and ID on the client, got %+v", msg)
+	}
+	if err := client.Publish(ctx, "missing", "x"); err == nil {
+		t.Error("Expected an unknown topic to be reported")
+	}
+
+	before, _ := pub.SubscriberStats("orders")
+	server.Stop()
+	defer serve().Stop()
+	waitFor(t, func() bool { // The old stream's subscriber is replaced by a new one
+		after, _ := pub.SubscriberStats("orders")
+		return len(after) == 2 && after[1].ID != before[0].ID && after[1].ID != before[1].ID
+	})
+	if err := client.Publish(ctx, "orders", "o2"); err != nil {
+		t.Fatalf("Publish() after the restart returned error: %v", err)
+	}
+	if msg := <-remote; msg.Value != "o2" {
+		t.Errorf("Expected o2 after the restart, got %+v", msg)
+	}
+}
+
+// countingConn counts the bytes that pass through a connection in either direction.
+type countingConn struct {
+	net.Conn
+	bytes *atomic.Int64
+}
+
+func (c countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.bytes.Add(int64(n))
+	return n, err
+}
+
+func (c countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.bytes.Add(int64(n))
+	return n, err
+}
+
+// newGRPCBridge serves pub on an in-memory connection and returns a client of it with
+// opts, and the counter of the bytes exchanged between the two.
+func newGRPCBridge(tb testing.TB, pub *Publisher[string], opts ...Option) (*GRPCClient[string], *atomic.Int64) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pub.RegisterGRPC(server)
+	go server.Serve(lis)
+	tb.Cleanup(server.Stop)
+
+	wire := new(atomic.Int64)
+	conn, err := grpc.NewClient("passthrough:///pubsub",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			conn, err := lis.DialContext(ctx)
+			return countingConn{conn, wire}, err
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		tb.Fatalf("grpc.NewClient() returned error: %v", err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+	client := NewGRPCClient[string](conn, append([]Option{WithDefaultBuffer(8)}, opts...)...)
+	tb.Cleanup(func() { client.Close() })
+	return client, wire
+}
+
+// TestGRPCCompression tests that payloads above the threshold travel compressed in both
+// directions when both sides enable it, with different algorithms, and that a side
+// only compresses towards a peer that announced its algorithm
+func TestGRPCCompression(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(8), WithGRPCCompression(Snappy, 256))
+	pub.CreateTopic("events")
+	client, _ := newGRPCBridge(t, pub, WithGRPCCompression(Gzip, 256))
+	remote, _ := client.Subscribe("events")
+	waitFor(t, func() bool { return pub.Stats()["events"].Subscribers == 1 })
+
+	large := strings.Repeat("event body ", 100)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, value := range []string{"first", large, "small", large} {
+		if err := client.Publish(ctx, "events", value); err != nil {
+			t.Fatalf("Publish() returned error: %v", err)
+		}
+		if msg := <-remote; msg.Value != value {
+			t.Errorf("Expected %.20q... back, got %.20q...", value, msg.Value)
+		}
+	}
+	// The client's first publish goes out plain, before it has the server's header
+	if got := client.config.grpcCompression.Stats(); got.Compressed != 2 || got.Uncompressed != 1 {
+		t.Errorf("Expected the client to compress the large payloads only, got %+v", got)
+	}
+	if got := pub.config.grpcCompression.Stats(); got.Compressed != 2 || got.Uncompressed != 2 {
+		t.Errorf("Expected the server to compress the large payloads only, got %+v", got)
+	}
+
+	if pub.config.grpcPacker(nil) != nil {
+		t.Error("Expected no compression towards a peer without the header")
+	}
+	gzipOnly := newConfig([]Option{WithGRPCCompression(Snappy, 0)})
+	if gzipOnly.grpcPacker(metadata.Pairs(grpcCompressionHeader, "gzip")) != nil {
+		t.Error("Expected no snappy towards a peer that only decodes gzip")
+	}
+}
+
+// BenchmarkGRPCCompression measures publishing through the gRPC bridge with and
+// without compression: ns/op shows the CPU cost, wire-B/op the bytes on the wire
+func BenchmarkGRPCCompression(b *testing.B) {
+	line := `{"level":"info","service":"checkout","msg":"order placed","order_id":%d,"amount":%d}` + "\n"
+	for _, size := range []int{256, 4 << 10, 64 << 10} {
+		var payload strings.Builder
+		for i := 0; payload.Len() < size; i++ {
+			fmt.Fprintf(&payload, line, i, i*37%1000)
+		}
+		value := payload.String()[:size]
+		for _, mode := range []struct {
+			name string
+			opts []Option
+		}{
+			{"none", nil},
+			{"gzip", []Option{WithGRPCCompression(Gzip, 1024)}},
+			{"snappy", []Option{WithGRPCCompression(Snappy, 1024)}},
+		} {
+			b.Run(fmt.Sprintf("%dB/%s", size, mode.name), func(b *testing.B) {
+				pub := NewPublisher[string](mode.opts...)
+				pub.CreateTopic("logs")
+				client, wire := newGRPCBridge(b, pub, mode.opts...)
+				ctx := context.Background()
+				client.Publish(ctx, "logs", "warm up") // Opens the stream and reads the server's header
+				wire.Store(0)
+				for b.Loop() {
+					if err := client.Publish(ctx, "logs", value); err != nil {
+						b.Fatalf("Publish() returned error: %v", err)
+					}
+				}
+				b.ReportMetric(float64(wire.Load())/float64(b.N), "wire-B/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrHandlerPanic is the DeadLetter reason (wrapped together with the panic value) for
+// messages whose SubscribeFunc handler panicked.
+var ErrHandlerPanic = errors.New("handler panicked")
+
+// WithConcurrency lets a handler subscription (SubscribeFunc) run up to n handlers at
+// once (1 by default, which handles messages one at a time in publish order). With
+// n > 1 messages are handled in parallel and may complete out of order.
+func WithConcurrency(n int) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.concurrency = n
+	}
+}
+
+// HandlerSubscription is a subscription whose messages are handed to a function by
+// goroutines the Publisher manages (see SubscribeFunc).
+type HandlerSubscription[T any] struct {
+	sub *Subscription[T]
+}
+
+// Close ends the subscription. Messages already received are still handled; later
+// ones are not.
+func (s *HandlerSubscription[T]) Close() error {
+	return s.sub.Close()
+}
+
+// SubscribeFunc subscribes handler to topic: instead of handing back a channel, the
+// Publisher calls handler for every message from goroutines of its own, at most
+// WithConcurrency at a time. A handler that panics is recovered: the panic is logged
+// with its stack, and the message goes to the dead-letter topic (see WithDeadLetters)
+// with ErrHandlerPanic, so one bad message cannot take the process down.
+//
+// The subscription buffers like Subscribe (WithDefaultBuffer), and a handler slower
+// than the publishers fills it up, at which point the overflow policy applies
+// (WithOverflow). Shutdown waits for running handlers to return.
+//
+// Go Concurrency Patterns used:
+//   - Worker pool: concurrency goroutines range over the subscriber channel, which
+//     bounds the parallel handlers without a semaphore
+//   - Panic recovery: each call runs under a deferred recover, so a panic ends that
+//     message, not the worker
+//
+// Usage example:
+//
+//	sub, err := pub.SubscribeFunc("orders", func(order Order) {
+//		ship(order)
+//	}, WithConcurrency(4))
+//	if err != nil { ... }
+//	defer sub.Close()
+func (p *Publisher[T]) SubscribeFunc(topic string, handler func(msg T), opts ...SubscribeOption) (*HandlerSubscription[T], error) {
+	if err := p.authorizeSubscribe(Anonymous, topic); err != nil {
+		return nil, err
+	}
+	settings := newSubscribeConfig(opts)
+	if settings.catchUpBatch > 0 || settings.credits {
+		return nil, errNeedsLog
+	}
+
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	sub, err := p.subscribeMessagesLocked(s, topic, settings)
+	if err != nil {
+		return nil, err
+	}
+	sub.handler = handler // Set before the lock lets a publish reach sub
+	for range max(settings.concurrency, 1) {
+		p.goroutines.Go(func() { // Started under the lock, so Shutdown waits for them
+			for msg := range sub.outLog {
+				if !msg.Deleted {
+					p.handle(topic, sub, handler, msg)
+				}
+			}
+		})
+	}
+	return &HandlerSubscription[T]{sub: &Subscription[T]{pub: p, topic: topic, sub: sub}}, nil
+}
+
+// handle calls handler with msg, turning a panic into an error. The error is logged
+// and the message dead-lettered.
+func (p *Publisher[T]) handle(topic string, sub *subscriber[T], handler func(T), msg Message[T]) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+			p.metrics.panics.Add(1)
+			p.config.logger.Error("pubsub: handler panicked", "topic", msg.Topic, "subscriber", sub.id, "panic", r, "stack", string(debug.Stack()))
+			p.deadLetter(topic, sub, msg, err)
+		}
+	}()
+	handler(msg.Value)
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestSubscribeFunc tests that a handler gets the messages in order, that a panic is
+// recovered and dead-lettered, and that Close stops the handler
+func TestSubscribeFunc(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(8), WithDeadLetters())
+	dead, _ := pub.SubscribeDeadLetters()
+	pub.CreateTopic("orders")
+
+	var mu sync.Mutex
+	var handled []string
+	sub, err := pub.SubscribeFunc("orders", func(order string) {
+		if order == "boom" {
+			panic("cannot ship")
+		}
+		mu.Lock()
+		handled = append(handled, order)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("SubscribeFunc() returned error: %v", err)
+	}
+	for _, order := range []string{"o1", "boom", "o2", "o3"} {
+		pub.Publish("orders", order)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 3
+	})
+	if fmt.Sprint(handled) != "[o1 o2 o3]" {
+		t.Errorf("Expected [o1 o2 o3] in order, got %v", handled)
+	}
+	if letter := <-dead; !errors.Is(letter.Reason, ErrHandlerPanic) || letter.Message.Value != "boom" {
+		t.Errorf("Expected boom dead-lettered with ErrHandlerPanic, got %+v", letter)
+	}
+	if n := pub.metrics.panics.Load(); n != 1 {
+		t.Errorf("Expected 1 panic counted, got %d", n)
+	}
+
+	if err := sub.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+	pub.Publish("orders", "late")
+	pub.Shutdown(context.Background()) // Waits for the handler goroutine
+	if len(handled) != 3 {
+		t.Errorf("Expected no message handled after Close, got %v", handled)
+	}
+}
+
+// TestSubscribeFuncConcurrency tests that WithConcurrency bounds the handlers running
+// at once
+func TestSubscribeFuncConcurrency(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(16))
+	pub.CreateTopic("jobs")
+	release := make(chan struct{})
+	var running, peak, done atomic.Int64
+	pub.SubscribeFunc("jobs", func(int) {
+		n := running.Add(1)
+		for old := peak.Load(); n > old && !peak.CompareAndSwap(old, n); old = peak.Load() {
+		}
+		<-release
+		running.Add(-1)
+		done.Add(1)
+	}, WithConcurrency(3))
+
+	for i := range 10 {
+		pub.Publish("jobs", i)
+	}
+	waitFor(t, func() bool { return running.Load() == 3 })
+	close(release)
+	waitFor(t, func() bool { return done.Load() == 10 })
+	if n := peak.Load(); n != 3 {
+		t.Errorf("Expected at most 3 handlers at once, got %d", n)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+// WithReplay keeps the topic's last n messages in memory so SubscribeWithReplay can
+// deliver them to late joiners. Unlike SubscribeFrom it needs no store, but the history
+// is bounded and lost when the process exits. Deletes are not kept.
+func WithReplay(n int) TopicOption {
+	return func(c *topicConfig) {
+		c.replay = max(n, 0)
+	}
+}
+
+// SubscribeWithReplay subscribes to topic like Subscribe, but first delivers the
+// messages kept by WithReplay, oldest first, then the live stream. The history is read
+// under the same write lock that registers the subscriber, and publishes record into
+// the history and deliver while holding the read lock, so every message is either in
+// the replayed history or in the live stream, never both and never neither.
+//
+// On a topic created without WithReplay it behaves exactly like Subscribe.
+//
+// Parameters:
+//   - topic: string - the topic name to subscribe to
+//   - opts: ...SubscribeOption - per-subscription settings, applied to live messages
+//
+// Returns:
+//   - <-chan T: receive-only channel, usable with CloseSubscriber like Subscribe's
+//   - error: "topic not found" or ErrUnauthorized (wrapped)
+func (p *Publisher[T]) SubscribeWithReplay(topic string, opts ...SubscribeOption) (<-chan T, error) {
+	if err := p.authorizeSubscribe(Anonymous, topic); err != nil {
+		return nil, err
+	}
+	return p.subscribe(topic, true, opts)
+}
+
+// ring is a fixed-size ring buffer holding the most recent values pushed into it.
+// It is not safe for concurrent use; topicState guards it with publishMu.
+type ring[T any] struct {
+	buf   []T
+	start int // Index of the oldest value
+	n     int // Values held, up to len(buf)
+}
+
+func newRing[T any](size int) *ring[T] {
+	return &ring[T]{buf: make([]T, size)}
+}
+
+// push appends v, overwriting the oldest value when the ring is full.
+func (r *ring[T]) push(v T) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = v
+		r.n++
+		return
+	}
+	r.buf[r.start] = v
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// items returns a copy of the held values, oldest first.
+func (r *ring[T]) items() []T {
+	items := make([]T, r.n)
+	for i := range items {
+		items[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+	return items
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// TestSubscribeWithReplay tests that a late subscriber gets the last n messages and
+// then the live stream, without gaps or duplicates in between
+func TestSubscribeWithReplay(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(0))
+	topic := "prices"
+	pub.CreateTopic(topic, WithReplay(3))
+	for i := range 5 {
+		pub.Publish(topic, fmt.Sprint(i))
+	}
+
+	ch, err := pub.SubscribeWithReplay(topic)
+	if err != nil {
+		t.Fatalf("SubscribeWithReplay() returned error: %v", err)
+	}
+	go func() {
+		for i := 5; i < 8; i++ {
+			pub.Publish(topic, fmt.Sprint(i))
+		}
+	}()
+	var got []string
+	for range 6 {
+		got = append(got, <-ch)
+	}
+	if want := []string{"2", "3", "4", "5", "6", "7"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestSubscribeWithReplayWithoutHistory tests that a topic without WithReplay behaves
+// like Subscribe
+func TestSubscribeWithReplayWithoutHistory(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("prices")
+	pub.Publish("prices", "old")
+	ch, _ := pub.SubscribeWithReplay("prices")
+	pub.Publish("prices", "new")
+	if msg := <-ch; msg != "new" {
+		t.Errorf("Expected only live messages, got %q", msg)
+	}
+}
+
+// TestRing tests wrap-around and ordering of the history ring buffer
+func TestRing(t *testing.T) {
+	r := newRing[int](3)
+	if items := r.items(); len(items) != 0 {
+		t.Errorf("Expected an empty ring, got %v", items)
+	}
+	for i := range 7 {
+		r.push(i)
+	}
+	if items := r.items(); !slices.Equal(items, []int{4, 5, 6}) {
+		t.Errorf("Expected [4 5 6], got %v", items)
+	}
+}
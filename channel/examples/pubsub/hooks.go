@@ -0,0 +1,104 @@
+package main
+
+import "slices"
+
+// lifecycleHooks are the callbacks registered with OnTopicCreated, OnTopicClosed,
+// OnSubscribe and OnUnsubscribe. Like routeTable it is never modified once installed
+// in Publisher.hooks; the pointers identify callbacks for removal.
+type lifecycleHooks struct {
+	created      []*func(topic string)
+	closed       []*func(topic string)
+	subscribed   []*func(topic string, subscriber uint64)
+	unsubscribed []*func(topic string, subscriber uint64)
+}
+
+// OnTopicCreated registers fn to be called whenever a topic is created, re-creations
+// by CreateTopic included. Lifecycle callbacks let applications keep derived state
+// (metrics, ACL caches, dashboards) in step with the Publisher instead of polling it.
+//
+// Lifecycle callbacks run synchronously, in the order of the events of a topic, with
+// the topic's shard locked: they must be fast and must not call back into the
+// Publisher. Topic names are the ones given to the Publisher (or Namespace) the
+// callback is registered on.
+//
+// Returns:
+//   - remove: func() - unregisters fn; calling it again does nothing
+func (p *Publisher[T]) OnTopicCreated(fn func(topic string)) (remove func()) {
+	return registerHook(p, func(h *lifecycleHooks) *[]*func(string) { return &h.created }, &fn)
+}
+
+// OnTopicClosed registers fn to be called whenever a topic is closed (CloseTopic or
+// Shutdown), after OnUnsubscribe was called for each of its subscribers. See
+// OnTopicCreated for when callbacks run.
+func (p *Publisher[T]) OnTopicClosed(fn func(topic string)) (remove func()) {
+	return registerHook(p, func(h *lifecycleHooks) *[]*func(string) { return &h.closed }, &fn)
+}
+
+// OnSubscribe registers fn to be called whenever a subscriber joins a topic, with the
+// subscriber's id as in SubscriberStats. See OnTopicCreated for when callbacks run.
+func (p *Publisher[T]) OnSubscribe(fn func(topic string, subscriber uint64)) (remove func()) {
+	return registerHook(p, func(h *lifecycleHooks) *[]*func(string, uint64) { return &h.subscribed }, &fn)
+}
+
+// OnUnsubscribe registers fn to be called whenever a subscriber leaves a topic: closed
+// by its owner, evicted (WithSlowSubscriberEviction), dropped by CreateTopic, or
+// removed with its topic. See OnTopicCreated for when callbacks run.
+func (p *Publisher[T]) OnUnsubscribe(fn func(topic string, subscriber uint64)) (remove func()) {
+	return registerHook(p, func(h *lifecycleHooks) *[]*func(string, uint64) { return &h.unsubscribed }, &fn)
+}
+
+// registerHook installs a copy of the hooks with fn appended to the list picked by
+// list, and returns the function that removes it again.
+func registerHook[T, F any](p *Publisher[T], list func(*lifecycleHooks) *[]*F, fn *F) (remove func()) {
+	update := func(change func([]*F) []*F) {
+		p.Lock()
+		defer p.Unlock()
+		var hooks lifecycleHooks
+		if old := p.hooks.Load(); old != nil {
+			hooks = *old
+		}
+		l := list(&hooks)
+		*l = change(slices.Clone(*l))
+		p.hooks.Store(&hooks)
+	}
+	update(func(l []*F) []*F { return append(l, fn) })
+	return func() {
+		update(func(l []*F) []*F { return slices.DeleteFunc(l, func(other *F) bool { return other == fn }) })
+	}
+}
+
+// topicCreatedLocked runs the OnTopicCreated callbacks. Called with topic's shard locked.
+func (p *Publisher[T]) topicCreatedLocked(topic string) {
+	if hooks := p.hooks.Load(); hooks != nil {
+		for _, fn := range hooks.created {
+			(*fn)(topic)
+		}
+	}
+}
+
+// topicClosedLocked runs the OnTopicClosed callbacks. Called with topic's shard locked.
+func (p *Publisher[T]) topicClosedLocked(topic string) {
+	if hooks := p.hooks.Load(); hooks != nil {
+		for _, fn := range hooks.closed {
+			(*fn)(topic)
+		}
+	}
+}
+
+// subscribedLocked runs the OnSubscribe callbacks. Called with topic's shard locked.
+func (p *Publisher[T]) subscribedLocked(topic string, sub *subscriber[T]) {
+	if hooks := p.hooks.Load(); hooks != nil {
+		for _, fn := range hooks.subscribed {
+			(*fn)(topic, sub.id)
+		}
+	}
+}
+
+// unsubscribedLocked runs the OnUnsubscribe callbacks. Called with topic's shard locked.
+func (p *Publisher[T]) unsubscribedLocked(topic string, sub *subscriber[T]) {
+	if hooks := p.hooks.Load(); hooks != nil {
+		for _, fn := range hooks.unsubscribed {
+			(*fn)(topic, sub.id)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// TestLifecycleHooks tests that the lifecycle callbacks see topics and subscribers come
+// and go, in order, and stop once removed
+func TestLifecycleHooks(t *testing.T) {
+	pub := NewPublisher[string]()
+	var events []string
+	removes := []func(){
+		pub.OnTopicCreated(func(topic string) { events = append(events, "created "+topic) }),
+		pub.OnTopicClosed(func(topic string) { events = append(events, "closed "+topic) }),
+		pub.OnSubscribe(func(topic string, id uint64) { events = append(events, fmt.Sprintf("subscribe %s %d", topic, id)) }),
+		pub.OnUnsubscribe(func(topic string, id uint64) { events = append(events, fmt.Sprintf("unsubscribe %s %d", topic, id)) }),
+	}
+
+	pub.CreateTopic("news")
+	ch, _ := pub.Subscribe("news")
+	pub.CloseSubscriber("news", ch)
+	sub, _ := pub.SubscribeMessages("news")
+	pub.CloseTopic("news")
+	sub.Close() // Already removed with the topic: no second event
+
+	want := []string{
+		"created news",
+		"subscribe news 1",
+		"unsubscribe news 1",
+		"subscribe news 2",
+		"unsubscribe news 2",
+		"closed news",
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("Expected events %q, got %q", want, events)
+	}
+
+	for _, remove := range removes {
+		remove()
+		remove() // Removing twice does nothing
+	}
+	pub.CreateTopic("sports")
+	pub.Subscribe("sports")
+	pub.CloseTopic("sports")
+	if len(events) != len(want) {
+		t.Errorf("Expected no events after removal, got %q", events[len(want):])
+	}
+}
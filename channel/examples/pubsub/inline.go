@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// inlineKey is the context key of a PublishSync in progress.
+type inlineKey struct{}
+
+// inlineDelivery collects what a PublishSync has to do once the topic's lock is
+// released: the handler subscribers to call, and the errors they returned.
+type inlineDelivery[T any] struct {
+	handlers []*subscriber[T]
+	errs     []error
+}
+
+// PublishSync publishes message and returns once every subscriber has it: handler
+// subscribers (SubscribeFunc) are called on the publishing goroutine instead of being
+// queued, so when PublishSync returns nil every handler has run to completion. Failed
+// handlers (a panic, see ErrHandlerPanic) are joined into the returned error together
+// with the broadcast's own error, if any; use errors.Is to inspect them. Messages routed
+// to other topics (AddRoute) are delivered the same way, and their handler failures
+// are reported too.
+//
+// Inline calls bypass the subscription's goroutines, so they do not count against
+// WithConcurrency and may run alongside messages those goroutines are handling. With
+// WithAsyncDelivery the message skips the delivery queue and may overtake messages
+// still queued for the topic.
+//
+// Usage example:
+//
+//	if err := pub.PublishSync("payments", payment); err != nil {
+//		return fmt.Errorf("payment not processed: %w", err)
+//	}
+func (p *Publisher[T]) PublishSync(topic string, message T) error {
+	if err := p.authorizePublish(Anonymous, topic); err != nil {
+		return err
+	}
+	inline := &inlineDelivery[T]{}
+	err := p.publish(context.WithValue(context.Background(), inlineKey{}, inline), topic, Message[T]{Value: message})
+	return errors.Join(append([]error{err}, inline.errs...)...)
+}
+
+// inlineFrom returns the PublishSync in progress on ctx, or nil.
+func inlineFrom[T any](ctx context.Context) *inlineDelivery[T] {
+	inline, _ := ctx.Value(inlineKey{}).(*inlineDelivery[T])
+	return inline
+}
+
+// run calls the collected handlers with msg. It must be called without the topic's
+// shard lock held, since handlers may publish.
+func (d *inlineDelivery[T]) run(p *Publisher[T], topic string, msg Message[T]) {
+	if d == nil {
+		return
+	}
+	handlers := d.handlers
+	d.handlers = nil // Routed publishes collect their own
+	for _, sub := range handlers {
+		if err := p.handle(topic, sub, sub.handler, msg); err != nil {
+			d.errs = append(d.errs, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestPublishSync tests that handler subscribers run before PublishSync returns, on
+// routed topics too, and that their failures are joined into its error
+func TestPublishSync(t *testing.T) {
+	pub := NewPublisher[string](WithAsyncDelivery(1, 4))
+	defer pub.Shutdown(t.Context())
+	pub.CreateTopic("payments")
+	pub.CreateTopic("audit")
+	pub.AddRoute("payments", nil, "audit")
+
+	var handled []string // Only written by the publishing goroutine
+	pub.SubscribeFunc("payments", func(p string) { handled = append(handled, "charge "+p) })
+	pub.SubscribeFunc("audit", func(p string) {
+		if p == "bad" {
+			panic("audit log unavailable")
+		}
+		handled = append(handled, "audit "+p)
+	})
+
+	if err := pub.PublishSync("payments", "p1"); err != nil {
+		t.Fatalf("PublishSync() returned error: %v", err)
+	}
+	if len(handled) != 2 || handled[0] != "charge p1" || handled[1] != "audit p1" {
+		t.Errorf("Expected both handlers to have run, got %v", handled)
+	}
+
+	err := pub.PublishSync("payments", "bad")
+	if !errors.Is(err, ErrHandlerPanic) {
+		t.Errorf("Expected the audit handler's panic in the error, got %v", err)
+	}
+	if len(handled) != 3 || handled[2] != "charge bad" {
+		t.Errorf("Expected the other handler to run regardless, got %v", handled)
+	}
+	if err := pub.PublishSync("missing", "p2"); err == nil {
+		t.Error("Expected an unknown topic to be reported")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// KeyedLimiter keeps an independent token bucket per key, so one noisy key (here: one
+// subscriber) cannot use up another key's allowance.
+//
+// Go Concurrency Patterns used:
+//   - Mutex-protected map: buckets are created, consulted and removed from many goroutines
+//   - Lazy refill: tokens are computed from elapsed time on each Allow, no ticker goroutine
+type KeyedLimiter[K comparable] struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	buckets map[K]*tokenBucket
+}
+
+// tokenBucket refills at rate tokens per second up to burst tokens.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewKeyedLimiter returns an empty limiter that measures time with c.
+func NewKeyedLimiter[K comparable](c clock.Clock) *KeyedLimiter[K] {
+	return &KeyedLimiter[K]{
+		clock:   c,
+		buckets: make(map[K]*tokenBucket),
+	}
+}
+
+// Set installs (or replaces) the bucket for key, starting full.
+//
+// Parameters:
+//   - key: K - bucket identifier
+//   - perSecond: float64 - sustained rate
+//   - burst: int - bucket size, at least 1
+func (l *KeyedLimiter[K]) Set(key K, perSecond float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := float64(max(burst, 1))
+	l.buckets[key] = &tokenBucket{rate: perSecond, burst: b, tokens: b, last: l.clock.Now()}
+}
+
+// Allow takes one token from key's bucket and reports whether one was available.
+// Keys without a bucket are unlimited.
+func (l *KeyedLimiter[K]) Allow(key K) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		return true
+	}
+
+	now := l.clock.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Remove forgets key's bucket.
+func (l *KeyedLimiter[K]) Remove(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, key)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// drain returns the messages currently buffered in ch without blocking
+func drain(ch <-chan string) []string {
+	var msgs []string
+	for {
+		select {
+		case msg := <-ch:
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+// TestWithRateLimit tests that a subscriber's quota skips excess messages without affecting others
+func TestWithRateLimit(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	pub := NewPublisher[string](WithClock(fake), WithDefaultBuffer(10))
+	topic := "test-topic"
+	pub.CreateTopic(topic)
+
+	limited, err := pub.Subscribe(topic, WithRateLimit(2, 2))
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	unlimited, err := pub.Subscribe(topic)
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+
+	for range 5 {
+		if err := pub.Publish(topic, "burst"); err != nil {
+			t.Fatalf("Publish() returned error: %v", err)
+		}
+	}
+	if got := len(drain(limited)); got != 2 {
+		t.Errorf("Expected limited subscriber to get its burst of 2, got %d", got)
+	}
+	if got := len(drain(unlimited)); got != 5 {
+		t.Errorf("Expected unlimited subscriber to get all 5, got %d", got)
+	}
+
+	// One second refills two tokens
+	fake.Advance(time.Second)
+	for range 3 {
+		pub.Publish(topic, "refill")
+	}
+	if got := len(drain(limited)); got != 2 {
+		t.Errorf("Expected 2 messages after refill, got %d", got)
+	}
+	if got := pub.metrics.rateLimited.Load(); got != 4 {
+		t.Errorf("Expected 4 rate-limited deliveries, got %d", got)
+	}
+}
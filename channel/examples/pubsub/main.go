@@ -30,6 +30,15 @@ import (
 //  4. Wait for all goroutines to complete using WaitGroup
 //  5. Gracefully close all topics, which closes subscriber channels
 func main() {
+	// Optionally record a runtime trace (TRACE=trace.out go run .)
+	// Publishes and deliveries appear as named tasks/regions in `go tool trace`
+	stopTrace, err := startTraceFromEnv()
+	if err != nil {
+		fmt.Printf("Error starting trace: %v\n", err)
+		return
+	}
+	defer stopTrace()
+
 	// Define all topics and their configuration in one place
 	// This centralizes configuration and makes it easy to add/modify topics
 	topicConfig := map[string]struct {
@@ -68,7 +77,7 @@ func main() {
 
 	// Create a new Publisher instance
 	// Publisher uses RWMutex internally for thread-safe operations
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 
 	// Create all topics before starting publishers/subscribers
 	// Topics must exist before subscribers can subscribe or publishers can publish
@@ -1,46 +1,53 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
+
+	"goconcurrency/testkit"
 )
 
 // TestNewPublisher tests the creation of a new Publisher instance
 func TestNewPublisher(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	if pub == nil {
 		t.Fatal("NewPublisher() returned nil")
 	}
-	if pub.subscribers == nil {
-		t.Fatal("NewPublisher() subscribers map is nil")
-	}
-	if len(pub.subscribers) != 0 {
-		t.Errorf("Expected empty subscribers map, got %d topics", len(pub.subscribers))
+	for i := range pub.shards {
+		if pub.shards[i].subscribers == nil {
+			t.Fatal("NewPublisher() subscribers map is nil")
+		}
+		if len(pub.shards[i].subscribers) != 0 {
+			t.Errorf("Expected empty subscribers map, got %d topics", len(pub.shards[i].subscribers))
+		}
 	}
 }
 
 // TestCreateTopic tests topic creation
 func TestCreateTopic(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 
 	pub.CreateTopic(topic)
 
-	pub.RLock()
-	defer pub.RUnlock()
+	s := pub.shard(topic)
+	s.RLock()
+	defer s.RUnlock()
 
-	if _, ok := pub.subscribers[topic]; !ok {
+	if _, ok := s.subscribers[topic]; !ok {
 		t.Fatal("Topic was not created")
 	}
-	if len(pub.subscribers[topic]) != 0 {
-		t.Errorf("Expected empty subscriber list, got %d subscribers", len(pub.subscribers[topic]))
+	if len(s.subscribers[topic]) != 0 {
+		t.Errorf("Expected empty subscriber list, got %d subscribers", len(s.subscribers[topic]))
 	}
 }
 
 // TestSubscribe tests subscribing to a topic
 func TestSubscribe(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -52,16 +59,17 @@ func TestSubscribe(t *testing.T) {
 		t.Fatal("Subscribe() returned nil channel")
 	}
 
-	pub.RLock()
-	if len(pub.subscribers[topic]) != 1 {
-		t.Errorf("Expected 1 subscriber, got %d", len(pub.subscribers[topic]))
+	s := pub.shard(topic)
+	s.RLock()
+	if len(s.subscribers[topic]) != 1 {
+		t.Errorf("Expected 1 subscriber, got %d", len(s.subscribers[topic]))
 	}
-	pub.RUnlock()
+	s.RUnlock()
 }
 
 // TestSubscribeNonExistentTopic tests subscribing to a non-existent topic
 func TestSubscribeNonExistentTopic(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 
 	ch, err := pub.Subscribe("non-existent")
 	if err == nil {
@@ -74,7 +82,7 @@ func TestSubscribeNonExistentTopic(t *testing.T) {
 
 // TestPublish tests publishing messages to subscribers
 func TestPublish(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -104,7 +112,7 @@ func TestPublish(t *testing.T) {
 
 // TestPublishMultipleSubscribers tests broadcasting to multiple subscribers
 func TestPublishMultipleSubscribers(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -140,7 +148,7 @@ func TestPublishMultipleSubscribers(t *testing.T) {
 
 // TestPublishNonExistentTopic tests publishing to a non-existent topic
 func TestPublishNonExistentTopic(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 
 	err := pub.Publish("non-existent", "message")
 	if err == nil {
@@ -150,7 +158,7 @@ func TestPublishNonExistentTopic(t *testing.T) {
 
 // TestCloseTopic tests closing a topic
 func TestCloseTopic(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -177,16 +185,17 @@ func TestCloseTopic(t *testing.T) {
 	}
 
 	// Topic should be removed
-	pub.RLock()
-	if _, ok := pub.subscribers[topic]; ok {
+	s := pub.shard(topic)
+	s.RLock()
+	if _, ok := s.subscribers[topic]; ok {
 		t.Error("Topic should be removed after CloseTopic()")
 	}
-	pub.RUnlock()
+	s.RUnlock()
 }
 
 // TestCloseTopicNonExistent tests closing a non-existent topic
 func TestCloseTopicNonExistent(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 
 	err := pub.CloseTopic("non-existent")
 	if err == nil {
@@ -196,7 +205,7 @@ func TestCloseTopicNonExistent(t *testing.T) {
 
 // TestCloseSubscriber tests closing a specific subscriber
 func TestCloseSubscriber(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -223,16 +232,17 @@ func TestCloseSubscriber(t *testing.T) {
 	}
 
 	// Subscriber should be removed from topic
-	pub.RLock()
-	if len(pub.subscribers[topic]) != 0 {
-		t.Errorf("Expected 0 subscribers, got %d", len(pub.subscribers[topic]))
+	s := pub.shard(topic)
+	s.RLock()
+	if len(s.subscribers[topic]) != 0 {
+		t.Errorf("Expected 0 subscribers, got %d", len(s.subscribers[topic]))
 	}
-	pub.RUnlock()
+	s.RUnlock()
 }
 
 // TestCloseSubscriberNonExistentTopic tests closing subscriber from non-existent topic
 func TestCloseSubscriberNonExistentTopic(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	ch := make(<-chan string)
 
 	err := pub.CloseSubscriber("non-existent", ch)
@@ -243,7 +253,7 @@ func TestCloseSubscriberNonExistentTopic(t *testing.T) {
 
 // TestCloseSubscriberNonExistentSubscriber tests closing non-existent subscriber
 func TestCloseSubscriberNonExistentSubscriber(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -257,7 +267,7 @@ func TestCloseSubscriberNonExistentSubscriber(t *testing.T) {
 
 // TestConcurrentPublish tests concurrent publishing to the same topic
 func TestConcurrentPublish(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -303,7 +313,7 @@ func TestConcurrentPublish(t *testing.T) {
 
 // TestConcurrentSubscribe tests concurrent subscriptions
 func TestConcurrentSubscribe(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -328,11 +338,12 @@ func TestConcurrentSubscribe(t *testing.T) {
 	wg.Wait()
 
 	// Verify all subscribers were added
-	pub.RLock()
-	if len(pub.subscribers[topic]) != numSubscribers {
-		t.Errorf("Expected %d subscribers, got %d", numSubscribers, len(pub.subscribers[topic]))
+	s := pub.shard(topic)
+	s.RLock()
+	if len(s.subscribers[topic]) != numSubscribers {
+		t.Errorf("Expected %d subscribers, got %d", numSubscribers, len(s.subscribers[topic]))
 	}
-	pub.RUnlock()
+	s.RUnlock()
 
 	// Publish a message and verify all subscribers receive it
 	message := "broadcast to all"
@@ -355,7 +366,7 @@ func TestConcurrentSubscribe(t *testing.T) {
 
 // TestMultipleTopics tests operations with multiple topics
 func TestMultipleTopics(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topics := []string{"topic1", "topic2", "topic3"}
 
 	// Create topics
@@ -398,7 +409,7 @@ func TestMultipleTopics(t *testing.T) {
 
 // TestPublishAfterClose tests that publishing after closing a topic returns an error
 func TestPublishAfterClose(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -417,7 +428,7 @@ func TestPublishAfterClose(t *testing.T) {
 
 // TestSubscribeAfterClose tests that subscribing after closing a topic returns an error
 func TestSubscribeAfterClose(t *testing.T) {
-	pub := NewPublisher()
+	pub := NewPublisher[string]()
 	topic := "test-topic"
 	pub.CreateTopic(topic)
 
@@ -439,41 +450,144 @@ func TestSubscribeAfterClose(t *testing.T) {
 
 // TestBufferedChannel tests that subscriber channels are buffered
 func TestBufferedChannel(t *testing.T) {
-	pub := NewPublisher()
-	topic := "test-topic"
+	testkit.Run(t, func(t *testing.T, s *testkit.Scheduler) {
+		pub := NewPublisher[string]()
+		topic := "test-topic"
+		pub.CreateTopic(topic)
+
+		ch, err := pub.Subscribe(topic)
+		if err != nil {
+			t.Fatalf("Subscribe() returned error: %v", err)
+		}
+
+		// The channel is buffered (capacity 1): the first message is buffered
+		err = pub.Publish(topic, "message1")
+		if err != nil {
+			t.Fatalf("Publish() returned error: %v", err)
+		}
+
+		// The second one blocks until the subscriber makes room
+		s.Go(func() {
+			if err := pub.Publish(topic, "message2"); err != nil {
+				t.Errorf("Publish() returned error: %v", err)
+			}
+		})
+		s.Settle()
+		if len(ch) != 1 {
+			t.Errorf("Expected 1 buffered message while the second publish blocks, got %d", len(ch))
+		}
+
+		// Receive messages
+		msg1 := <-ch
+		msg2 := <-ch
+		s.Wait()
+
+		if msg1 != "message1" {
+			t.Errorf("Expected first message 'message1', got '%s'", msg1)
+		}
+		if msg2 != "message2" {
+			t.Errorf("Expected second message 'message2', got '%s'", msg2)
+		}
+	})
+}
+
+// TestStructPayload tests a Publisher carrying a struct type, delivered live and
+// replayed from the store through the codec
+func TestStructPayload(t *testing.T) {
+	type orderPlaced struct {
+		ID    int
+		Total float64
+	}
+	pub := NewPublisher[orderPlaced](WithStore(NewMemoryStore()), WithDefaultBuffer(10))
+	topic := "orders"
 	pub.CreateTopic(topic)
+	live, _ := pub.Subscribe(topic)
 
-	ch, err := pub.Subscribe(topic)
-	if err != nil {
-		t.Fatalf("Subscribe() returned error: %v", err)
+	event := orderPlaced{ID: 7, Total: 19.5}
+	if err := pub.Publish(topic, event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if got := <-live; got != event {
+		t.Errorf("Expected %+v live, got %+v", event, got)
 	}
 
-	// Publish two messages quickly
-	// If channel is buffered (capacity 1), first message should be buffered
-	// and second should block or be buffered if there's space
-	err = pub.Publish(topic, "message1")
+	replayed, err := pub.SubscribeFrom(topic, 0)
 	if err != nil {
-		t.Fatalf("Publish() returned error: %v", err)
+		t.Fatalf("SubscribeFrom failed: %v", err)
 	}
+	if got := <-replayed; got != event {
+		t.Errorf("Expected %+v replayed, got %+v", event, got)
+	}
+}
 
-	// Small delay to ensure first message is sent
-	time.Sleep(10 * time.Millisecond)
+// TestPublishContext tests that a canceled publish stops at a full subscriber and
+// reports it and the subscribers after it as skipped
+func TestPublishContext(t *testing.T) {
+	pub := NewPublisher[string](WithStore(NewMemoryStore()), WithDefaultBuffer(1))
+	topic := "quotes"
+	pub.CreateTopic(topic)
+	first, _ := pub.Subscribe(topic)
+	slow, _ := pub.Subscribe(topic)
+	logSub, _ := pub.SubscribeLog(topic, 0)
+	pub.Publish(topic, "q0")
+	<-first
 
-	// Publish second message
-	err = pub.Publish(topic, "message2")
-	if err != nil {
-		t.Fatalf("Publish() returned error: %v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := pub.PublishContext(ctx, topic, "q1")
+	var canceled *PublishCanceledError[string]
+	if !errors.As(err, &canceled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a PublishCanceledError for DeadlineExceeded, got %v", err)
+	}
+	if len(canceled.Skipped) != 1 || canceled.Skipped[0] != slow {
+		t.Errorf("Expected the slow subscriber to be skipped, got %v", canceled.Skipped)
+	}
+	if len(canceled.SkippedLogs) != 1 || canceled.SkippedLogs[0] != logSub.C {
+		t.Errorf("Expected the log subscriber to be skipped, got %v", canceled.SkippedLogs)
+	}
+	if msg := <-first; msg != "q1" {
+		t.Errorf("Expected the first subscriber to get q1, got %q", msg)
 	}
 
-	// Receive messages
-	msg1 := <-ch
-	msg2 := <-ch
-
-	if msg1 != "message1" {
-		t.Errorf("Expected first message 'message1', got '%s'", msg1)
+	if err := pub.PublishContext(ctx, topic, "q2"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded for a done ctx, got %v", err)
 	}
-	if msg2 != "message2" {
-		t.Errorf("Expected second message 'message2', got '%s'", msg2)
+	select {
+	case msg := <-first:
+		t.Errorf("Expected nothing published with a done ctx, got %q", msg)
+	default:
 	}
 }
 
+// TestRetain tests that new subscribers of a retained topic start with the last
+// message, and that a delete clears it
+func TestRetain(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(0))
+	topic := "config"
+	pub.CreateTopic(topic, WithRetain())
+
+	early, _ := pub.Subscribe(topic)
+	go func() {
+		for range early {
+		}
+	}()
+	pub.Publish(topic, "v1")
+	pub.Publish(topic, "v2")
+
+	late, _ := pub.Subscribe(topic)
+	go pub.Publish(topic, "v3")
+	for _, want := range []string{"v2", "v3"} {
+		if got := <-late; got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+
+	pub.DeleteKey(topic, "")
+	fresh, _ := pub.Subscribe(topic)
+	select {
+	case msg := <-fresh:
+		t.Errorf("Expected no retained message after a delete, got %q", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+	pub.CloseTopic(topic)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+// sharedChannel counts the subscribers of a SubscribeMany subscription, which all
+// deliver into one channel: the last one removed closes it.
+type sharedChannel struct {
+	members atomic.Int32
+}
+
+// SubscribeMany subscribes to several topics at once and delivers their messages on a
+// single channel, Subscription.C, so consumers of several topics need no fan-in
+// goroutines of their own. Every Message carries the topic it was published to in
+// Message.Topic; messages of one topic arrive in publish order, messages of different
+// topics interleave as they are published. A retained message (WithRetain) of each
+// topic is delivered first if the buffer has room for it.
+//
+// The subscription is all or nothing: if one topic does not exist, or is not
+// authorized, none is subscribed. The topics share one channel of WithDefaultBuffer
+// capacity. C is closed once every topic has been closed (CloseTopic) or by Close.
+//
+// Go Concurrency Patterns used:
+//   - Shared channel fan-in: each topic's subscriber sends into the same channel, so
+//     merging needs no forwarding goroutines; a reference count closes it when the
+//     last topic lets go of it
+//   - Ordered lock acquisition: the shards of all topics are locked in index order,
+//     so concurrent SubscribeMany calls cannot deadlock
+//
+// Usage example:
+//
+//	sub, err := pub.SubscribeMany("orders", "payments", "refunds")
+//	if err != nil { ... }
+//	defer sub.Close()
+//	for msg := range sub.C {
+//		fmt.Println(msg.Topic, msg.Value)
+//	}
+func (p *Publisher[T]) SubscribeMany(topics ...string) (*Subscription[T], error) {
+	if len(topics) == 0 {
+		return nil, errors.New("no topics to subscribe to")
+	}
+	for i, topic := range topics {
+		topics[i] = p.resolve(topic)
+	}
+	topics = slices.Compact(slices.Sorted(slices.Values(topics)))
+	for _, topic := range topics {
+		if err := p.authorizeSubscribe(Anonymous, topic); err != nil {
+			return nil, err
+		}
+	}
+	settings := newSubscribeConfig(nil)
+
+	unlock := p.lockShards(topics)
+	defer unlock()
+	for _, topic := range topics {
+		if _, ok := p.shard(topic).topics[topic]; !ok {
+			return nil, fmt.Errorf("topic not found: %q", topic)
+		}
+	}
+
+	ch := make(chan Message[T], p.config.buffer)
+	shared := &sharedChannel{}
+	merged := &Subscription[T]{C: ch, pub: p}
+	for i, topic := range topics {
+		s := p.shard(topic)
+		sub := &subscriber[T]{msgs: ch, outLog: ch, shared: shared}
+		if i == 0 {
+			sub.buffered = p.config.buffer // The channel counts against the namespace once
+		}
+		if retained := s.topics[topic].retained; retained != nil && len(ch) < cap(ch) {
+			if _, ok := p.timeLeft(*retained); ok {
+				ch <- *retained
+			}
+		}
+		shared.members.Add(1)
+		if err := p.addSubscriberLocked(s, topic, sub, settings); err != nil {
+			shared.members.Add(-1)
+			for _, part := range merged.parts { // Undo the topics subscribed so far
+				p.dropSubscriberLocked(p.shard(part.topic), part.topic, part.sub)
+			}
+			return nil, err
+		}
+		merged.parts = append(merged.parts, &Subscription[T]{C: ch, pub: p, topic: topic, sub: sub})
+	}
+	return merged, nil
+}
+
+// lockShards write-locks the shards of topics, each once and in index order, and
+// returns the function that unlocks them.
+func (p *Publisher[T]) lockShards(topics []string) (unlock func()) {
+	var indexes []int
+	for _, topic := range topics {
+		indexes = append(indexes, p.shardIndex(topic))
+	}
+	indexes = slices.Compact(slices.Sorted(slices.Values(indexes)))
+	for _, i := range indexes {
+		p.shards[i].Lock()
+	}
+	return func() {
+		for _, i := range indexes {
+			p.shards[i].Unlock()
+		}
+	}
+}
+
+// dropSubscriberLocked removes sub from topic and stops delivery to it. It reports
+// whether sub was still subscribed. Called with the write lock of topic's shard s held.
+func (p *Publisher[T]) dropSubscriberLocked(s *topicShard[T], topic string, sub *subscriber[T]) bool {
+	subs := s.subscribers[topic]
+	i := slices.Index(subs, sub)
+	if i < 0 {
+		return false
+	}
+	p.removeSubscriberLocked(topic, sub)
+	s.subscribers[topic] = slices.Delete(subs, i, i+1)
+	return true
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestSubscribeMany tests that messages of several topics arrive on one channel with
+// their topic, and that the channel closes with the last topic
+func TestSubscribeMany(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(8), WithTopicShards(4))
+	for _, topic := range []string{"orders", "payments", "refunds"} {
+		pub.CreateTopic(topic)
+	}
+	if _, err := pub.SubscribeMany("orders", "missing"); err == nil {
+		t.Error("Expected an error when one topic does not exist")
+	}
+	if n := pub.Stats()["orders"].Subscribers; n != 0 {
+		t.Errorf("Expected a failed SubscribeMany to subscribe nothing, got %d subscribers", n)
+	}
+
+	sub, err := pub.SubscribeMany("orders", "payments", "refunds", "orders")
+	if err != nil {
+		t.Fatalf("SubscribeMany() returned error: %v", err)
+	}
+	pub.Publish("orders", "o1")
+	pub.Publish("payments", "p1")
+	pub.Publish("orders", "o2")
+	for _, want := range []Message[string]{{Topic: "orders", Value: "o1"}, {Topic: "payments", Value: "p1"}, {Topic: "orders", Value: "o2"}} {
+		if msg := <-sub.C; msg.Topic != want.Topic || msg.Value != want.Value {
+			t.Errorf("Expected %s on %s, got %s on %s", want.Value, want.Topic, msg.Value, msg.Topic)
+		}
+	}
+
+	pub.CloseTopic("orders")
+	pub.Publish("refunds", "r1")
+	if msg, ok := <-sub.C; !ok || msg.Value != "r1" {
+		t.Errorf("Expected the other topics to keep delivering, got %+v (open %v)", msg, ok)
+	}
+	if err := sub.Close(); err != nil {
+		t.Errorf("Close() returned error: %v", err)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Error("Expected C to be closed")
+	}
+	if err := sub.Close(); err == nil {
+		t.Error("Expected closing twice to fail")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"time"
+)
+
+// Message is the envelope every published message travels in. Log subscribers
+// (SubscribeLog), envelope subscribers (SubscribeMessages) and acked subscribers
+// (SubscribeAcked) receive it whole; Subscribe hands out only Value.
+//
+// Topic, ID and Time are filled in by the Publisher; Offset only with a store. Headers
+// and IDs are not stored, so messages replayed from the store carry neither.
+type Message[T any] struct {
+	Offset  uint64            // Position in the topic's stored log
+	ID      string            // Unique ID assigned by Publish, or the one given to PublishWithID
+	Topic   string            // Topic the message was published to, with its namespace path
+	Time    time.Time         // When the message was published
+	Key     string            // Message key, empty unless published with PublishKeyed
+	Headers map[string]string // Application metadata (PublishMessage), nil if none
+	Value   T                 // Message content, the zero value for deletes
+	Deleted bool              // Tombstone published by DeleteKey
+	Expires time.Time         // Delivery deadline (WithTTL, PublishWithTTL), zero if none
+}
+
+// PublishMessage publishes a prepared envelope: msg's Value, Key, ID, Headers and
+// Expires are kept (an empty ID is assigned one), while Topic, Time and Offset are set
+// by the Publisher. Headers are copied, so the caller may reuse the map; subscribers
+// share the copy and must not modify it.
+//
+// Usage example:
+//
+//	pub.PublishMessage("orders", Message[Order]{
+//		Key:     order.ID,
+//		Headers: map[string]string{"content-type": "application/json"},
+//		Value:   order,
+//	})
+func (p *Publisher[T]) PublishMessage(topic string, msg Message[T]) error {
+	if err := p.authorizePublish(Anonymous, topic); err != nil {
+		return err
+	}
+	msg.Offset, msg.Deleted = 0, false
+	msg.Headers = maps.Clone(msg.Headers)
+	return p.publish(context.Background(), topic, msg)
+}
+
+// SubscribeMessages subscribes to topic like Subscribe, but delivers whole envelopes,
+// so the subscriber sees IDs, keys, headers, publish times and delete tombstones. It
+// needs no store; a retained message (WithRetain) is delivered first, as with Subscribe.
+// End the subscription with Subscription.Close.
+func (p *Publisher[T]) SubscribeMessages(topic string, opts ...SubscribeOption) (*Subscription[T], error) {
+	if err := p.authorizeSubscribe(Anonymous, topic); err != nil {
+		return nil, err
+	}
+	settings := newSubscribeConfig(opts)
+	if settings.catchUpBatch > 0 || settings.credits {
+		return nil, errNeedsLog
+	}
+
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	sub, err := p.subscribeMessagesLocked(s, topic, settings)
+	if err != nil {
+		return nil, err
+	}
+	return &Subscription[T]{C: sub.outLog, pub: p, topic: topic, sub: sub}, nil
+}
+
+// subscribeMessagesLocked registers an envelope subscriber of topic, which receives
+// the topic's snapshot (SetSnapshotProvider) or retained message first, if any. Must be called with the write lock of
+// topic's shard s held.
+func (p *Publisher[T]) subscribeMessagesLocked(s *topicShard[T], topic string, settings subscribeConfig) (*subscriber[T], error) {
+	state, ok := s.topics[topic]
+	if !ok {
+		return nil, errors.New("topic not found")
+	}
+	var backlog []Message[T]
+	if state.snapshot != nil {
+		backlog = p.snapshotMessagesLocked(topic, state)
+	} else if state.retained != nil {
+		if _, ok := p.timeLeft(*state.retained); ok {
+			backlog = []Message[T]{*state.retained}
+		}
+	}
+	live := make(chan Message[T], p.config.buffer)
+	sub := &subscriber[T]{msgs: live, outLog: live, buffered: p.config.buffer}
+	if len(backlog) > 0 {
+		out := make(chan Message[T], p.config.buffer)
+		sub.outLog, sub.done, sub.buffered = out, make(chan struct{}), 2*p.config.buffer
+		p.goroutines.Go(func() { pump(backlog, live, out, sub.done, nil) })
+	}
+	if err := p.addSubscriberLocked(s, topic, sub, settings); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// stamp fills in the envelope fields the Publisher owns before msg is published.
+func (p *Publisher[T]) stamp(topic string, msg *Message[T]) {
+	msg.Topic = p.qualified(topic)
+	msg.Time = p.config.clock.Now()
+	if msg.ID == "" {
+		msg.ID = p.newMessageID()
+	}
+}
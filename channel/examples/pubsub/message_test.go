@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// TestMessageEnvelope tests that envelope subscribers receive headers, IDs, topic and
+// publish time, and that the headers are copied
+func TestMessageEnvelope(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(100, 0))
+	pub := NewPublisher[string](WithClock(fake), WithDefaultBuffer(2))
+	pub.CreateTopic("orders")
+	sub, err := pub.SubscribeMessages("orders")
+	if err != nil {
+		t.Fatalf("SubscribeMessages() returned error: %v", err)
+	}
+
+	headers := map[string]string{"trace": "abc"}
+	pub.PublishMessage("orders", Message[string]{Key: "o1", Headers: headers, Value: "created"})
+	headers["trace"] = "changed"
+	pub.DeleteKey("orders", "o1")
+
+	msg := <-sub.C
+	if msg.Headers["trace"] != "abc" || msg.Key != "o1" || msg.Value != "created" {
+		t.Errorf("Expected o1 created with trace abc, got %+v", msg)
+	}
+	if msg.Topic != "orders" || !msg.Time.Equal(fake.Now()) || msg.ID == "" {
+		t.Errorf("Expected topic, publish time and ID filled in, got %+v", msg)
+	}
+	if tomb := <-sub.C; !tomb.Deleted || tomb.Key != "o1" {
+		t.Errorf("Expected a tombstone for o1, got %+v", tomb)
+	}
+	if err := sub.Close(); err != nil {
+		t.Errorf("Close() returned error: %v", err)
+	}
+}
+
+// TestSubscribeMessagesRetained tests that SubscribeMessages starts with the retained
+// message
+func TestSubscribeMessagesRetained(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("config", WithRetain())
+	pub.PublishWithID("config", "v1", "debug=true")
+
+	sub, _ := pub.SubscribeMessages("config")
+	if msg := <-sub.C; msg.ID != "v1" || msg.Value != "debug=true" {
+		t.Errorf("Expected the retained v1 envelope, got %+v", msg)
+	}
+}
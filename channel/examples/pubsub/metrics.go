@@ -0,0 +1,126 @@
+package main
+
+import (
+	"expvar"
+	"strconv"
+	"sync/atomic"
+)
+
+// expvarRoot is the namespaced expvar map every Publisher registers itself under.
+// Importing expvar installs the /debug/vars handler on http.DefaultServeMux, so any
+// example that serves HTTP exposes these values without extra wiring.
+var expvarRoot = expvar.NewMap("pubsub")
+
+// metrics holds the Publisher's counters.
+//
+// Go Concurrency Patterns used:
+//   - Atomic counters: Publish only holds the read lock, so several publishers can
+//     update the counters at the same time; sync/atomic keeps those updates race-free
+type metrics struct {
+	published    atomic.Int64 // Messages accepted by Publish
+	delivered    atomic.Int64 // Successful sends into subscriber channels
+	rateLimited  atomic.Int64 // Deliveries skipped because a subscriber exceeded its quota
+	lagged       atomic.Int64 // Times a catch-up subscriber fell behind and switched to the store
+	dropped      atomic.Int64 // Messages discarded by a subscriber's overflow policy or by Shutdown
+	deadLettered atomic.Int64 // Undelivered messages sent to the dead-letter topic
+	evicted      atomic.Int64 // Slow subscribers removed by WithSlowSubscriberEviction
+	expired      atomic.Int64 // Deliveries skipped because the message outlived its TTL
+	redelivered  atomic.Int64 // Unacknowledged messages delivered again (SubscribeAcked)
+	deduplicated atomic.Int64 // Duplicate messages filtered by WithDedup
+	routed       atomic.Int64 // Messages forwarded to another topic by AddRoute
+	invalid      atomic.Int64 // Publishes rejected by a topic's validator (SetValidator)
+	panics       atomic.Int64 // Handler calls that panicked (SubscribeFunc)
+}
+
+// PublishExpvar registers the Publisher's counters and gauges under pubsub.<name>
+// in expvar. Values are computed lazily each time /debug/vars is scraped.
+//
+// Exported values:
+//   - topics: number of open topics (gauge)
+//   - subscribers: number of subscriber channels across all topics (gauge)
+//   - published: total messages published (counter)
+//   - delivered: total messages delivered to subscribers (counter)
+//   - rate_limited: deliveries skipped by per-subscriber quotas (counter)
+//   - lagged: catch-up subscribers switched from live delivery to the store (counter)
+//   - dropped: messages discarded by subscriber overflow policies or by Shutdown (counter)
+//   - pending: messages queued for async delivery (gauge, see WithAsyncDelivery)
+//   - dead_lettered: undelivered messages routed to the dead-letter topic (counter)
+//   - evicted: slow subscribers removed (counter, see WithSlowSubscriberEviction)
+//   - expired: deliveries skipped because the message's TTL ran out (counter, see WithTTL)
+//   - redelivered: unacknowledged messages delivered again (counter, see SubscribeAcked)
+//   - deduplicated: duplicate messages filtered out (counter, see WithDedup)
+//   - routed: messages forwarded between topics (counter, see AddRoute)
+//   - invalid: publishes rejected by topic validators (counter, see SetValidator)
+//   - handler_panics: handler calls recovered from a panic (counter, see SubscribeFunc)
+//
+// The per-topic view (see Stats and SubscriberStats) is registered next to it, under
+// pubsub.<name>.topics: for each topic its published, delivered and dropped counters,
+// subscribers and max_backlog gauges, and a subscriber map from id to the subscriber's
+// pending, delivered and blocked_ns values.
+//
+// Parameters:
+//   - name: string - key under the "pubsub" map; registering the same name again replaces it
+func (p *Publisher[T]) PublishExpvar(name string) {
+	expvarRoot.Set(name, expvar.Func(func() any {
+		return p.expvarSnapshot()
+	}))
+	expvarRoot.Set(name+".topics", expvar.Func(func() any {
+		return p.expvarTopics()
+	}))
+}
+
+// expvarSnapshot collects the current gauge and counter values.
+func (p *Publisher[T]) expvarSnapshot() map[string]int64 {
+	subscribers := 0
+	p.eachTopic(func(_ string, _ *topicState[T], subs []*subscriber[T]) {
+		subscribers += len(subs)
+	})
+	var pending int64
+	if p.async != nil {
+		pending = p.async.pending.Load()
+	}
+	return map[string]int64{
+		"topics":         p.topicCount.Load(),
+		"subscribers":    int64(subscribers),
+		"published":      p.metrics.published.Load(),
+		"delivered":      p.metrics.delivered.Load(),
+		"rate_limited":   p.metrics.rateLimited.Load(),
+		"lagged":         p.metrics.lagged.Load(),
+		"dropped":        p.metrics.dropped.Load(),
+		"pending":        pending,
+		"dead_lettered":  p.metrics.deadLettered.Load(),
+		"evicted":        p.metrics.evicted.Load(),
+		"expired":        p.metrics.expired.Load(),
+		"redelivered":    p.metrics.redelivered.Load(),
+		"deduplicated":   p.metrics.deduplicated.Load(),
+		"routed":         p.metrics.routed.Load(),
+		"invalid":        p.metrics.invalid.Load(),
+		"handler_panics": p.metrics.panics.Load(),
+	}
+}
+
+// expvarTopics collects the per-topic and per-subscriber values.
+func (p *Publisher[T]) expvarTopics() map[string]map[string]any {
+	topics := make(map[string]map[string]any)
+	p.eachTopic(func(topic string, state *topicState[T], subs []*subscriber[T]) {
+		stats := topicStatsOf(state, subs)
+		bySubscriber := make(map[string]map[string]int64, len(subs))
+		for _, sub := range subs {
+			s := sub.stats()
+			bySubscriber[strconv.FormatUint(s.ID, 10)] = map[string]int64{
+				"pending":    int64(s.Pending),
+				"delivered":  s.Delivered,
+				"blocked_ns": int64(s.Blocked),
+			}
+		}
+		topics[topic] = map[string]any{
+			"published":   stats.Published,
+			"delivered":   stats.Delivered,
+			"dropped":     stats.Dropped,
+			"subscribers": stats.Subscribers,
+			"max_backlog": stats.MaxBacklog,
+			"subscriber":  bySubscriber,
+		}
+	})
+	return topics
+}
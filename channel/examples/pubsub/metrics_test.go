@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestPublishExpvar tests that counters and gauges are visible through expvar
+func TestPublishExpvar(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.PublishExpvar("test-publisher")
+	pub.CreateTopic("test-topic")
+
+	ch, err := pub.Subscribe("test-topic")
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	if err := pub.Publish("test-topic", "message"); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	<-ch
+
+	var got map[string]int64
+	if err := json.Unmarshal([]byte(expvarRoot.Get("test-publisher").String()), &got); err != nil {
+		t.Fatalf("Failed to decode expvar value: %v", err)
+	}
+
+	want := map[string]int64{"topics": 1, "subscribers": 1, "published": 1, "delivered": 1}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("Expected %s=%d, got %d", key, value, got[key])
+		}
+	}
+}
+
+// TestPublishExpvarTopics tests that per-topic and per-subscriber values are visible
+// through expvar
+func TestPublishExpvarTopics(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	pub.PublishExpvar("test-topics")
+	pub.CreateTopic("orders")
+	pub.Subscribe("orders")
+	pub.Publish("orders", "o1")
+
+	var got map[string]struct {
+		Published   int64
+		Subscribers int
+		MaxBacklog  int `json:"max_backlog"`
+		Subscriber  map[string]map[string]int64
+	}
+	if err := json.Unmarshal([]byte(expvarRoot.Get("test-topics.topics").String()), &got); err != nil {
+		t.Fatalf("Failed to decode expvar value: %v", err)
+	}
+	orders := got["orders"]
+	if orders.Published != 1 || orders.Subscribers != 1 || orders.MaxBacklog != 1 {
+		t.Errorf("Expected 1 published, 1 subscriber and a backlog of 1, got %+v", orders)
+	}
+	for id, values := range orders.Subscriber {
+		if values["pending"] != 1 || values["delivered"] != 1 {
+			t.Errorf("Expected subscriber %s to have 1 pending and 1 delivered, got %v", id, values)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"slices"
+)
+
+// PublishFunc is one step of a publish or of a delivery to one subscriber, as seen by
+// middleware (see Use). topic is the topic as given to Publish; msg.Topic holds it with
+// its namespace path.
+type PublishFunc[T any] func(ctx context.Context, topic string, msg Message[T]) error
+
+// Middleware wraps a PublishFunc with cross-cutting behavior: it may inspect or modify
+// msg before calling next, reject it by returning an error without calling next, or
+// observe the outcome after next returns.
+type Middleware[T any] func(next PublishFunc[T]) PublishFunc[T]
+
+// middlewareChain is the middleware installed with Use, outermost first.
+type middlewareChain[T any] []Middleware[T]
+
+// wrap returns core wrapped in every middleware of the chain.
+func (c middlewareChain[T]) wrap(core PublishFunc[T]) PublishFunc[T] {
+	for _, mw := range slices.Backward(c) {
+		core = mw(core)
+	}
+	return core
+}
+
+// subscriberKey is the context key SubscriberFromContext reads.
+type subscriberKey struct{}
+
+// SubscriberFromContext tells middleware which stage it is wrapping: it returns the id
+// of the subscriber being delivered to (as in SubscriberStats) and true during a
+// delivery, and false during a publish.
+func SubscriberFromContext(ctx context.Context) (uint64, bool) {
+	id, ok := ctx.Value(subscriberKey{}).(uint64)
+	return id, ok
+}
+
+// Use layers middleware around every publish and every per-subscriber delivery, for
+// logging, validation, metrics or mutation. The first middleware given (over all Use
+// calls) is the outermost. Each middleware runs twice per subscriber-bound message:
+//
+//   - Once per publish, on the publishing goroutine, after authorization and before
+//     the message is stored, queued (WithAsyncDelivery) or broadcast. Returning an
+//     error without calling next rejects the publish, and Publish returns that error.
+//     A modified msg is what every subscriber receives.
+//   - Once per subscriber the message is handed to, with the read lock held; use
+//     SubscriberFromContext to tell the stages apart. next returns ErrDropped when the
+//     subscriber did not get the message (the dead letter, if any, has the precise
+//     reason). Returning an error without calling next skips this subscriber, and the
+//     message goes to the dead-letter topic with that error. A modified msg only
+//     affects this subscriber.
+//
+// Delivery middleware must not call back into the Publisher.
+//
+// Usage example (validation):
+//
+//	pub.Use(func(next PublishFunc[Order]) PublishFunc[Order] {
+//		return func(ctx context.Context, topic string, msg Message[Order]) error {
+//			if _, delivering := SubscriberFromContext(ctx); !delivering && msg.Value.ID == "" {
+//				return errors.New("order without id")
+//			}
+//			return next(ctx, topic, msg)
+//		}
+//	})
+func (p *Publisher[T]) Use(middleware ...Middleware[T]) {
+	p.Lock()
+	defer p.Unlock()
+	var chain middlewareChain[T]
+	if old := p.middleware.Load(); old != nil {
+		chain = slices.Clone(*old)
+	}
+	chain = append(chain, middleware...)
+	p.middleware.Store(&chain)
+}
+
+// sendVia hands msg to sub (see send) through the delivery middleware, if any. err is
+// set when a middleware rejected the delivery; r tells what happened otherwise.
+func (p *Publisher[T]) sendVia(ctx context.Context, topic string, sub *subscriber[T], msg Message[T]) (r offered[Message[T]], err error) {
+	chain := p.middleware.Load()
+	if chain == nil {
+		return p.send(ctx, sub, msg), nil
+	}
+	sent := false
+	core := func(ctx context.Context, topic string, msg Message[T]) error {
+		sent = true
+		if r = p.send(ctx, sub, msg); !r.delivered {
+			return ErrDropped
+		}
+		return nil
+	}
+	err = chain.wrap(core)(context.WithValue(ctx, subscriberKey{}, sub.id), topic, msg)
+	if sent {
+		return r, nil // Delivery failures are handled from r, not from middleware errors
+	}
+	return r, err
+}
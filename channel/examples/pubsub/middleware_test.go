@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestMiddleware tests publish and delivery middleware: order, mutation, rejection
+// and the stage reported by SubscriberFromContext
+func TestMiddleware(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4), WithDeadLetters())
+	dead, _ := pub.SubscribeDeadLetters()
+	pub.CreateTopic("news")
+	first, _ := pub.Subscribe("news")
+	second, _ := pub.Subscribe("news")
+	secondID := uint64(2)
+
+	var mu sync.Mutex
+	var calls []string
+	pub.Use(func(next PublishFunc[string]) PublishFunc[string] {
+		return func(ctx context.Context, topic string, msg Message[string]) error {
+			id, delivering := SubscriberFromContext(ctx)
+			mu.Lock()
+			calls = append(calls, map[bool]string{false: "publish", true: "deliver"}[delivering])
+			mu.Unlock()
+			if !delivering && msg.Value == "" {
+				return errors.New("empty message")
+			}
+			if delivering && id == secondID && strings.HasPrefix(msg.Value, "SECRET") { // Upper-cased at publish
+				return errors.New("not for this subscriber")
+			}
+			return next(ctx, topic, msg)
+		}
+	}, func(next PublishFunc[string]) PublishFunc[string] {
+		return func(ctx context.Context, topic string, msg Message[string]) error {
+			msg.Value = strings.ToUpper(msg.Value)
+			return next(ctx, topic, msg)
+		}
+	})
+
+	if err := pub.Publish("news", ""); err == nil || err.Error() != "empty message" {
+		t.Errorf("Expected the publish to be rejected, got %v", err)
+	}
+	pub.Publish("news", "hello")
+	pub.Publish("news", "secret plan")
+
+	if a, b := <-first, <-first; a != "HELLO" || b != "SECRET PLAN" {
+		t.Errorf("Expected HELLO and SECRET PLAN, got %q and %q", a, b)
+	}
+	if a := <-second; a != "HELLO" || len(second) != 0 {
+		t.Errorf("Expected only HELLO for the second subscriber, got %q and %d more", a, len(second))
+	}
+	if letter := <-dead; letter.Subscriber != secondID || letter.Reason.Error() != "not for this subscriber" {
+		t.Errorf("Expected the rejected delivery dead-lettered, got %+v", letter)
+	}
+	want := "publish publish deliver deliver publish deliver deliver"
+	if got := strings.Join(calls, " "); got != want {
+		t.Errorf("Expected calls %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// namespace returns the view of namespace name in pub, failing the test on error.
+func namespace(t *testing.T, pub *Publisher[string], name string, opts ...NamespaceOption) *Publisher[string] {
+	t.Helper()
+	ns, err := pub.Namespace(name, opts...)
+	if err != nil {
+		t.Fatalf("Namespace(%q) returned error: %v", name, err)
+	}
+	return ns
+}
+
+// TestNamespaceIsolation tests that tenants have separate topics, subscribers and metrics
+func TestNamespaceIsolation(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(10))
+	acme, globex := namespace(t, pub, "acme"), namespace(t, pub, "globex")
+	if namespace(t, pub, "acme") != acme {
+		t.Error("Expected Namespace to return the same view for the same name")
+	}
+
+	acme.CreateTopic("orders")
+	globex.CreateTopic("orders")
+	acmeCh, _ := acme.Subscribe("orders")
+	globexCh, _ := globex.Subscribe("orders")
+
+	if err := acme.Publish("orders", "acme order"); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	if got := drain(acmeCh); len(got) != 1 || got[0] != "acme order" {
+		t.Errorf("Expected acme subscriber to get 'acme order', got %v", got)
+	}
+	if got := drain(globexCh); len(got) != 0 {
+		t.Errorf("Expected globex subscriber to get nothing, got %v", got)
+	}
+	if err := pub.Publish("orders", "root"); err == nil {
+		t.Error("Expected tenant topics to be invisible to the root Publisher")
+	}
+	if got := acme.metrics.published.Load(); got != 1 {
+		t.Errorf("Expected 1 message published in acme, got %d", got)
+	}
+	if got := globex.metrics.published.Load(); got != 0 {
+		t.Errorf("Expected 0 messages published in globex, got %d", got)
+	}
+}
+
+// TestNamespaceLimits tests the topic count and buffered message limits
+func TestNamespaceLimits(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	tenant := namespace(t, pub, "tenant", WithMaxTopics(1), WithMaxBuffered(8))
+
+	if err := tenant.CreateTopic("a"); err != nil {
+		t.Fatalf("CreateTopic() returned error: %v", err)
+	}
+	if err := tenant.CreateTopic("b"); !errors.Is(err, ErrNamespaceLimit) {
+		t.Errorf("Expected ErrNamespaceLimit for second topic, got %v", err)
+	}
+	if err := tenant.CreateTopic("a"); err != nil {
+		t.Errorf("Expected re-creating a topic to stay within the limit, got %v", err)
+	}
+
+	first, err := tenant.Subscribe("a")
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	tenant.Subscribe("a")
+	if _, err := tenant.Subscribe("a"); !errors.Is(err, ErrNamespaceLimit) {
+		t.Errorf("Expected ErrNamespaceLimit beyond 8 buffered messages, got %v", err)
+	}
+
+	// Unsubscribing releases buffer space
+	tenant.CloseSubscriber("a", first)
+	if _, err := tenant.Subscribe("a"); err != nil {
+		t.Errorf("Expected Subscribe to succeed after releasing a buffer, got %v", err)
+	}
+
+	// The root Publisher is unaffected
+	for _, topic := range []string{"a", "b", "c"} {
+		if err := pub.CreateTopic(topic); err != nil {
+			t.Errorf("Expected no limit on the root Publisher, got %v", err)
+		}
+	}
+}
+
+// TestNamespaceQualifiedNames tests that the shared store and Authorizer see tenant-qualified topics
+func TestNamespaceQualifiedNames(t *testing.T) {
+	store := NewMemoryStore()
+	acl := NewACL()
+	acl.AllowPublish(Anonymous, "acme/orders")
+	acl.AllowSubscribe(Anonymous, AnyTopic)
+	pub := NewPublisher[string](WithStore(store), WithAuthorizer(acl))
+	acme, globex := namespace(t, pub, "acme"), namespace(t, pub, "globex")
+	acme.CreateTopic("orders")
+	globex.CreateTopic("orders")
+
+	if err := acme.Publish("orders", "allowed"); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	if err := globex.Publish("orders", "denied"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized for globex/orders, got %v", err)
+	}
+
+	if records, _ := store.ReadFrom("acme/orders", 0, 0); len(records) != 1 {
+		t.Errorf("Expected 1 record under acme/orders, got %d", len(records))
+	}
+	ch, err := globex.SubscribeFrom("orders", 0)
+	if err != nil {
+		t.Fatalf("SubscribeFrom() returned error: %v", err)
+	}
+	if got := drain(ch); len(got) != 0 {
+		t.Errorf("Expected globex replay to be empty, got %v", got)
+	}
+	globex.CloseTopic("orders")
+}
+
+// TestNamespaceCrossAccess tests that a tenant's topics cannot be reached through
+// their qualified names from outside the tenant
+func TestNamespaceCrossAccess(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	acme := namespace(t, pub, "acme")
+	acme.CreateTopic("orders")
+	eu := namespace(t, acme, "eu")
+
+	if err := pub.CreateTopic("acme/orders"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace creating acme/orders on the root, got %v", err)
+	}
+	if err := pub.Publish("acme/orders", "forged"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace publishing to acme/orders, got %v", err)
+	}
+	if _, err := pub.Subscribe("acme/orders"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace subscribing to acme/orders, got %v", err)
+	}
+	if err := acme.CreateTopic("eu/orders"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace for a nested namespace, got %v", err)
+	}
+	if err := eu.CreateTopic("orders"); err != nil {
+		t.Errorf("Expected the nested namespace to create its own topic, got %v", err)
+	}
+	if err := pub.CreateTopic("other/orders"); err != nil {
+		t.Errorf("Expected slashes outside namespace names to be allowed, got %v", err)
+	}
+
+	// Aliases and renames cannot reach into a namespace either
+	pub.CreateTopic("orders")
+	if err := pub.AliasTopic("acme/orders", "orders"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace aliasing acme/orders, got %v", err)
+	}
+	if err := pub.AliasTopic("legacy", "acme/orders"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace aliasing to acme/orders, got %v", err)
+	}
+	if err := pub.RenameTopic("orders", "acme/orders"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace renaming to acme/orders, got %v", err)
+	}
+	if !pub.hasTopic("orders") {
+		t.Error("Expected the refused rename to leave the topic in place")
+	}
+}
+
+// TestNamespaceTakenPrefix tests that a namespace cannot be created while root
+// topics or aliases already use its name as a prefix
+func TestNamespaceTakenPrefix(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("billing/invoices")
+	if _, err := pub.Namespace("billing"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace for a name used by a topic, got %v", err)
+	}
+
+	pub.CreateTopic("orders")
+	pub.AliasTopic("shop/orders", "orders")
+	if _, err := pub.Namespace("shop"); !errors.Is(err, ErrCrossNamespace) {
+		t.Errorf("Expected ErrCrossNamespace for a name used by an alias, got %v", err)
+	}
+
+	pub.CloseTopic("billing/invoices")
+	pub.RemoveAlias("shop/orders")
+	for _, name := range []string{"billing", "shop"} {
+		if _, err := pub.Namespace(name); err != nil {
+			t.Errorf("Expected Namespace(%q) to succeed once the prefix is free, got %v", name, err)
+		}
+	}
+}
+
+// TestNamespaceShutdown tests that shutting a namespace down leaves the rest of the
+// Publisher running
+func TestNamespaceShutdown(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	acme, globex := namespace(t, pub, "acme"), namespace(t, pub, "globex")
+	acme.CreateTopic("orders")
+	globex.CreateTopic("orders")
+	acmeCh, _ := acme.Subscribe("orders")
+	globexCh, _ := globex.Subscribe("orders")
+
+	if err := acme.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+	if _, ok := <-acmeCh; ok {
+		t.Error("Expected the acme subscriber to be closed")
+	}
+	if err := acme.Publish("orders", "late"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected ErrShutdown from acme, got %v", err)
+	}
+	if err := globex.Publish("orders", "still open"); err != nil {
+		t.Errorf("Expected globex to keep running, got %v", err)
+	}
+	if got := <-globexCh; got != "still open" {
+		t.Errorf("Expected 'still open', got %q", got)
+	}
+
+	pub.Shutdown(t.Context()) // Waits for acme's shutdown too
+	if _, ok := <-globexCh; ok {
+		t.Error("Expected the globex subscriber to be closed by the root Shutdown")
+	}
+}
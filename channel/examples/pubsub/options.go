@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync/atomic"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// defaultBuffer is the subscriber channel capacity used when WithDefaultBuffer is not given.
+const defaultBuffer = 1
+
+// config collects everything NewPublisher can be configured with.
+type config struct {
+	buffer          int                           // Capacity of each subscriber channel
+	clock           clock.Clock                   // Time source for time-based features
+	logger          *slog.Logger                  // Destination for lifecycle logs
+	metricsName     string                        // expvar key, empty means not exported
+	authorizer      Authorizer                    // Topic-level access control, nil allows everything
+	store           TopicStore                    // Topic log for replay, nil keeps nothing after delivery
+	codec           Codec                         // Encodes messages into stored records
+	shards          int                           // Independently locked topic shards (WithTopicShards)
+	asyncWorkers    int                           // Delivery goroutines (WithAsyncDelivery), 0 delivers in Publish
+	asyncQueue      int                           // Pending messages per delivery goroutine
+	topicOptions    *atomic.Pointer[topicOptions] // Per-topic options, shared with namespaces
+	dropOnShutdown  bool                          // Shutdown discards queued messages instead of delivering them
+	deadLetters     bool                          // Route undeliverable messages to a dead-letter topic
+	stallLimit      time.Duration                 // Evict subscribers stalled this long, 0 never evicts
+	onEvict         func(Eviction)                // Called after each eviction, may be nil
+	tracer          Tracer                        // Span creation (WithTracing), nil when off
+	propagator      Propagator                    // Trace context in message headers, may be nil
+	grpcCompression *CompressedCodec              // Payload compression on the gRPC bridge, nil when off
+}
+
+// topicOptions holds the Options given for single topics, by qualified topic. It is
+// never modified once stored: updateTopicOptions replaces it with a changed copy, so
+// publishes read it without locking while RenameTopic moves a topic's entries.
+type topicOptions struct {
+	deliveryConcurrency map[string]int    // Parallel deliveries per message (WithDeliveryConcurrency)
+	ciphers             map[string]Cipher // Payload encryption (WithCipher)
+	codecs              map[string]Codec  // Codecs overriding codec (WithTopicCodec)
+}
+
+// updateTopicOptions replaces the per-topic options with a copy changed by fn.
+func (c *config) updateTopicOptions(fn func(*topicOptions)) {
+	for {
+		old := c.topicOptions.Load()
+		next := &topicOptions{
+			deliveryConcurrency: maps.Clone(old.deliveryConcurrency),
+			ciphers:             maps.Clone(old.ciphers),
+			codecs:              maps.Clone(old.codecs),
+		}
+		fn(next)
+		if c.topicOptions.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// Option configures a Publisher (functional options pattern).
+//
+// Functional options keep NewPublisher() call sites working while new settings are
+// added: each setting is a function that mutates the configuration before the
+// Publisher is built, and callers pass only the ones they need.
+//
+// Usage example:
+//
+//	pub := NewPublisher(WithDefaultBuffer(16), WithLogger(slog.Default()))
+type Option func(*config)
+
+// WithDefaultBuffer sets the capacity of subscriber channels created by Subscribe.
+// Larger buffers let publishers run ahead of slow subscribers before Publish blocks.
+// Negative values are treated as 0 (unbuffered).
+func WithDefaultBuffer(size int) Option {
+	return func(c *config) {
+		c.buffer = max(size, 0)
+	}
+}
+
+// WithClock sets the time source used by time-based features. Tests pass a
+// clock.FakeClock to control time explicitly.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// WithLogger sets the logger used for topic and subscriber lifecycle events
+// (logged at debug level). By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// WithMetrics exports the Publisher's metrics via expvar under pubsub.<name>
+// (see PublishExpvar).
+func WithMetrics(name string) Option {
+	return func(c *config) {
+		c.metricsName = name
+	}
+}
+
+// WithAuthorizer enables topic-level access control: every publish and subscribe is
+// checked against a. The principal comes from PublishAs/SubscribeAs; plain Publish and
+// Subscribe are checked as Anonymous.
+func WithAuthorizer(a Authorizer) Option {
+	return func(c *config) {
+		c.authorizer = a
+	}
+}
+
+// WithStore makes the Publisher append every published message to store before
+// delivering it, which enables SubscribeFrom. The caller owns store and closes it.
+func WithStore(store TopicStore) Option {
+	return func(c *config) {
+		c.store = store
+	}
+}
+
+// WithCodec sets how message payloads are encoded wherever they leave the process: in
+// stored records, on the gRPC bridge and at the HTTP gateway (JSONCodec by default).
+// WithTopicCodec overrides it for single topics.
+func WithCodec(codec Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+// newConfig applies opts on top of the defaults.
+func newConfig(opts []Option) config {
+	cfg := config{
+		buffer:       defaultBuffer,
+		clock:        clock.Real,
+		logger:       slog.New(slog.DiscardHandler),
+		codec:        JSONCodec,
+		shards:       defaultShards,
+		topicOptions: new(atomic.Pointer[topicOptions]),
+	}
+	cfg.topicOptions.Store(&topicOptions{
+		deliveryConcurrency: make(map[string]int),
+		ciphers:             make(map[string]Cipher),
+		codecs:              make(map[string]Codec),
+	})
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// subscribeConfig collects per-subscription settings.
+type subscribeConfig struct {
+	ratePerSecond  float64       // Delivery quota, 0 means unlimited
+	rateBurst      int           // Deliveries allowed in a burst above the rate
+	catchUpBatch   int           // Store read size in catch-up mode, 0 means catch-up is off
+	credits        bool          // Credit-based flow control (WithCredits)
+	initialCredits int           // Credits granted at subscribe time
+	overflow       Overflow      // What Publish does when the subscriber channel is full
+	queueGroup     string        // Queue group sharing the topic's messages (WithQueueGroup)
+	ackTimeout     time.Duration // Redelivery delay of acked subscriptions (WithAckTimeout)
+	maxDeliveries  int           // Delivery attempts of acked subscriptions (WithMaxDeliveries)
+	dedupWindow    time.Duration // How long delivered message IDs are remembered (WithDedup)
+	concurrency    int           // Parallel handlers of a handler subscription (WithConcurrency)
+}
+
+// SubscribeOption configures a single subscription (same functional options pattern as Option).
+type SubscribeOption func(*subscribeConfig)
+
+// WithRateLimit caps deliveries to this subscriber at perSecond messages per second,
+// allowing bursts of up to burst messages. Messages beyond the quota are not delivered
+// to this subscriber (other subscribers are unaffected), which protects a consumer that
+// cannot keep up without slowing down the publisher.
+//
+// The quota is enforced with the Publisher's KeyedLimiter, keyed by subscriber.
+func WithRateLimit(perSecond float64, burst int) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.ratePerSecond = perSecond
+		c.rateBurst = burst
+	}
+}
+
+func newSubscribeConfig(opts []SubscribeOption) subscribeConfig {
+	if len(opts) == 0 {
+		return subscribeConfig{} // cfg below escapes to the heap through the options
+	}
+	var cfg subscribeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// debugEnabled reports whether the configured logger emits debug records.
+// Call sites check it first so log arguments are not boxed into interfaces
+// (one allocation each) on every subscribe/unsubscribe when logging is off.
+func (p *Publisher[T]) debugEnabled() bool {
+	return p.config.logger.Enabled(context.Background(), slog.LevelDebug)
+}
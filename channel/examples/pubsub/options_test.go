@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWithDefaultBuffer tests that subscriber channels use the configured capacity
+func TestWithDefaultBuffer(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(3))
+	topic := "test-topic"
+	pub.CreateTopic(topic)
+
+	ch, err := pub.Subscribe(topic)
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	if cap(ch) != 3 {
+		t.Fatalf("Expected subscriber buffer 3, got %d", cap(ch))
+	}
+
+	// Three publishes fit in the buffer without a reader
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, msg := range []string{"m1", "m2", "m3"} {
+			if err := pub.Publish(topic, msg); err != nil {
+				t.Errorf("Publish() returned error: %v", err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Publish blocked although the buffer had room")
+	}
+}
+
+// TestNewPublisherDefaults tests that NewPublisher[string]() without options keeps the original behavior
+func TestNewPublisherDefaults(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("test-topic")
+
+	ch, err := pub.Subscribe("test-topic")
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	if cap(ch) != 1 {
+		t.Errorf("Expected default subscriber buffer 1, got %d", cap(ch))
+	}
+}
+
+// TestWithMetrics tests that WithMetrics registers the Publisher in expvar
+func TestWithMetrics(t *testing.T) {
+	NewPublisher[string](WithMetrics("test-with-metrics"))
+	if expvarRoot.Get("test-with-metrics") == nil {
+		t.Fatal("Expected Publisher to be registered under pubsub.test-with-metrics")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// ErrDropped is the DeadLetter reason for messages discarded by OverflowDropNewest or
+// OverflowDropOldest.
+var ErrDropped = errors.New("dropped by overflow policy")
+
+// ErrSubscriberFull is returned (wrapped) by Publish when a subscriber with the
+// OverflowError policy had no room for the message. The other subscribers still
+// received it.
+var ErrSubscriberFull = errors.New("subscriber channel full")
+
+// Overflow selects what Publish does when a subscriber's channel is full.
+type Overflow int
+
+const (
+	// OverflowBlock waits until the subscriber makes room (the default): nothing is
+	// lost, but one slow subscriber stalls every publisher of the topic.
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest discards the message for this subscriber.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered message to make room for the new
+	// one, so the subscriber always sees the most recent messages.
+	OverflowDropOldest
+	// OverflowError discards the message for this subscriber and makes Publish return
+	// ErrSubscriberFull once the other subscribers have been served.
+	OverflowError
+)
+
+// WithOverflow sets what Publish does when this subscriber's channel is full
+// (OverflowBlock by default). The non-blocking policies keep a slow subscriber from
+// freezing publishers. On an unbuffered subscriber (WithDefaultBuffer(0)) there is
+// nothing to evict, so OverflowDropOldest drops the new message instead.
+//
+// Catch-up subscribers (WithCatchUp) never block the publisher and ignore the policy.
+func WithOverflow(policy Overflow) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.overflow = policy
+	}
+}
+
+// offered is the outcome of one offer.
+type offered[M any] struct {
+	delivered bool
+	evicted   []M           // Older messages evicted to make room (OverflowDropOldest)
+	blocked   time.Duration // Time spent waiting for room (OverflowBlock)
+	stalled   bool          // Gave up after the stall limit (see WithSlowSubscriberEviction)
+}
+
+// offer sends msg on ch according to policy. OverflowBlock gives up when ctx is done or,
+// with a positive stall limit, after waiting that long according to clk.
+//
+// Go Concurrency Patterns used:
+//   - Non-blocking send: select with default gives up instead of waiting for room
+//   - Non-blocking receive: the publisher takes the oldest message out of the buffer
+//     itself; if the subscriber got there first, the next send simply succeeds
+//   - Fast path first: the clock and timer are only touched when ch is full
+func offer[M any](ctx context.Context, ch chan M, msg M, policy Overflow, clk clock.Clock, stall time.Duration) (r offered[M]) {
+	select {
+	case ch <- msg:
+		r.delivered = true
+		return r
+	default:
+	}
+	if policy == OverflowBlock {
+		var timeout <-chan time.Time
+		if stall > 0 {
+			timer := clk.NewTimer(stall)
+			defer timer.Stop()
+			timeout = timer.C()
+		}
+		start := clk.Now()
+		select {
+		case ch <- msg:
+			r.delivered = true
+		case <-ctx.Done():
+		case <-timeout:
+			r.stalled = true
+		}
+		r.blocked = clk.Now().Sub(start)
+		return r
+	}
+	for policy == OverflowDropOldest && cap(ch) > 0 {
+		select {
+		case old := <-ch:
+			r.evicted = append(r.evicted, old)
+		default: // The subscriber made room in the meantime
+		}
+		select {
+		case ch <- msg:
+			r.delivered = true
+			return r
+		default:
+		}
+	}
+	return r
+}
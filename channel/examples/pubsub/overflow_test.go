@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// TestOverflowPolicies tests what each non-blocking policy keeps when a subscriber
+// stops reading, while Publish keeps serving the other subscribers
+func TestOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Overflow
+		want   []string
+		err    error
+	}{
+		{"drop-newest", OverflowDropNewest, []string{"0", "1"}, nil},
+		{"drop-oldest", OverflowDropOldest, []string{"3", "4"}, nil},
+		{"error", OverflowError, []string{"0", "1"}, ErrSubscriberFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := NewPublisher[string](WithDefaultBuffer(2))
+			pub.CreateTopic("ticks")
+			slow, _ := pub.Subscribe("ticks", WithOverflow(tt.policy))
+			fast, _ := pub.Subscribe("ticks", WithOverflow(OverflowDropNewest))
+
+			var fastGot []string
+			for i := range 5 {
+				var want error
+				if i >= 2 { // The slow subscriber's buffer is full
+					want = tt.err
+				}
+				if err := pub.Publish("ticks", fmt.Sprint(i)); !errors.Is(err, want) {
+					t.Errorf("Publish(%d) returned %v, expected %v", i, err, want)
+				}
+				fastGot = append(fastGot, drain(fast)...)
+			}
+			if got := drain(slow); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected slow subscriber to keep %v, got %v", tt.want, got)
+			}
+			if want := []string{"0", "1", "2", "3", "4"}; !slices.Equal(fastGot, want) {
+				t.Errorf("Expected fast subscriber to get %v, got %v", want, fastGot)
+			}
+			if dropped := pub.metrics.dropped.Load(); dropped != 3 {
+				t.Errorf("Expected 3 dropped messages, got %d", dropped)
+			}
+		})
+	}
+}
+
+// TestOverflowDropOldestUnbuffered tests that drop-oldest on an unbuffered subscriber
+// drops the new message instead of spinning
+func TestOverflowDropOldestUnbuffered(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(0))
+	pub.CreateTopic("ticks")
+	pub.Subscribe("ticks", WithOverflow(OverflowDropOldest))
+	if err := pub.Publish("ticks", "lost"); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	if dropped := pub.metrics.dropped.Load(); dropped != 1 {
+		t.Errorf("Expected 1 dropped message, got %d", dropped)
+	}
+}
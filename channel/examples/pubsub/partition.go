@@ -0,0 +1,35 @@
+package main
+
+import "hash/maphash"
+
+// partitionSeed hashes message keys to partitions. Partitions only route live
+// messages, so the assignment need not be stable across restarts.
+var partitionSeed = maphash.MakeSeed()
+
+// WithPartitions splits the topic into n partitions: every message published with
+// PublishKeyed goes to the partition its key hashes to, so all messages of one key
+// take the same path while different keys spread over the partitions. Concretely:
+//
+//   - In a queue group (WithQueueGroup), each partition belongs to one member, so a
+//     member sees every message of its keys, in publish order, and the members work
+//     on different keys in parallel. A keyed message waits for its member even when
+//     another member has room; unkeyed messages still take turns.
+//   - With WithAsyncDelivery, each partition is served by one worker instead of the
+//     whole topic, so a busy key does not hold up the others.
+//
+// Per-key order holds as long as the queue group's membership does not change:
+// partitions are reassigned when a member joins or leaves.
+func WithPartitions(n int) TopicOption {
+	return func(c *topicConfig) {
+		c.partitions = max(n, 0)
+	}
+}
+
+// partition returns the partition of msg, and false when msg is not routed by
+// partition (unkeyed, or the topic is not partitioned).
+func (s *topicState[T]) partition(msg Message[T]) (int, bool) {
+	if s.settings.partitions == 0 || msg.Key == "" {
+		return 0, false
+	}
+	return int(maphash.String(partitionSeed, msg.Key) % uint64(s.settings.partitions)), true
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// checkKeyOrder reads n "key/seq" messages from each channel and fails if a key shows
+// up on two channels or out of order. It returns how many messages each channel had.
+func checkKeyOrder(t *testing.T, n []int, channels ...<-chan string) {
+	t.Helper()
+	owner := map[string]int{}
+	next := map[string]int{}
+	for i, ch := range channels {
+		for range n[i] {
+			var key string
+			var seq int
+			fmt.Sscanf(strings.Replace(<-ch, "/", " ", 1), "%s %d", &key, &seq)
+			if o, ok := owner[key]; ok && o != i {
+				t.Errorf("Expected key %s on one channel, got it on %d and %d", key, o, i)
+			}
+			owner[key] = i
+			if seq != next[key] {
+				t.Errorf("Expected %s/%d next, got %s/%d", key, next[key], key, seq)
+			}
+			next[key] = seq + 1
+		}
+	}
+}
+
+// publishKeys publishes rounds messages for each of keys keys, interleaving the keys.
+func publishKeys(t *testing.T, pub *Publisher[string], topic string, keys, rounds int) {
+	t.Helper()
+	for seq := range rounds {
+		for k := range keys {
+			if err := pub.PublishKeyed(topic, fmt.Sprint("k", k), fmt.Sprintf("k%d/%d", k, seq)); err != nil {
+				t.Fatalf("PublishKeyed() returned error: %v", err)
+			}
+		}
+	}
+}
+
+// TestPartitionedQueueGroup tests that a key's messages all reach the same queue
+// group member, in order
+func TestPartitionedQueueGroup(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(200))
+	pub.CreateTopic("orders", WithPartitions(8))
+	m1, _ := pub.Subscribe("orders", WithQueueGroup("billing"))
+	m2, _ := pub.Subscribe("orders", WithQueueGroup("billing"))
+
+	publishKeys(t, pub, "orders", 32, 5)
+	if len(m1)+len(m2) != 160 || len(m1) == 0 || len(m2) == 0 {
+		t.Fatalf("Expected both members to share 160 messages, got %d and %d", len(m1), len(m2))
+	}
+	checkKeyOrder(t, []int{len(m1), len(m2)}, m1, m2)
+}
+
+// TestPartitionedAsyncDelivery tests that per-key order holds when partitions of a
+// topic are delivered by different workers
+func TestPartitionedAsyncDelivery(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(200), WithAsyncDelivery(4, 200))
+	pub.CreateTopic("orders", WithPartitions(4))
+	ch, _ := pub.Subscribe("orders")
+
+	publishKeys(t, pub, "orders", 10, 10)
+	if err := pub.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+	checkKeyOrder(t, []int{100}, ch)
+}
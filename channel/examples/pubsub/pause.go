@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrTopicPaused is returned when a publish reaches a paused topic (see PauseTopic)
+// whose buffer is full, or that buffers nothing.
+var ErrTopicPaused = errors.New("topic paused")
+
+// pauseConfig holds the settings of a PauseTopic.
+type pauseConfig struct {
+	buffer int // Publishes held until ResumeTopic, 0 rejects every publish
+}
+
+// PauseOption configures a PauseTopic (functional options pattern).
+type PauseOption func(*pauseConfig)
+
+// WithPauseBuffer holds up to n publishes while the topic is paused and delivers them
+// on ResumeTopic; publishes beyond n fail with ErrTopicPaused. Without it a paused topic
+// rejects every publish.
+func WithPauseBuffer(n int) PauseOption {
+	return func(c *pauseConfig) {
+		c.buffer = n
+	}
+}
+
+// pauseState is the state of a paused topic, held in topicState.paused.
+type pauseState[T any] struct {
+	mu       sync.Mutex
+	limit    int          // Most messages held (WithPauseBuffer)
+	held     []Message[T] // Publishes waiting for ResumeTopic, in publish order
+	resuming bool         // ResumeTopic is flushing held
+	repause  bool         // PauseTopic during the flush: stop it and keep the rest held
+	over     bool         // Flush finished: publishes that raced with it deliver directly
+}
+
+// resumeKey is the context key of the deliveries ResumeTopic makes; its value is the
+// pauseState being flushed, so messages it routes to other paused topics are held.
+type resumeKey struct{}
+
+// PauseTopic stops delivery on topic until ResumeTopic: publishes are held, up to
+// WithPauseBuffer of them, or rejected with ErrTopicPaused. Messages already in
+// subscriber channels stay there. Pausing a paused topic changes nothing. Pausing a
+// topic that ResumeTopic is flushing stops the flush after the message in delivery:
+// the messages not yet flushed stay held, with opts applying from then on.
+//
+// Held messages are not stored, routed or counted as published yet; they are, in
+// order, when ResumeTopic flushes them. With WithAsyncDelivery a rejection reaches the
+// delivery worker, which logs it, instead of the publisher. Closing the topic, or
+// shutting the Publisher down, discards the held messages.
+//
+// Usage example:
+//
+//	pub.PauseTopic("orders", WithPauseBuffer(1000)) // Maintenance window
+//	migrate()
+//	pub.ResumeTopic("orders")
+func (p *Publisher[T]) PauseTopic(topic string, opts ...PauseOption) error {
+	var settings pauseConfig
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	state, ok := s.topics[topic]
+	if !ok {
+		return errors.New("topic not found")
+	}
+	if ps := state.paused; ps != nil {
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+		switch {
+		case ps.over:
+			// The flush is done and the pause about to be lifted: pause afresh
+		case ps.resuming:
+			ps.repause, ps.limit = true, settings.buffer
+			return nil
+		default:
+			return nil
+		}
+	}
+	state.paused = &pauseState[T]{limit: settings.buffer}
+	return nil
+}
+
+// ResumeTopic restarts delivery on a paused topic: the held messages are delivered in
+// publish order, then the topic goes back to normal. Publishes that arrive during the
+// flush are held behind the earlier ones, so subscribers see every message in publish
+// order. ResumeTopic returns once the flush is over, or stopped by a PauseTopic;
+// failed deliveries are logged. Resuming a topic that is not paused changes nothing.
+//
+// Go Concurrency Patterns used:
+//   - Drain then switch: the flush pops held messages one at a time under the pause
+//     mutex and only clears the pause once none is left, with the same mutex deciding
+//     for each concurrent publish whether it still queues or delivers directly
+func (p *Publisher[T]) ResumeTopic(topic string) error {
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	state, ok := s.topics[topic]
+	var ps *pauseState[T]
+	if ok {
+		ps = state.paused
+	}
+	s.Unlock()
+	if !ok {
+		return errors.New("topic not found")
+	}
+	if ps == nil {
+		return nil
+	}
+	ps.mu.Lock()
+	if ps.resuming {
+		ps.repause = false // Undoes a PauseTopic since the flush started
+		ps.mu.Unlock()
+		return nil // Another ResumeTopic is flushing
+	}
+	ps.resuming = true
+	ps.mu.Unlock()
+
+	ctx := context.WithValue(context.Background(), resumeKey{}, ps)
+	for {
+		ps.mu.Lock()
+		if ps.repause {
+			ps.resuming, ps.repause = false, false
+			ps.mu.Unlock()
+			return nil // Paused again: the rest waits for the next ResumeTopic
+		}
+		if len(ps.held) == 0 {
+			ps.over = true
+			ps.mu.Unlock()
+			break
+		}
+		msg := ps.held[0]
+		ps.held = ps.held[1:]
+		ps.mu.Unlock()
+		if err := p.deliver(ctx, topic, msg); err != nil {
+			p.config.logger.Warn("pubsub: resume delivery failed", "topic", topic, "error", err)
+		}
+	}
+
+	s.Lock()
+	if s.topics[topic] == state && state.paused == ps {
+		state.paused = nil
+	}
+	s.Unlock()
+	return nil
+}
+
+// holdLocked holds msg if state is paused. It reports whether msg was taken care of,
+// and ErrTopicPaused if it was rejected. Called with the shard's lock held.
+func (p *Publisher[T]) holdLocked(ctx context.Context, state *topicState[T], msg Message[T]) (bool, error) {
+	ps := state.paused
+	if ps == nil || ctx.Value(resumeKey{}) == ps {
+		return false, nil
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	switch {
+	case ps.over:
+		return false, nil // The flush is over, deliver directly
+	case len(ps.held) >= ps.limit:
+		return true, ErrTopicPaused
+	}
+	ps.held = append(ps.held, msg)
+	return true, nil
+}
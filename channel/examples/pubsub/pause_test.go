@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestPauseTopic tests that a paused topic holds publishes up to its buffer, rejects
+// the rest, and delivers the held ones in order on resume
+func TestPauseTopic(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(16))
+	pub.CreateTopic("numbers")
+	sub, _ := pub.Subscribe("numbers")
+
+	if err := pub.PauseTopic("missing"); err == nil {
+		t.Error("Expected an error for an unknown topic")
+	}
+	pub.PauseTopic("numbers", WithPauseBuffer(2))
+	pub.Publish("numbers", 1)
+	pub.Publish("numbers", 2)
+	if err := pub.Publish("numbers", 3); !errors.Is(err, ErrTopicPaused) {
+		t.Errorf("Expected ErrTopicPaused beyond the buffer, got %v", err)
+	}
+	select {
+	case msg := <-sub:
+		t.Fatalf("Expected no delivery while paused, got %d", msg)
+	default:
+	}
+
+	pub.ResumeTopic("numbers")
+	pub.Publish("numbers", 4)
+	for _, want := range []int{1, 2, 4} {
+		if got := <-sub; got != want {
+			t.Errorf("Expected %d, got %d", want, got)
+		}
+	}
+
+	pub.PauseTopic("numbers")
+	if err := pub.Publish("numbers", 5); !errors.Is(err, ErrTopicPaused) {
+		t.Errorf("Expected ErrTopicPaused without a buffer, got %v", err)
+	}
+	pub.ResumeTopic("numbers")
+}
+
+// TestResumeTopicOrder tests that publishes racing with a resume are delivered after
+// the held messages
+func TestResumeTopicOrder(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(256))
+	pub.CreateTopic("numbers")
+	sub, _ := pub.Subscribe("numbers")
+	pub.PauseTopic("numbers", WithPauseBuffer(256))
+	for i := range 100 {
+		pub.Publish("numbers", i)
+	}
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := 100; i < 200; i++ {
+			pub.Publish("numbers", i)
+		}
+	})
+	pub.ResumeTopic("numbers")
+	wg.Wait()
+	for want := range 200 {
+		if got := <-sub; got != want {
+			t.Fatalf("Expected %d, got %d", want, got)
+		}
+	}
+}
+
+// TestPauseDuringResume tests that a PauseTopic while ResumeTopic is flushing stops
+// the flush and holds the rest, instead of being lost
+func TestPauseDuringResume(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(1))
+	pub.CreateTopic("numbers")
+	sub, _ := pub.Subscribe("numbers")
+	pub.PauseTopic("numbers", WithPauseBuffer(3))
+	for i := 1; i <= 3; i++ {
+		pub.Publish("numbers", i)
+	}
+
+	// The flush delivers 1, then blocks on the full subscriber with 2, holding up the
+	// PauseTopic until the subscriber makes room
+	resumed := make(chan struct{})
+	go func() {
+		pub.ResumeTopic("numbers")
+		close(resumed)
+	}()
+	waitFor(t, func() bool { return pub.TopicPressure("numbers").Backlog == 1 })
+	paused := make(chan error)
+	go func() { paused <- pub.PauseTopic("numbers", WithPauseBuffer(3)) }()
+	waitFor(t, func() bool { // A waiting writer makes TryRLock fail
+		s := pub.shard("numbers")
+		if s.TryRLock() {
+			s.RUnlock()
+			return false
+		}
+		return true
+	})
+
+	var got []int
+	for stopped := false; !stopped; {
+		select {
+		case msg := <-sub:
+			got = append(got, msg)
+		case <-resumed:
+			stopped = true
+		}
+	}
+	if err := <-paused; err != nil {
+		t.Fatalf("PauseTopic() returned error: %v", err)
+	}
+	for len(sub) > 0 {
+		got = append(got, <-sub)
+	}
+	if err := pub.Publish("numbers", 4); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	select {
+	case msg := <-sub:
+		t.Fatalf("Expected the topic to stay paused, got %d", msg)
+	default:
+	}
+
+	go pub.ResumeTopic("numbers")
+	for len(got) < 4 {
+		got = append(got, <-sub)
+	}
+	if want := []int{1, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
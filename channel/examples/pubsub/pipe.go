@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTopicClosed is the Pipe.Err of a pipe whose topic was closed (by CloseTopic or
+// Shutdown) while it was running.
+var ErrTopicClosed = errors.New("topic closed")
+
+// pipeConfig holds the settings of a PipeChannel.
+type pipeConfig struct {
+	ctx context.Context // Stops the pipe when done
+}
+
+// PipeOption configures a PipeChannel (functional options pattern).
+type PipeOption func(*pipeConfig)
+
+// WithPipeContext stops the pipe when ctx is done. It also bounds a publish waiting
+// for room in a full subscriber channel, as with PublishContext.
+func WithPipeContext(ctx context.Context) PipeOption {
+	return func(c *pipeConfig) {
+		c.ctx = ctx
+	}
+}
+
+// Pipe is a running PipeChannel.
+type Pipe struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error // Why the pipe stopped, set before done is closed
+}
+
+// Stop stops the pipe and waits for it to exit. Values still in the source channel
+// are left there.
+func (p *Pipe) Stop() {
+	p.cancel()
+	<-p.done
+}
+
+// Done returns a channel closed once the pipe has stopped.
+func (p *Pipe) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err reports why the pipe stopped, once Done is closed: nil when the source channel
+// was closed, the context's error when it was done (context.Canceled after Stop), or
+// ErrTopicClosed.
+func (p *Pipe) Err() error {
+	<-p.done
+	return p.err
+}
+
+// PipeChannel publishes every value arriving on src to topic, in order, until src is
+// closed, the pipe's context is done (WithPipeContext, or Stop) or the topic is
+// closed, so code that already produces on a channel can feed the topic without a
+// glue goroutine of its own. A publish that fails is logged and the pipe carries on
+// with the next value.
+//
+// Go Concurrency Patterns used:
+//   - Pipeline stage: the pipe's goroutine drains one channel into the topic
+//   - Context cancellation: the context and the topic's done channel are selected
+//     together with src, so the pipe exits without waiting for another value
+//
+// Usage example:
+//
+//	readings := make(chan Reading)
+//	go sensor.Run(readings)
+//	pipe, err := pub.PipeChannel("readings", readings, WithPipeContext(ctx))
+//	if err != nil { ... }
+//	<-pipe.Done()
+func (p *Publisher[T]) PipeChannel(topic string, src <-chan T, opts ...PipeOption) (*Pipe, error) {
+	if err := p.authorizePublish(Anonymous, topic); err != nil {
+		return nil, err
+	}
+	settings := pipeConfig{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	state, ok := s.topics[topic]
+	if !ok {
+		return nil, errors.New("topic not found")
+	}
+	ctx, cancel := context.WithCancel(settings.ctx)
+	pipe := &Pipe{cancel: cancel, done: make(chan struct{})}
+	p.goroutines.Go(func() { // Started under the lock, so Shutdown waits for it
+		defer close(pipe.done)
+		defer cancel()
+		pipe.err = p.pipe(ctx, topic, src, state.done)
+	})
+	return pipe, nil
+}
+
+// pipe publishes the values of src to topic until src is closed, ctx is done or the
+// topic is closed (done), and returns why it stopped.
+func (p *Publisher[T]) pipe(ctx context.Context, topic string, src <-chan T, done <-chan struct{}) error {
+	for {
+		select {
+		case value, ok := <-src:
+			if !ok {
+				return nil
+			}
+			err := p.publish(ctx, topic, Message[T]{Value: value})
+			if errors.Is(err, ErrShutdown) {
+				return ErrTopicClosed
+			}
+			if err != nil {
+				p.config.logger.Warn("pubsub: pipe publish failed", "topic", topic, "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return ErrTopicClosed
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestPipeChannel tests that a pipe publishes a channel's values in order and stops
+// when the channel closes, its context is canceled or the topic is closed
+func TestPipeChannel(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(8))
+	pub.CreateTopic("numbers")
+	sub, _ := pub.Subscribe("numbers")
+
+	if _, err := pub.PipeChannel("missing", make(chan int)); err == nil {
+		t.Error("Expected an error for an unknown topic")
+	}
+
+	src := make(chan int)
+	pipe, err := pub.PipeChannel("numbers", src)
+	if err != nil {
+		t.Fatalf("PipeChannel() returned error: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		src <- i
+	}
+	close(src)
+	if err := pipe.Err(); err != nil {
+		t.Errorf("Expected nil after the source closed, got %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if got := <-sub; got != i {
+			t.Errorf("Expected %d, got %d", i, got)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pipe, _ = pub.PipeChannel("numbers", make(chan int), WithPipeContext(ctx))
+	cancel()
+	if err := pipe.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	pipe, _ = pub.PipeChannel("numbers", make(chan int))
+	pub.CloseTopic("numbers")
+	if err := pipe.Err(); !errors.Is(err, ErrTopicClosed) {
+		t.Errorf("Expected ErrTopicClosed, got %v", err)
+	}
+	pipe.Stop() // Stopping a stopped pipe returns right away
+}
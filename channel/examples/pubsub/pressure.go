@@ -0,0 +1,83 @@
+package main
+
+import "slices"
+
+// PressureInfo is a snapshot of how far a topic's subscribers are behind, so
+// publishers can slow down before subscribers start blocking them or dropping messages.
+type PressureInfo struct {
+	Subscribers    int    // Current number of subscribers
+	Backlog        int    // Unread messages summed over the subscribers
+	Capacity       int    // Buffer capacity summed over the subscribers
+	Slowest        uint64 // ID of the subscriber with the most unread messages (see SubscriberStats), 0 without subscribers
+	SlowestBacklog int    // Unread messages of that subscriber
+}
+
+// PressureEvent reports that a topic's backlog crossed one of the thresholds of
+// WithPressureThresholds.
+type PressureEvent struct {
+	Topic    string
+	Level    int // Number of thresholds the backlog is at or above, 0 when below all of them
+	Pressure PressureInfo
+}
+
+// WithPressureThresholds calls onChange whenever the topic's aggregate backlog
+// (PressureInfo.Backlog) crosses one of thresholds, upwards or downwards, so adaptive
+// producers can throttle instead of polling TopicPressure. The backlog is measured
+// after every publish to the topic, so a backlog that drains while nobody publishes
+// is reported with the next publish. onChange runs on the publishing goroutine and
+// must not block; concurrent publishers may report crossings slightly out of order.
+//
+// Usage example:
+//
+//	pub.CreateTopic("events", WithPressureThresholds(func(e PressureEvent) {
+//		producer.SetRate(baseRate >> e.Level) // Halve the rate per threshold crossed
+//	}, 100, 500, 1000))
+func WithPressureThresholds(onChange func(PressureEvent), thresholds ...int) TopicOption {
+	return func(c *topicConfig) {
+		c.onPressure = onChange
+		c.pressureThresholds = slices.Sorted(slices.Values(thresholds))
+	}
+}
+
+// TopicPressure returns the current backlog of topic's subscribers. An unknown topic
+// has no pressure: the zero PressureInfo is returned.
+func (p *Publisher[T]) TopicPressure(topic string) PressureInfo {
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.RLock()
+	defer s.RUnlock()
+	return pressureOf(s.subscribers[topic])
+}
+
+// pressureOf sums up the backlog of subs. Called with the shard's lock held.
+func pressureOf[T any](subs []*subscriber[T]) PressureInfo {
+	info := PressureInfo{Subscribers: len(subs)}
+	for _, sub := range subs {
+		pending := sub.pending()
+		info.Backlog += pending
+		info.Capacity += sub.buffered
+		if info.Slowest == 0 || pending > info.SlowestBacklog {
+			info.Slowest, info.SlowestBacklog = sub.id, pending
+		}
+	}
+	return info
+}
+
+// checkPressure measures the backlog of topic, whose state is watched with
+// WithPressureThresholds, and reports a change of level.
+func (p *Publisher[T]) checkPressure(topic string, state *topicState[T]) {
+	s := p.shard(topic)
+	s.RLock()
+	if s.topics[topic] != state {
+		s.RUnlock()
+		return // Closed or re-created meanwhile
+	}
+	info := pressureOf(s.subscribers[topic])
+	s.RUnlock()
+
+	thresholds := state.settings.pressureThresholds
+	level, _ := slices.BinarySearch(thresholds, info.Backlog+1) // Thresholds <= Backlog
+	if int(state.pressure.Swap(int32(level))) != level {
+		state.settings.onPressure(PressureEvent{Topic: topic, Level: level, Pressure: info})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestTopicPressure tests the backlog snapshot and the threshold callback
+func TestTopicPressure(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(8))
+	var levels []int
+	pub.CreateTopic("events", WithPressureThresholds(func(e PressureEvent) {
+		levels = append(levels, e.Level)
+	}, 4, 2))
+	fast, _ := pub.Subscribe("events")
+	slow, _ := pub.Subscribe("events")
+
+	for i := range 5 {
+		pub.Publish("events", i)
+		<-fast
+	}
+	info := pub.TopicPressure("events")
+	stats, _ := pub.SubscriberStats("events")
+	if info.Subscribers != 2 || info.Backlog != 5 || info.Capacity != 16 || info.Slowest != stats[1].ID || info.SlowestBacklog != 5 {
+		t.Errorf("Unexpected pressure %+v", info)
+	}
+	if !slices.Equal(levels, []int{1, 2}) {
+		t.Errorf("Expected levels [1 2] while the backlog grew, got %v", levels)
+	}
+
+	for range 5 {
+		<-slow
+	}
+	pub.Publish("events", 5) // One unread message per subscriber
+	if !slices.Equal(levels, []int{1, 2, 1}) {
+		t.Errorf("Expected level 1 once drained, got %v", levels)
+	}
+	if info := pub.TopicPressure("missing"); info != (PressureInfo{}) {
+		t.Errorf("Expected no pressure for an unknown topic, got %+v", info)
+	}
+}
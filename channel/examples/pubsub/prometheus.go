@@ -0,0 +1,113 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Gauges among the values of expvarSnapshot; the others are counters.
+var promGauges = map[string]bool{"topics": true, "subscribers": true, "pending": true}
+
+// Descriptions of the per-topic and per-subscriber metrics.
+var (
+	promTopicPublished = prometheus.NewDesc("pubsub_topic_published_total",
+		"Messages published to the topic.", []string{"namespace", "topic"}, nil)
+	promTopicDelivered = prometheus.NewDesc("pubsub_topic_delivered_total",
+		"Messages handed to the topic's subscribers, one per subscriber.", []string{"namespace", "topic"}, nil)
+	promTopicDropped = prometheus.NewDesc("pubsub_topic_dropped_total",
+		"Messages discarded by the overflow policies of the topic's subscribers.", []string{"namespace", "topic"}, nil)
+	promTopicSubscribers = prometheus.NewDesc("pubsub_topic_subscribers",
+		"Current number of subscribers of the topic.", []string{"namespace", "topic"}, nil)
+	promTopicBacklog = prometheus.NewDesc("pubsub_topic_max_backlog",
+		"Unread messages of the topic's subscriber furthest behind.", []string{"namespace", "topic"}, nil)
+	promSubscriberPending = prometheus.NewDesc("pubsub_subscriber_pending",
+		"Messages buffered for the subscriber, not read yet.", []string{"namespace", "topic", "subscriber"}, nil)
+	promSubscriberDelivered = prometheus.NewDesc("pubsub_subscriber_delivered_total",
+		"Messages delivered to the subscriber.", []string{"namespace", "topic", "subscriber"}, nil)
+	promSubscriberBlocked = prometheus.NewDesc("pubsub_subscriber_blocked_seconds_total",
+		"Time publishers waited for room in the subscriber's channel.", []string{"namespace", "topic", "subscriber"}, nil)
+)
+
+// publisherCollector is the prometheus.Collector returned by Collector.
+type publisherCollector[T any] struct {
+	p     *Publisher[T]
+	descs map[string]*prometheus.Desc // Publisher-wide metrics, by expvarSnapshot key
+}
+
+// Collector returns a prometheus.Collector exporting the same values as PublishExpvar:
+// the Publisher-wide counters and gauges (pubsub_published_total, pubsub_topics, ...),
+// the per-topic ones (pubsub_topic_*) and the per-subscriber ones (pubsub_subscriber_*),
+// labeled with the namespace path (empty for the root), the topic and the subscriber
+// id. The Publisher's namespaces (see Namespace) are included. Values are read when
+// Prometheus scrapes, so registering the collector costs nothing between scrapes.
+//
+// Usage example:
+//
+//	prometheus.MustRegister(pub.Collector())
+//	http.Handle("/metrics", promhttp.Handler())
+func (p *Publisher[T]) Collector() prometheus.Collector {
+	c := &publisherCollector[T]{p: p, descs: make(map[string]*prometheus.Desc)}
+	for name := range p.expvarSnapshot() {
+		metric, help := "pubsub_"+name+"_total", "Publisher counter "+name+" (see PublishExpvar)."
+		if promGauges[name] {
+			metric, help = "pubsub_"+name, "Publisher gauge "+name+" (see PublishExpvar)."
+		}
+		c.descs[name] = prometheus.NewDesc(metric, help, []string{"namespace"}, nil)
+	}
+	return c
+}
+
+// Describe sends the descriptions of every metric the collector exports.
+func (c *publisherCollector[T]) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.descs {
+		ch <- desc
+	}
+	for _, desc := range []*prometheus.Desc{
+		promTopicPublished, promTopicDelivered, promTopicDropped, promTopicSubscribers, promTopicBacklog,
+		promSubscriberPending, promSubscriberDelivered, promSubscriberBlocked,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect sends the current values of the Publisher and of its namespaces.
+func (c *publisherCollector[T]) Collect(ch chan<- prometheus.Metric) {
+	c.collect(ch, c.p)
+}
+
+// collect sends the values of p, then those of its namespaces, recursively.
+func (c *publisherCollector[T]) collect(ch chan<- prometheus.Metric, p *Publisher[T]) {
+	ns := p.namespace
+	for name, value := range p.expvarSnapshot() {
+		kind := prometheus.CounterValue
+		if promGauges[name] {
+			kind = prometheus.GaugeValue
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs[name], kind, float64(value), ns)
+	}
+	p.eachTopic(func(topic string, state *topicState[T], subs []*subscriber[T]) {
+		stats := topicStatsOf(state, subs)
+		ch <- prometheus.MustNewConstMetric(promTopicPublished, prometheus.CounterValue, float64(stats.Published), ns, topic)
+		ch <- prometheus.MustNewConstMetric(promTopicDelivered, prometheus.CounterValue, float64(stats.Delivered), ns, topic)
+		ch <- prometheus.MustNewConstMetric(promTopicDropped, prometheus.CounterValue, float64(stats.Dropped), ns, topic)
+		ch <- prometheus.MustNewConstMetric(promTopicSubscribers, prometheus.GaugeValue, float64(stats.Subscribers), ns, topic)
+		ch <- prometheus.MustNewConstMetric(promTopicBacklog, prometheus.GaugeValue, float64(stats.MaxBacklog), ns, topic)
+		for _, sub := range subs {
+			s := sub.stats()
+			id := strconv.FormatUint(s.ID, 10)
+			ch <- prometheus.MustNewConstMetric(promSubscriberPending, prometheus.GaugeValue, float64(s.Pending), ns, topic, id)
+			ch <- prometheus.MustNewConstMetric(promSubscriberDelivered, prometheus.CounterValue, float64(s.Delivered), ns, topic, id)
+			ch <- prometheus.MustNewConstMetric(promSubscriberBlocked, prometheus.CounterValue, s.Blocked.Seconds(), ns, topic, id)
+		}
+	})
+
+	p.RLock()
+	children := slices.Collect(maps.Values(p.namespaces))
+	p.RUnlock()
+	for _, child := range children {
+		c.collect(ch, child)
+	}
+}
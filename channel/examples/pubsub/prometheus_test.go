@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestCollector tests that a Prometheus registry gathers the Publisher's metrics,
+// those of its namespaces included
+func TestCollector(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4))
+	pub.CreateTopic("orders")
+	pub.Subscribe("orders")
+	pub.Publish("orders", "o1")
+	acme := namespace(t, pub, "acme")
+	acme.CreateTopic("orders")
+	acme.Publish("orders", "a1")
+
+	reg := prometheus.NewPedanticRegistry() // Also checks Collect against Describe
+	reg.MustRegister(pub.Collector())
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() returned error: %v", err)
+	}
+
+	values := make(map[string]float64) // name{namespace} -> summed value
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "namespace" {
+					key += "{" + label.GetValue() + "}"
+				}
+			}
+			values[key] += m.GetCounter().GetValue() + m.GetGauge().GetValue()
+		}
+	}
+	want := map[string]float64{
+		"pubsub_published_total{}":            1,
+		"pubsub_published_total{acme}":        1,
+		"pubsub_topics{}":                     1,
+		"pubsub_topic_published_total{}":      1,
+		"pubsub_topic_published_total{acme}":  1,
+		"pubsub_topic_subscribers{}":          1,
+		"pubsub_subscriber_pending{}":         1,
+		"pubsub_subscriber_delivered_total{}": 1,
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("Expected %s = %v, got %v", key, value, values[key])
+		}
+	}
+}
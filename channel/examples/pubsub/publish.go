@@ -1,6 +1,10 @@
 package main
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // Publish sends a message to all subscribers of a specific topic.
 // This implements the broadcast pattern where one message is delivered to multiple subscribers.
@@ -19,27 +23,326 @@ import "errors"
 //
 // Parameters:
 //   - topic: string - the topic name to publish to
-//   - message: string - the message content to broadcast
+//   - message: T - the message content to broadcast
 //
 // Returns:
-//   - error: returns error if topic doesn't exist
+//   - error: returns error if topic doesn't exist, ErrUnauthorized (see below), or
+//     ErrSubscriberFull (wrapped) when an OverflowError subscriber had no room
 //
 // Note: If a subscriber's channel is full, the send operation will block until space is available.
 // This is a design choice - it ensures no messages are lost, but may slow down publishers.
-func (p *Publisher) Publish(topic string, message string) error {
-	p.RLock()         // Acquire read lock (allows concurrent reads, blocks writes)
-	defer p.RUnlock() // Ensure lock is released
+// Subscribers created with WithOverflow drop messages (or make Publish return
+// ErrSubscriberFull) instead of blocking. With WithAsyncDelivery, Publish only queues
+// the message and returns ErrQueueFull or ErrShutdown instead.
+//
+// When an Authorizer is configured, Publish acts as the Anonymous principal; use PublishAs
+// to publish on behalf of a specific user.
+func (p *Publisher[T]) Publish(topic string, message T) error {
+	return p.PublishAs(Anonymous, topic, message)
+}
+
+// PublishContext is Publish with a deadline on the broadcast: if ctx is done while
+// the message waits for room in a full subscriber channel, the broadcast stops there
+// and PublishContext returns a *PublishCanceledError listing the subscribers that
+// did not receive the message. Subscribers served before that keep it, and with a
+// store the message stays in the topic log. Bounded-latency publishers use this to
+// give up on slow subscribers instead of waiting forever.
+//
+// If ctx is already done, nothing is published and ctx.Err() is returned. With
+// WithAsyncDelivery the message is only queued, and ctx is not consulted after that.
+func (p *Publisher[T]) PublishContext(ctx context.Context, topic string, message T) error {
+	if err := p.authorizePublish(Anonymous, topic); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.publish(ctx, topic, Message[T]{Value: message})
+}
+
+// PublishCanceledError is returned by PublishContext when ctx is done in the middle of
+// a broadcast. Subscribers are identified by the channels they were handed, so callers
+// can compare them with ==.
+type PublishCanceledError[T any] struct {
+	Skipped     []<-chan T          // Plain subscribers (Subscribe, SubscribeFrom, SubscribeSince)
+	SkippedLogs []<-chan Message[T] // Log subscribers, by Subscription.C
+	Err         error               // ctx.Err()
+}
+
+func (e *PublishCanceledError[T]) Error() string {
+	return fmt.Sprintf("publish canceled, %d subscribers skipped: %v", len(e.Skipped)+len(e.SkippedLogs), e.Err)
+}
+
+func (e *PublishCanceledError[T]) Unwrap() error { return e.Err }
+
+// publish broadcasts msg to the topic's subscribers once authorization has passed,
+// either right away or, with WithAsyncDelivery, by handing it to a delivery worker.
+func (p *Publisher[T]) publish(ctx context.Context, topic string, msg Message[T]) error {
+	if p.stopping.Load() {
+		return ErrShutdown
+	}
+	topic = p.resolve(topic) // See AliasTopic
+	p.stamp(topic, &msg)
+	if chain := p.middleware.Load(); chain != nil {
+		return chain.wrap(p.dispatch)(ctx, topic, msg)
+	}
+	return p.dispatch(ctx, topic, msg)
+}
+
+// dispatch validates msg (see SetValidator), then delivers it right away or queues it
+// for a delivery worker.
+func (p *Publisher[T]) dispatch(ctx context.Context, topic string, msg Message[T]) error {
+	if err := p.validate(topic, msg); err != nil {
+		return err
+	}
+	if p.async != nil && inlineFrom[T](ctx) == nil {
+		return p.enqueue(topic, msg)
+	}
+	return p.deliver(ctx, topic, msg)
+}
+
+// deliver broadcasts msg to the topic's subscribers, giving up when ctx is done while
+// waiting on a full subscriber. Plain subscribers receive msg.Value (nothing for
+// deletes); envelope subscribers receive msg itself, with Offset filled in from the
+// store. Subscribers found stalled (WithSlowSubscriberEviction) are evicted once
+// the read lock is released.
+func (p *Publisher[T]) deliver(ctx context.Context, topic string, msg Message[T]) error {
+	var slow []*subscriber[T]
+	var routed []string
+	var watched *topicState[T]   // Topic whose backlog is measured afterwards (WithPressureThresholds)
+	inline := inlineFrom[T](ctx) // PublishSync in progress, nil otherwise
+
+	// Runs after RUnlock: eviction needs the write lock, handlers, forwarding and
+	// pressure checks the read lock
+	defer func() {
+		p.evict(topic, slow)
+		if watched != nil {
+			p.checkPressure(topic, watched)
+		}
+		inline.run(p, topic, msg)
+		p.forward(ctx, routed, msg)
+	}()
+
+	s := p.shard(topic)
+	s.RLock()         // Acquire the shard's read lock (allows concurrent reads, blocks writes)
+	defer s.RUnlock() // Ensure lock is released
 
 	// Get list of subscribers for this topic
-	subscriber, ok := p.subscribers[topic]
+	subscriber, ok := s.subscribers[topic]
 	if !ok {
 		return errors.New("topic not found")
 	}
 
+	// A message that waited past its TTL (in the async queue, say) is not published
+	state := s.topics[topic]
+	if held, err := p.holdLocked(ctx, state, msg); held {
+		return err // Delivered by ResumeTopic, or rejected
+	}
+	if state.settings.onPressure != nil {
+		watched = state
+	}
+	p.stampExpiry(state, &msg)
+	if _, live := p.timeLeft(msg); !live {
+		for _, sub := range subscriber {
+			p.expire(topic, sub, msg)
+		}
+		return ErrExpired
+	}
+
+	// With a store, the message is appended to the topic log before it is delivered.
+	// Publishes to one topic serialize here so log order equals delivery order (and the
+	// retained message is the last one delivered).
+	if p.config.store != nil || state.settings.retain || state.history != nil {
+		state.publishMu.Lock()
+		defer state.publishMu.Unlock()
+	}
+	if p.config.store != nil {
+		if err := p.appendToStore(topic, &msg); err != nil {
+			return err
+		}
+	}
+	if state.history != nil && !msg.Deleted {
+		state.history.push(msg)
+	}
+	if state.settings.retain {
+		if msg.Deleted {
+			state.retained = nil
+		} else {
+			state.retained = &msg
+		}
+	}
+
+	p.metrics.published.Add(1)
+	state.counters.published.Add(1)
+	routed = p.matchRoutes(topic, msg) // Forwarded to other topics (AddRoute) afterwards
+
+	// One trace task per publish, one region per delivery (visible in `go tool trace`)
+	ctx, endTask := traceTask(ctx, "pubsub.Publish", topic)
+	defer endTask()
+
 	// Broadcast message to all subscribers (fan-out pattern)
 	// Each subscriber receives the message through their dedicated channel
-	for _, ch := range subscriber {
-		ch <- message // Send message to subscriber's channel
+	full := 0 // Subscribers with OverflowError that had no room
+	var canceled *PublishCanceledError[T]
+	var parallel *parallelSends[T] // Sends in flight (WithDeliveryConcurrency), nil when sent one at a time
+	if state.parallel > 1 && p.async != nil && inline == nil {
+		parallel = p.newParallelSends(ctx, topic, msg, state.parallel, len(subscriber))
+	}
+	picked := p.pickQueueMembers(state, subscriber, msg) // One member per queue group gets msg
+	for i, sub := range subscriber {
+		if sub.queue != "" && picked[sub.queue] != sub {
+			continue
+		}
+		// Subscribers with a delivery quota skip messages beyond their rate
+		if sub.limited && !p.limiter.Allow(sub.id) {
+			p.metrics.rateLimited.Add(1)
+			continue
+		}
+		if _, live := p.timeLeft(msg); !live {
+			p.expire(topic, sub, msg)
+			continue
+		}
+		if !sub.dedup.admit(msg.ID) {
+			p.metrics.deduplicated.Add(1)
+			continue
+		}
+		if msg.Deleted && sub.msgs == nil {
+			continue // Plain subscribers receive nothing for deletes
+		}
+		if inline != nil && sub.handler != nil {
+			if !msg.Deleted {
+				inline.handlers = append(inline.handlers, sub) // Called once the lock is released
+				sub.delivered.Add(1)
+				p.metrics.delivered.Add(1)
+				state.counters.delivered.Add(1)
+			}
+			continue
+		}
+		if parallel != nil {
+			parallel.start(i, sub) // Settled below, once every send has returned
+			continue
+		}
+		r, err := p.sendVia(ctx, topic, sub, msg)
+		if p.settleLocked(topic, state, sub, msg, r, err, &full, &slow) {
+			// Only a blocking send gives up without delivering: ctx is done
+			canceled = &PublishCanceledError[T]{Err: ctx.Err()}
+			for _, skipped := range subscriber[i:] {
+				canceled.add(skipped)
+				p.deadLetter(topic, skipped, msg, ctx.Err())
+			}
+			break
+		}
+	}
+	if parallel != nil {
+		for _, sent := range parallel.wait() {
+			if sent.sub == nil {
+				continue // Skipped by the loop above
+			}
+			if p.settleLocked(topic, state, sent.sub, msg, sent.r, sent.err, &full, &slow) {
+				// The other sends were not skipped: only the canceled ones are reported
+				if canceled == nil {
+					canceled = &PublishCanceledError[T]{Err: ctx.Err()}
+				}
+				canceled.add(sent.sub)
+				p.deadLetter(topic, sent.sub, msg, ctx.Err())
+			}
+		}
+	}
+	if canceled != nil {
+		return canceled
+	}
+	if full > 0 {
+		return fmt.Errorf("%w: %d of %d subscribers of %q", ErrSubscriberFull, full, len(subscriber), topic)
 	}
 	return nil
 }
+
+// settleLocked accounts for the send of msg to sub that returned r and err: it counts
+// the delivery or the drop, dead-letters what was not delivered, and adds sub to slow
+// when it stalled (WithSlowSubscriberEviction). A subscriber with OverflowError that had
+// no room adds to full. Returns true, without dead-lettering msg, when a blocking send
+// gave up because ctx is done. Called with the read lock of topic's shard held.
+func (p *Publisher[T]) settleLocked(topic string, state *topicState[T], sub *subscriber[T], msg Message[T], r offered[Message[T]], err error, full *int, slow *[]*subscriber[T]) (canceled bool) {
+	if err != nil { // Rejected by a delivery middleware
+		sub.dedup.release(msg.ID)
+		p.deadLetter(topic, sub, msg, err)
+		return false
+	}
+	if sub.catchUp {
+		if !r.delivered {
+			sub.dedup.release(msg.ID)
+			return false
+		}
+	} else {
+		delivered := r.delivered
+		if !delivered {
+			sub.dedup.release(msg.ID)
+		}
+		sub.blocked.Add(int64(r.blocked))
+		p.metrics.dropped.Add(int64(len(r.evicted)))
+		state.counters.dropped.Add(int64(len(r.evicted)))
+		for _, old := range r.evicted {
+			p.deadLetter(topic, sub, old, ErrDropped)
+		}
+		if _, live := p.timeLeft(msg); r.stalled && !live {
+			p.expire(topic, sub, msg) // Waited out its TTL, not the stall limit
+			return false
+		}
+		if p.stalled(sub, r.delivered, r.stalled) {
+			*slow = append(*slow, sub)
+			if !delivered {
+				p.deadLetter(topic, sub, msg, ErrSlowSubscriber)
+				return false
+			}
+		}
+		if !delivered && sub.overflow == OverflowBlock {
+			return true
+		}
+		if !delivered {
+			p.metrics.dropped.Add(1)
+			state.counters.dropped.Add(1)
+			reason := ErrDropped
+			if sub.overflow == OverflowError {
+				*full++
+				reason = ErrSubscriberFull
+			}
+			p.deadLetter(topic, sub, msg, reason)
+			return false
+		}
+	}
+	sub.delivered.Add(1)
+	p.metrics.delivered.Add(1)
+	state.counters.delivered.Add(1)
+	return false
+}
+
+// send hands msg to sub according to its overflow policy.
+func (p *Publisher[T]) send(ctx context.Context, sub *subscriber[T], msg Message[T]) (r offered[Message[T]]) {
+	if sub.catchUp {
+		r.delivered = p.deliverLive(sub, msg)
+		return r
+	}
+	traceRegion(ctx, "pubsub.deliver", func() {
+		if sub.msgs != nil {
+			// Log subscribers get offset, key and tombstones too
+			r = offer(ctx, sub.msgs, msg, sub.overflow, p.config.clock, p.waitLimit(msg))
+			return
+		}
+		// Send message to subscriber's channel
+		plain := offer(ctx, sub.ch, msg.Value, sub.overflow, p.config.clock, p.waitLimit(msg))
+		r = offered[Message[T]]{delivered: plain.delivered, blocked: plain.blocked, stalled: plain.stalled}
+		for _, value := range plain.evicted {
+			r.evicted = append(r.evicted, Message[T]{Value: value})
+		}
+	})
+	return r
+}
+
+// add records sub as skipped by a canceled publish.
+func (e *PublishCanceledError[T]) add(sub *subscriber[T]) {
+	if sub.msgs != nil {
+		e.SkippedLogs = append(e.SkippedLogs, sub.outLog)
+	} else {
+		e.Skipped = append(e.Skipped, sub.out)
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
-import "sync"
+import (
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
 
 // Publisher implements the Publisher-Subscriber (Pub/Sub) pattern using Go channels.
 // This is a concurrent-safe message broker that allows multiple publishers to send
@@ -9,23 +13,134 @@ import "sync"
 // Go Concurrency Patterns used:
 //   - Channel-based communication: Uses channels for message passing between goroutines
 //   - RWMutex: Read-Write mutex for efficient concurrent access (multiple readers, single writer)
+//   - Lock striping: topics are spread over shards with a lock each (see WithTopicShards)
 //   - Thread-safe map: Protects shared state (subscribers map) from race conditions
 //
 // Architecture:
-//   - Each topic maintains a slice of channels (one per subscriber)
+//   - Each topic maintains a slice of subscribers, each owning one channel
 //   - When a message is published, it's sent to all subscriber channels (broadcast pattern)
 //   - Subscribers receive messages through their dedicated channel
-type Publisher struct {
-	sync.RWMutex                          // Protects subscribers map from concurrent access
-	subscribers  map[string][]chan string // Topic -> list of subscriber channels
+//
+// T is the message payload type: a Publisher[string] carries text, a Publisher of a
+// struct type carries domain events. Payloads are handed to subscribers as they were
+// published (no copy), and encoded with the configured Codec only when a store is used.
+type Publisher[T any] struct {
+	sync.RWMutex                                    // Protects the Publisher-wide settings below (namespaces, middleware and routes changes)
+	shards       []topicShard[T]                    // Topics and their subscribers, spread by topic hash (see shard)
+	shardSeed    maphash.Seed                       // Hash seed of shard
+	metrics      metrics                            // Counters exported via PublishExpvar
+	config       config                             // Settings applied by NewPublisher options
+	limiter      *KeyedLimiter[uint64]              // Per-subscriber delivery quotas, keyed by subscriber id
+	nextID       atomic.Uint64                      // Last subscriber id handed out
+	namespace    string                             // Full namespace path, empty for the root Publisher
+	limits       atomic.Pointer[namespaceLimits]    // Tenant limits set by Namespace options
+	topicCount   atomic.Int64                       // Open topics, counted against the namespace limit
+	buffered     atomic.Int64                       // Subscriber buffer capacity in use
+	namespaces   map[string]*Publisher[T]           // Child namespaces by name (guarded by the write lock)
+	durables     map[string]bool                    // Open durable subscriptions (SubscribeDurable), guarded by the write lock
+	async        *asyncDelivery[T]                  // Worker pool (WithAsyncDelivery), nil when Publish delivers itself
+	deadLetters  *Publisher[DeadLetter]             // Dead-letter topic (WithDeadLetters), nil when off
+	idPrefix     string                             // Random prefix of the message IDs Publish assigns
+	idSeq        atomic.Uint64                      // Last message ID sequence number handed out
+	routes       atomic.Pointer[routeTable[T]]      // Source topic -> routes (AddRoute), replaced under the write lock
+	aliases      atomic.Pointer[aliasTable]         // Alias -> topic (AliasTopic, RenameTopic), replaced under the write lock
+	middleware   atomic.Pointer[middlewareChain[T]] // Installed by Use, nil when none
+	hooks        atomic.Pointer[lifecycleHooks]     // Lifecycle callbacks (OnTopicCreated, ...), replaced under the write lock
+	stopping     atomic.Bool                        // Set by Shutdown, rejects further publishes
+	stopOnce     sync.Once                          // Starts the shutdown sequence once
+	stopped      chan struct{}                      // Closed when the shutdown sequence is over (set by stopOnce)
+	goroutines   sync.WaitGroup                     // Pumps, ack loops and topic loops, awaited by Shutdown
+}
+
+// topicState holds per-topic state that is not a subscriber list.
+type topicState[T any] struct {
+	publishMu sync.Mutex        // Serializes publishes so store order matches delivery order
+	settings  topicConfig       // Options given to CreateTopic
+	done      chan struct{}     // Closed by CloseTopic, stops the topic's background goroutines
+	retained  *Message[T]       // Last published message (WithRetain), guarded by publishMu
+	history   *ring[Message[T]] // Last published messages (WithReplay), guarded by publishMu
+	queues    queueTurns        // Turn counters of the queue groups (WithQueueGroup), guarded by the shard's write lock
+	counters  topicCounters     // Per-topic counters reported by Stats
+	validate  func(T) error     // Schema check (SetValidator), nil when none; guarded by the shard's lock
+	paused    *pauseState[T]    // Set while the topic is paused (PauseTopic); guarded by the shard's lock
+	snapshot  func() []T        // State provider for new subscribers (SetSnapshotProvider), nil when none; guarded by the shard's lock
+	pressure  atomic.Int32      // Thresholds the backlog was at or above when last measured (WithPressureThresholds)
+	parallel  int               // Subscribers a delivery worker sends a message to at once (WithDeliveryConcurrency)
+}
+
+// subscriber is one registered receiver of a topic.
+//
+// Subscribers are not recycled through a sync.Pool: eviction and Subscription.Close
+// find a subscriber again by pointer after retaking the shard lock, so a recycled one
+// could be mistaken for the subscriber that used to sit at its address.
+type subscriber[T any] struct {
+	id        uint64            // Unique per Publisher, used as the limiter key
+	ch        chan T            // Channel the publisher delivers to (plain subscribers)
+	msgs      chan Message[T]   // Channel the publisher delivers to (log subscribers, see SubscribeLog)
+	out       <-chan T          // Channel handed to the caller (ch, unless a replay pump sits in between)
+	outLog    <-chan Message[T] // Channel handed to log subscribers (Subscription.C)
+	done      chan struct{}     // Closed when the subscriber is removed, stops the replay pump
+	limited   bool              // Delivery quota installed in the Publisher's limiter
+	buffered  int               // Buffer capacity counted against the namespace limit
+	catchUp   bool              // Catch-up mode (WithCatchUp): never block the publisher on msgs
+	lagging   atomic.Bool       // Catch-up subscriber is reading from the store, skip live delivery
+	credits   *creditGate       // Credit-based flow control (WithCredits), nil when off
+	overflow  Overflow          // What Publish does when ch or msgs is full (WithOverflow)
+	handler   func(T)           // Handler of a handler subscription (SubscribeFunc), called inline by PublishSync
+	delivered atomic.Int64      // Messages delivered (SubscriberStats)
+	blocked   atomic.Int64      // Nanoseconds publishers waited for room (SubscriberStats)
+	fullSince atomic.Int64      // Clock time in Unix nanoseconds since the channel is full, 0 when not
+	queue     string            // Queue group (WithQueueGroup), empty when it gets every message
+	dedup     *dedupWindow      // Recently delivered message IDs (WithDedup), nil when off
+	shared    *sharedChannel    // Subscribers sharing msgs (SubscribeMany), nil when msgs is its own
+}
+
+// close stops delivery to the subscriber. Must be called with the shard's write lock held.
+func (s *subscriber[T]) close() {
+	if s.shared != nil && s.shared.members.Add(-1) > 0 {
+		// Other topics of a SubscribeMany still deliver into msgs
+	} else if s.msgs != nil {
+		close(s.msgs)
+	} else {
+		close(s.ch)
+	}
+	if s.done != nil {
+		close(s.done)
+	}
 }
 
 // NewPublisher creates and returns a new Publisher instance.
 // Initializes the subscribers map to store topic-channel mappings.
 //
-// Returns: *Publisher - pointer to the newly created Publisher
-func NewPublisher() *Publisher {
-	return &Publisher{
-		subscribers: make(map[string][]chan string),
+// Parameters:
+//   - opts: ...Option - optional settings (WithDefaultBuffer, WithClock, WithLogger, WithMetrics, WithStore, ...)
+//
+// Returns: *Publisher[T] - pointer to the newly created Publisher
+func NewPublisher[T any](opts ...Option) *Publisher[T] {
+	return newPublisher[T](newConfig(opts))
+}
+
+// newPublisher builds a Publisher from a complete configuration.
+func newPublisher[T any](cfg config) *Publisher[T] {
+	p := &Publisher[T]{
+		shards:    newShards[T](cfg.shards),
+		shardSeed: maphash.MakeSeed(),
+		config:    cfg,
+		idPrefix:  newIDPrefix(),
+	}
+	p.limits.Store(&namespaceLimits{})
+	p.limiter = NewKeyedLimiter[uint64](p.config.clock)
+	if p.config.asyncWorkers > 0 {
+		p.startAsync()
+	}
+	if p.config.deadLetters {
+		p.startDeadLetters()
+	}
+	if p.config.tracer != nil {
+		p.Use(tracing[T](p.config.tracer, p.config.propagator))
+	}
+	if p.config.metricsName != "" {
+		p.PublishExpvar(p.config.metricsName)
 	}
+	return p
 }
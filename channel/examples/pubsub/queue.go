@@ -0,0 +1,82 @@
+package main
+
+import "sync/atomic"
+
+// WithQueueGroup makes the subscriber a member of the queue group group: each message
+// published to the topic reaches exactly one member of the group, so the members share
+// the load like workers reading from a queue. Other subscribers, including members of
+// other queue groups, still receive their own copy.
+//
+// Members take turns (round robin), but a member whose channel is full is passed over
+// for the next one with room, so a busy worker does not hold up the group. When every
+// member is full, the member whose turn it is gets the message and its overflow policy
+// applies. A member whose rate limit (WithRateLimit) is exhausted misses its turn.
+// On a partitioned topic (WithPartitions), keyed messages go to the member owning
+// their key's partition instead.
+//
+// Replayed and retained messages are not shared: every member receives its own backlog.
+// With SubscribeGroup, pass the same name to share the live messages of a consumer group:
+//
+//	sub, err := pub.SubscribeGroup("orders", "billing", WithQueueGroup("billing"))
+func WithQueueGroup(group string) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.queueGroup = group
+	}
+}
+
+// queueTurns maps each queue group of a topic to the number of messages it has taken,
+// which picks the member whose turn it is.
+type queueTurns map[string]*atomic.Uint64
+
+// joinQueueLocked registers sub's queue group, if any, with the topic of state.
+// Must be called with the shard's write lock held.
+func (p *Publisher[T]) joinQueueLocked(state *topicState[T], sub *subscriber[T]) {
+	if sub.queue == "" {
+		return
+	}
+	if state.queues == nil {
+		state.queues = make(queueTurns)
+	}
+	if _, ok := state.queues[sub.queue]; !ok {
+		state.queues[sub.queue] = new(atomic.Uint64)
+	}
+}
+
+// pickQueueMembers returns the member of each queue group of the topic that gets msg,
+// or nil if the topic has no queue groups. Called with the shard's read lock held.
+func (p *Publisher[T]) pickQueueMembers(state *topicState[T], subscribers []*subscriber[T], msg Message[T]) map[string]*subscriber[T] {
+	if len(state.queues) == 0 {
+		return nil
+	}
+	members := make(map[string][]*subscriber[T], len(state.queues))
+	for _, sub := range subscribers {
+		if sub.queue != "" {
+			members[sub.queue] = append(members[sub.queue], sub)
+		}
+	}
+	picked := make(map[string]*subscriber[T], len(members))
+	partition, keyed := state.partition(msg)
+	for group, m := range members {
+		if keyed {
+			picked[group] = m[partition%len(m)] // The member owning the key's partition
+			continue
+		}
+		turn := int(state.queues[group].Add(1)-1) % len(m)
+		picked[group] = m[turn]
+		for i := range m {
+			if next := m[(turn+i)%len(m)]; next.hasRoom() {
+				picked[group] = next
+				break
+			}
+		}
+	}
+	return picked
+}
+
+// hasRoom reports whether the subscriber's channel can take a message without blocking.
+func (s *subscriber[T]) hasRoom() bool {
+	if s.msgs != nil {
+		return len(s.msgs) < cap(s.msgs)
+	}
+	return len(s.ch) < cap(s.ch)
+}
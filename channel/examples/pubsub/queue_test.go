@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+// TestQueueGroup tests that each message reaches one member of a queue group, while
+// other groups and plain subscribers get every message
+func TestQueueGroup(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(8))
+	pub.CreateTopic("jobs")
+	w1, _ := pub.Subscribe("jobs", WithQueueGroup("workers"))
+	w2, _ := pub.Subscribe("jobs", WithQueueGroup("workers"))
+	audit, _ := pub.Subscribe("jobs", WithQueueGroup("audit"))
+	plain, _ := pub.Subscribe("jobs")
+
+	for i := range 6 {
+		pub.Publish("jobs", i)
+	}
+	if len(w1) != 3 || len(w2) != 3 {
+		t.Errorf("Expected the workers to share 6 messages evenly, got %d and %d", len(w1), len(w2))
+	}
+	if len(audit) != 6 || len(plain) != 6 {
+		t.Errorf("Expected 6 messages for the audit group and the plain subscriber, got %d and %d", len(audit), len(plain))
+	}
+	seen := map[int]bool{}
+	for range 3 {
+		seen[<-w1] = true
+		seen[<-w2] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("Expected every message exactly once across the workers, got %v", seen)
+	}
+}
+
+// TestQueueGroupSkipsFullMember tests that a member with a full channel is passed over
+func TestQueueGroupSkipsFullMember(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(1))
+	pub.CreateTopic("jobs")
+	busy, _ := pub.Subscribe("jobs", WithQueueGroup("workers"))
+	idle, _ := pub.Subscribe("jobs", WithQueueGroup("workers"))
+
+	pub.Publish("jobs", 1) // busy's turn
+	pub.Publish("jobs", 2) // idle's turn
+	<-idle
+	pub.Publish("jobs", 3) // busy's turn, but its channel is full
+	if msg := <-idle; msg != 3 {
+		t.Errorf("Expected 3 to go to the idle member, got %d", msg)
+	}
+	if len(busy) != 1 {
+		t.Errorf("Expected only 1 message for the busy member, got %d", len(busy))
+	}
+}
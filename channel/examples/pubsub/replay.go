@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoStore is returned by replay operations on a Publisher created without WithStore.
+var ErrNoStore = errors.New("publisher has no store")
+
+// SubscribeFrom subscribes to topic starting at offset in the topic's stored log:
+// the subscriber first receives every stored message with an offset >= offset, then
+// the live stream, without gaps or duplicates in between. Offsets start at 0, so
+// SubscribeFrom(topic, 0) replays the whole retained log (including messages stored
+// by a previous process when the store is persistent, e.g. a FileStore).
+//
+// Go Concurrency Patterns used:
+//   - Write lock as a barrier: reading the backlog and registering the live subscriber
+//     happen under the exclusive lock, so no publish (which holds the read lock for the
+//     whole append+deliver) can fall between the two
+//   - Pump goroutine: forwards the backlog and then the live channel to the caller's
+//     channel, so a large backlog never blocks the lock holder
+//   - Done channel: closing the subscriber stops the pump even if nobody reads anymore
+//
+// Parameters:
+//   - topic: string - the topic name to subscribe to
+//   - offset: uint64 - first offset to deliver
+//   - opts: ...SubscribeOption - per-subscription settings, applied to live messages
+//
+// Returns:
+//   - <-chan T: receive-only channel, usable with CloseSubscriber like Subscribe's
+//   - error: ErrNoStore, "topic not found", ErrUnauthorized (wrapped), or a store error
+func (p *Publisher[T]) SubscribeFrom(topic string, offset uint64, opts ...SubscribeOption) (<-chan T, error) {
+	return p.subscribeReplay(topic, offset, func(Record) bool { return true }, opts)
+}
+
+// SubscribeSince subscribes to topic, first replaying every stored message published
+// strictly after t, then switching to live delivery. The hand-off is the same as
+// SubscribeFrom's: a message is either in the replayed backlog or in the live stream,
+// never both and never neither. Publish times come from the Publisher's clock
+// (see WithClock), so tests can pin them with a clock.FakeClock.
+//
+// Parameters:
+//   - topic: string - the topic name to subscribe to
+//   - t: time.Time - only messages published after t are replayed
+//   - opts: ...SubscribeOption - per-subscription settings, applied to live messages
+//
+// Returns: the same as SubscribeFrom
+func (p *Publisher[T]) SubscribeSince(topic string, t time.Time, opts ...SubscribeOption) (<-chan T, error) {
+	return p.subscribeReplay(topic, 0, func(rec Record) bool { return rec.Time.After(t) }, opts)
+}
+
+// subscribeReplay implements SubscribeFrom and SubscribeSince: it replays the stored
+// records of topic from offset on that match keep, then hands off to live delivery.
+func (p *Publisher[T]) subscribeReplay(topic string, offset uint64, keep func(Record) bool, opts []SubscribeOption) (<-chan T, error) {
+	if err := p.authorizeSubscribe(Anonymous, topic); err != nil {
+		return nil, err
+	}
+	if p.config.store == nil {
+		return nil, ErrNoStore
+	}
+	settings := newSubscribeConfig(opts)
+	if settings.catchUpBatch > 0 || settings.credits {
+		return nil, errNeedsLog
+	}
+
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.subscribers[topic]; !ok {
+		return nil, errors.New("topic not found")
+	}
+
+	messages, err := p.replayLocked(topic, offset, keep)
+	if err != nil {
+		return nil, err
+	}
+	backlog := make([]T, 0, len(messages))
+	for _, msg := range messages {
+		if !msg.Deleted {
+			backlog = append(backlog, msg.Value)
+		}
+	}
+	return p.subscribeBacklogLocked(s, topic, backlog, settings)
+}
+
+// replayLocked reads and decodes the stored records of topic from offset on that match
+// keep. Called with the shard's write lock held so that no publish to topic is in flight.
+func (p *Publisher[T]) replayLocked(topic string, offset uint64, keep func(Record) bool) ([]Message[T], error) {
+	records, err := readAll(p.config.store, p.qualified(topic), offset)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message[T], 0, len(records))
+	for _, rec := range records {
+		if !keep(rec) {
+			continue
+		}
+		msg, err := p.toMessage(topic, rec)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// pump sends backlog, then everything received on live, to out. It closes out when
+// live is closed or done is closed.
+// Each send first spends a credit from credits (nil means no flow control).
+func pump[T any](backlog []T, live <-chan T, out chan<- T, done <-chan struct{}, credits *creditGate) {
+	defer close(out)
+	send := func(msg T) bool {
+		if !credits.acquire(done) {
+			return false
+		}
+		select {
+		case out <- msg:
+			return true
+		case <-done:
+			return false
+		}
+	}
+	for _, msg := range backlog {
+		if !send(msg) {
+			return
+		}
+	}
+	for msg := range live {
+		if !send(msg) {
+			return
+		}
+	}
+}
+
+// appendToStore encodes msg, appends it to topic's log and records the assigned
+// offset in msg.
+func (p *Publisher[T]) appendToStore(topic string, msg *Message[T]) error {
+	rec := Record{Time: msg.Time, Key: msg.Key, Tombstone: msg.Deleted}
+	if !msg.Deleted {
+		payload, err := p.config.encodePayload(p.qualified(topic), msg.Value)
+		if err != nil {
+			return err
+		}
+		rec.Payload = payload
+	}
+	offset, err := p.config.store.Append(p.qualified(topic), rec)
+	if err != nil {
+		return err
+	}
+	msg.Offset = offset
+	return nil
+}
+
+// toMessage decodes a stored record back into a Message.
+func (p *Publisher[T]) toMessage(topic string, rec Record) (Message[T], error) {
+	msg := Message[T]{Offset: rec.Offset, Topic: p.qualified(topic), Time: rec.Time, Key: rec.Key, Deleted: rec.Tombstone}
+	if !rec.Tombstone {
+		if err := p.config.decodePayload(msg.Topic, rec.Payload, &msg.Value); err != nil {
+			return Message[T]{}, fmt.Errorf("decode %s@%d: %w", topic, rec.Offset, err)
+		}
+	}
+	return msg, nil
+}
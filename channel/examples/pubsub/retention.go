@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrTopicsUnlisted is returned by RestoreTopics when the store cannot list its topics.
+var ErrTopicsUnlisted = errors.New("store cannot list topics")
+
+// TopicLister is implemented by stores that can enumerate the topics they hold logs
+// for, which RestoreTopics needs to rehydrate a Publisher after a restart.
+type TopicLister interface {
+	// Topics returns the names of every topic with a log, in no particular order.
+	Topics() ([]string, error)
+}
+
+// WithRetention bounds the topic's stored log: every interval a background goroutine
+// discards records older than maxAge and, oldest first, records beyond maxBytes of
+// keys and payloads (see EnforceRetention). A zero maxAge or maxBytes disables that
+// limit. Without a store the option has no effect.
+func WithRetention(maxAge time.Duration, maxBytes int64, interval time.Duration) TopicOption {
+	return func(c *topicConfig) {
+		c.maxAge = maxAge
+		c.maxBytes = maxBytes
+		c.retainEvery = interval
+	}
+}
+
+// EnforceRetention applies the topic's WithRetention limits to its stored log now.
+// Only a prefix of the log is discarded, so offsets of the remaining records, and
+// consumer positions in them, stay valid; a topic whose records have all expired is
+// left with an empty log, and its offsets carry on from where they were.
+func (p *Publisher[T]) EnforceRetention(topic string) error {
+	store := p.config.store
+	if store == nil {
+		return ErrNoStore
+	}
+	s := p.shard(topic)
+	s.RLock()
+	state, ok := s.topics[topic]
+	s.RUnlock()
+	if !ok {
+		return errors.New("topic not found")
+	}
+	settings := state.settings
+
+	records, err := readAll(store, p.qualified(topic), 0)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	keep := 0 // Index of the first record kept
+	if settings.maxAge > 0 {
+		cutoff := p.config.clock.Now().Add(-settings.maxAge)
+		for keep < len(records) && !records[keep].Time.After(cutoff) {
+			keep++
+		}
+	}
+	if settings.maxBytes > 0 {
+		var size int64
+		for i := len(records) - 1; i >= keep; i-- {
+			size += int64(len(records[i].Key) + len(records[i].Payload))
+			if size > settings.maxBytes {
+				keep = i + 1
+				break
+			}
+		}
+	}
+	if keep == 0 {
+		return nil
+	}
+	before := records[len(records)-1].Offset + 1
+	if keep < len(records) {
+		before = records[keep].Offset
+	}
+	return store.Truncate(p.qualified(topic), before)
+}
+
+// retentionLoop runs EnforceRetention every interval until done is closed.
+func (p *Publisher[T]) retentionLoop(topic string, interval time.Duration, done <-chan struct{}) {
+	timer := p.config.clock.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C():
+			if err := p.EnforceRetention(topic); err != nil {
+				p.config.logger.Warn("pubsub: retention failed", "topic", topic, "error", err)
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
+// RestoreTopics rehydrates the Publisher after a restart: it creates (with opts) every
+// topic the store holds a log for, so publishing and SubscribeFrom work again without
+// knowing the topic names up front. Stores only create a log on the first publish, so
+// topics that never had a message are not restored. Retained messages and replay
+// history are rebuilt from the log when opts include WithRetain or WithReplay.
+//
+// In a namespace only the namespace's own topics are restored. Stored names with a
+// further "/" belong to nested namespaces and are skipped.
+//
+// Returns: the restored topic names, or ErrNoStore, ErrTopicsUnlisted or a store error
+func (p *Publisher[T]) RestoreTopics(opts ...TopicOption) ([]string, error) {
+	if p.config.store == nil {
+		return nil, ErrNoStore
+	}
+	lister, ok := p.config.store.(TopicLister)
+	if !ok {
+		return nil, ErrTopicsUnlisted
+	}
+	names, err := lister.Topics()
+	if err != nil {
+		return nil, err
+	}
+	var restored []string
+	for _, name := range names {
+		topic, ok := strings.CutPrefix(name, p.qualified(""))
+		if !ok || topic == "" || strings.Contains(topic, "/") {
+			continue
+		}
+		if err := p.CreateTopic(topic, opts...); err != nil {
+			return restored, err
+		}
+		restored = append(restored, topic)
+	}
+	return restored, nil
+}
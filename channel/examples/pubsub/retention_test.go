@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// TestEnforceRetention tests trimming by age and by size against every built-in store
+func TestEnforceRetention(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			fake := clock.NewFakeClock(time.Unix(0, 0))
+			pub := NewPublisher[string](WithStore(store), WithClock(fake))
+			// JSON payloads are 4 bytes ("m0"), so 12 bytes hold three records
+			pub.CreateTopic("age", WithRetention(2500*time.Millisecond, 0, 0))
+			pub.CreateTopic("size", WithRetention(0, 12, 0))
+			for i := range 5 {
+				pub.Publish("age", fmt.Sprintf("m%d", i))
+				pub.Publish("size", fmt.Sprintf("m%d", i))
+				fake.Advance(time.Second)
+			}
+
+			for topic, want := range map[string][]string{
+				"age":  {`"m3"`, `"m4"`},
+				"size": {`"m2"`, `"m3"`, `"m4"`},
+			} {
+				if err := pub.EnforceRetention(topic); err != nil {
+					t.Fatalf("EnforceRetention(%s) returned error: %v", topic, err)
+				}
+				records, _ := store.ReadFrom(topic, 0, 0)
+				if got := payloads(records); !slices.Equal(got, want) {
+					t.Errorf("Expected %s to keep %v, got %v", topic, want, got)
+				}
+			}
+
+			// When everything is too old the log empties, and offsets carry on
+			fake.Advance(time.Hour)
+			pub.EnforceRetention("age")
+			if records, _ := store.ReadFrom("age", 0, 0); len(records) != 0 {
+				t.Errorf("Expected an empty log, got %v", records)
+			}
+			if offset, _ := store.Append("age", Record{}); offset != 5 {
+				t.Errorf("Expected offset 5 after emptying the log, got %d", offset)
+			}
+		})
+	}
+}
+
+// TestRestoreTopics tests that a restarted Publisher recreates the topics that have a
+// stored log, with their retained messages
+func TestRestoreTopics(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := OpenFileStore(dir, WithSyncWrites())
+	pub := NewPublisher[string](WithStore(store))
+	pub.CreateTopic("config")
+	pub.CreateTopic("events")
+	tenant := namespace(t, pub, "tenant")
+	tenant.CreateTopic("orders")
+	pub.Publish("config", "v1")
+	pub.Publish("config", "v2")
+	pub.Publish("events", "e1")
+	tenant.Publish("orders", "o1")
+	store.Close()
+
+	store, _ = OpenFileStore(dir)
+	defer store.Close()
+	pub = NewPublisher[string](WithStore(store))
+	restored, err := pub.RestoreTopics(WithRetain())
+	if err != nil {
+		t.Fatalf("RestoreTopics() returned error: %v", err)
+	}
+	if want := []string{"config", "events"}; !slices.Equal(restored, want) {
+		t.Errorf("Expected %v restored, got %v", want, restored)
+	}
+	if restored, _ := namespace(t, pub, "tenant").RestoreTopics(); !slices.Equal(restored, []string{"orders"}) {
+		t.Errorf("Expected the namespace to restore [orders], got %v", restored)
+	}
+
+	ch, _ := pub.Subscribe("config")
+	if msg := <-ch; msg != "v2" {
+		t.Errorf("Expected the retained message v2, got %q", msg)
+	}
+	if _, err := NewPublisher[string]().RestoreTopics(); !errors.Is(err, ErrNoStore) {
+		t.Errorf("Expected ErrNoStore, got %v", err)
+	}
+}
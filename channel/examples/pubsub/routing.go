@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErrRouteCycle is returned by AddRoute when the new route would let a message travel
+// back to a topic it already passed through.
+var ErrRouteCycle = errors.New("route would create a cycle")
+
+// route forwards the messages of one topic that match predicate to dest.
+type route[T any] struct {
+	predicate func(Message[T]) bool // nil forwards everything
+	dest      string
+}
+
+// routeTable maps source topics to their routes. It is never modified once published
+// in Publisher.routes: AddRoute and remove install a changed copy, so publishes read it
+// without locking.
+type routeTable[T any] map[string][]*route[T]
+
+// AddRoute forwards every message published to source for which predicate returns true
+// to dest, as if it had been published there too. A nil predicate forwards everything.
+// Routes make topologies such as splitters (one source, several predicates) and routers
+// (content decides the destination) a matter of configuration rather than bridging
+// goroutines:
+//
+//	pub.AddRoute("orders", func(m Message[Order]) bool { return m.Value.Total > 1000 }, "orders.large")
+//	pub.AddRoute("orders", func(m Message[Order]) bool { return m.Value.Country != "US" }, "orders.intl")
+//
+// The forwarded message keeps its ID, key, headers and TTL; it gets dest as its topic
+// and a new publish time. Forwarding happens once the source subscribers have been
+// served, is not checked by the Authorizer, and counts as a publish to dest. Routes
+// that would form a cycle are rejected with ErrRouteCycle. Predicates run on the
+// publishing goroutine with the source topic's shard locked for reading, so they must be
+// fast and must not call back into the Publisher.
+//
+// Returns:
+//   - remove: func() - deletes the route; calling it again does nothing
+//   - error: "topic not found" if source or dest does not exist, or ErrRouteCycle
+func (p *Publisher[T]) AddRoute(source string, predicate func(Message[T]) bool, dest string) (remove func(), err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, topic := range []string{source, dest} {
+		if !p.hasTopic(topic) {
+			return nil, fmt.Errorf("topic not found: %q", topic)
+		}
+	}
+	table := p.routes.Load()
+	if table.reachable(dest, source) {
+		return nil, fmt.Errorf("%w: %q -> %q", ErrRouteCycle, source, dest)
+	}
+	r := &route[T]{predicate: predicate, dest: dest}
+	p.setRoutesLocked(source, append(table.from(source), r))
+
+	return func() {
+		p.Lock()
+		defer p.Unlock()
+		routes := p.routes.Load().from(source)
+		p.setRoutesLocked(source, slices.DeleteFunc(routes, func(other *route[T]) bool { return other == r }))
+	}, nil
+}
+
+// setRoutesLocked installs a copy of the route table with routes as the routes of
+// source. Called with the write lock held, which serializes route changes.
+func (p *Publisher[T]) setRoutesLocked(source string, routes []*route[T]) {
+	table := routeTable[T]{}
+	if old := p.routes.Load(); old != nil {
+		table = maps.Clone(*old)
+	}
+	table[source] = routes
+	p.routes.Store(&table)
+}
+
+// from returns a copy of the routes of source, which the caller may modify.
+func (t *routeTable[T]) from(source string) []*route[T] {
+	if t == nil {
+		return nil
+	}
+	return slices.Clone((*t)[source])
+}
+
+// reachable reports whether a message published to from can reach to through the
+// routes of t.
+func (t *routeTable[T]) reachable(from, to string) bool {
+	seen := map[string]bool{}
+	stack := []string{from}
+	for len(stack) > 0 {
+		topic := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if topic == to {
+			return true
+		}
+		if seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		if t == nil {
+			continue
+		}
+		for _, r := range (*t)[topic] {
+			stack = append(stack, r.dest)
+		}
+	}
+	return false
+}
+
+// matchRoutes returns the destinations msg published to topic is forwarded to.
+func (p *Publisher[T]) matchRoutes(topic string, msg Message[T]) []string {
+	table := p.routes.Load()
+	if table == nil {
+		return nil
+	}
+	var dests []string
+	for _, r := range (*table)[topic] {
+		if r.predicate == nil || r.predicate(msg) {
+			dests = append(dests, r.dest)
+		}
+	}
+	return dests
+}
+
+// forward publishes msg to each of dests. It must be called without the source's
+// shard lock held: publishing may take it again, and a waiting writer would deadlock a
+// recursive read lock.
+func (p *Publisher[T]) forward(ctx context.Context, dests []string, msg Message[T]) {
+	for _, dest := range dests {
+		p.metrics.routed.Add(1)
+		msg.Offset = 0
+		if err := p.publish(ctx, dest, msg); err != nil {
+			p.config.logger.Warn("pubsub: route failed", "topic", msg.Topic, "dest", dest, "error", err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRoutes tests that messages are forwarded to the topics whose predicate they
+// match, and no longer once the route is removed
+func TestRoutes(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(8))
+	for _, topic := range []string{"orders", "orders.large", "orders.audit"} {
+		pub.CreateTopic(topic)
+	}
+	large, _ := pub.Subscribe("orders.large")
+	audit, _ := pub.SubscribeMessages("orders.audit")
+	pub.AddRoute("orders", func(m Message[int]) bool { return m.Value > 100 }, "orders.large")
+	remove, err := pub.AddRoute("orders", nil, "orders.audit")
+	if err != nil {
+		t.Fatalf("AddRoute() returned error: %v", err)
+	}
+
+	for _, total := range []int{50, 500, 150} {
+		pub.Publish("orders", total)
+	}
+	remove()
+	pub.Publish("orders", 5)
+
+	if len(large) != 2 || <-large != 500 || <-large != 150 {
+		t.Errorf("Expected 500 and 150 routed to orders.large")
+	}
+	if len(audit.C) != 3 {
+		t.Errorf("Expected 3 messages routed to orders.audit before removal, got %d", len(audit.C))
+	}
+	if msg := <-audit.C; msg.Topic != "orders.audit" || msg.Value != 50 {
+		t.Errorf("Expected 50 republished on orders.audit, got %+v", msg)
+	}
+	if n := pub.metrics.routed.Load(); n != 5 {
+		t.Errorf("Expected 5 forwarded messages, got %d", n)
+	}
+}
+
+// TestRouteCycle tests that routes forming a cycle are rejected
+func TestRouteCycle(t *testing.T) {
+	pub := NewPublisher[int]()
+	for _, topic := range []string{"a", "b", "c"} {
+		pub.CreateTopic(topic)
+	}
+	pub.AddRoute("a", nil, "b")
+	pub.AddRoute("b", nil, "c")
+	for _, dest := range []string{"a", "c"} {
+		if _, err := pub.AddRoute(dest, nil, "a"); !errors.Is(err, ErrRouteCycle) {
+			t.Errorf("Expected ErrRouteCycle for %s -> a, got %v", dest, err)
+		}
+	}
+	if _, err := pub.AddRoute("a", nil, "missing"); err == nil {
+		t.Error("Expected an error for an unknown destination")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// defaultShards is the number of topic shards used when WithTopicShards is not given.
+const defaultShards = 32
+
+// WithTopicShards spreads the topics over n independently locked shards (32 by default,
+// values below 1 are treated as 1). Publishes and subscriptions on topics of different
+// shards never wait for each other; more shards help brokers with many busy topics.
+func WithTopicShards(n int) Option {
+	return func(c *config) {
+		c.shards = max(n, 1)
+	}
+}
+
+// topicShard holds the topics whose name hashes to it (see Publisher.shard).
+//
+// Go Concurrency Patterns used:
+//   - Lock striping: each shard has its own RWMutex, so a Subscribe or CloseTopic (write
+//     lock) only stalls the publishes of its own shard, and readers of different shards
+//     do not bounce one reader count between CPUs
+type topicShard[T any] struct {
+	sync.RWMutex                             // Protects the maps and the queue turns of the shard's topics
+	subscribers  map[string][]*subscriber[T] // Topic -> list of subscribers
+	topics       map[string]*topicState[T]   // Per-topic state, same keys as subscribers
+	_            [64]byte                    // Keeps the locks of neighbouring shards on different cache lines
+}
+
+// newShards returns n empty shards.
+func newShards[T any](n int) []topicShard[T] {
+	shards := make([]topicShard[T], n)
+	for i := range shards {
+		shards[i].subscribers = make(map[string][]*subscriber[T])
+		shards[i].topics = make(map[string]*topicState[T])
+	}
+	return shards
+}
+
+// shard returns the shard that holds topic.
+func (p *Publisher[T]) shard(topic string) *topicShard[T] {
+	return &p.shards[p.shardIndex(topic)]
+}
+
+// shardIndex returns the index in p.shards of the shard that holds topic.
+func (p *Publisher[T]) shardIndex(topic string) int {
+	return int(maphash.String(p.shardSeed, topic) % uint64(len(p.shards)))
+}
+
+// hasTopic reports whether topic exists.
+func (p *Publisher[T]) hasTopic(topic string) bool {
+	s := p.shard(topic)
+	s.RLock()
+	defer s.RUnlock()
+	_, ok := s.topics[topic]
+	return ok
+}
+
+// eachTopic calls fn for every topic, one shard at a time with the shard's read lock
+// held. The topics of one call are not a snapshot of the whole Publisher.
+func (p *Publisher[T]) eachTopic(fn func(topic string, state *topicState[T], subs []*subscriber[T])) {
+	for i := range p.shards {
+		s := &p.shards[i]
+		s.RLock()
+		for topic, state := range s.topics {
+			fn(topic, state, s.subscribers[topic])
+		}
+		s.RUnlock()
+	}
+}
+
+// reserveTopic counts a new topic against WithMaxTopics, returning a QuotaError if the
+// namespace is full. releaseTopic undoes it.
+func (p *Publisher[T]) reserveTopic() error {
+	limit := int64(p.limits.Load().maxTopics)
+	for {
+		n := p.topicCount.Load()
+		if limit > 0 && n >= limit {
+			return &QuotaError{Quota: "WithMaxTopics", Namespace: p.namespace, Limit: limit, Used: n}
+		}
+		if p.topicCount.CompareAndSwap(n, n+1) {
+			return nil
+		}
+	}
+}
+
+func (p *Publisher[T]) releaseTopic() {
+	p.topicCount.Add(-1)
+}
+
+// reserveBuffered counts n buffered messages against WithMaxBuffered, returning a
+// QuotaError if they do not fit. releaseBuffered undoes it.
+func (p *Publisher[T]) reserveBuffered(n int) error {
+	limit := int64(p.limits.Load().maxBuffered)
+	for {
+		used := p.buffered.Load()
+		if limit > 0 && used+int64(n) > limit {
+			return &QuotaError{Quota: "WithMaxBuffered", Namespace: p.namespace, Limit: limit, Used: used}
+		}
+		if p.buffered.CompareAndSwap(used, used+int64(n)) {
+			return nil
+		}
+	}
+}
+
+func (p *Publisher[T]) releaseBuffered(n int) {
+	p.buffered.Add(-int64(n))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestTopicShards tests that topics spread over the shards and that concurrent
+// subscribes, publishes and closes on different topics keep every topic consistent
+func TestTopicShards(t *testing.T) {
+	pub := NewPublisher[string](WithTopicShards(4))
+	const topics = 64
+	var wg sync.WaitGroup
+	for i := range topics {
+		wg.Go(func() {
+			topic := fmt.Sprint("topic-", i)
+			if err := pub.CreateTopic(topic); err != nil {
+				t.Errorf("CreateTopic(%q) returned error: %v", topic, err)
+				return
+			}
+			ch, _ := pub.Subscribe(topic, WithOverflow(OverflowDropNewest))
+			pub.Publish(topic, "hello")
+			if msg := <-ch; msg != "hello" {
+				t.Errorf("Expected hello on %s, got %q", topic, msg)
+			}
+			if i%2 == 0 {
+				pub.CloseTopic(topic)
+			}
+		})
+	}
+	wg.Wait()
+
+	used := 0
+	for i := range pub.shards {
+		if len(pub.shards[i].topics) > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		t.Errorf("Expected topics on several of the 4 shards, got %d", used)
+	}
+	if stats := pub.Stats(); len(stats) != topics/2 {
+		t.Errorf("Expected %d open topics, got %d", topics/2, len(stats))
+	}
+	if n := pub.expvarSnapshot()["topics"]; n != topics/2 {
+		t.Errorf("Expected the topics gauge at %d, got %d", topics/2, n)
+	}
+}
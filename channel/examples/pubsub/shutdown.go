@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// WithDropOnShutdown makes Shutdown discard the messages still queued for async
+// delivery (see WithAsyncDelivery) instead of delivering them first. Discarded
+// messages are counted as dropped.
+func WithDropOnShutdown() Option {
+	return func(c *config) {
+		c.dropOnShutdown = true
+	}
+}
+
+// Shutdown stops the whole Publisher:
+//
+//  1. Publish and CreateTopic fail with ErrShutdown from now on.
+//  2. Messages queued for async delivery are delivered (or discarded, see
+//     WithDropOnShutdown).
+//  3. Every topic is closed as by CloseTopic, which closes every subscriber channel.
+//  4. Shutdown waits for the Publisher's goroutines (replay pumps, ack loops,
+//     compaction and retention loops) to exit.
+//
+// Namespaces (see Namespace) and the dead-letter topic (see WithDeadLetters) are shut
+// down too. If ctx is done first, Shutdown returns ctx.Err() and the shutdown carries on
+// in the background; a later call waits for the same shutdown.
+//
+// Go Concurrency Patterns used:
+//   - sync.Once: the shutdown sequence runs once however many callers ask for it, and
+//     every caller waits on the same done channel
+//   - Bounded wait: the sequence runs on its own goroutine, so a subscriber that never
+//     drains delays it but cannot hold the caller past ctx
+//   - WaitGroup: background goroutines are started with goroutines.Go so Shutdown
+//     knows when the last one has exited
+func (p *Publisher[T]) Shutdown(ctx context.Context) error {
+	select {
+	case <-p.stop():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop starts the shutdown sequence, once, and returns a channel closed when it is over.
+func (p *Publisher[T]) stop() <-chan struct{} {
+	p.stopOnce.Do(func() {
+		p.stopped = make(chan struct{})
+		p.stopping.Store(true)
+		go func() {
+			defer close(p.stopped)
+			p.RLock()
+			children := slices.Collect(maps.Values(p.namespaces))
+			p.RUnlock()
+			var pending []<-chan struct{}
+			for _, child := range children {
+				pending = append(pending, child.stop())
+			}
+
+			if p.async != nil {
+				p.stopAsync()
+			}
+			for i := range p.shards {
+				s := &p.shards[i]
+				s.Lock() // Waits for publishes still delivering
+				for topic := range s.topics {
+					p.closeTopicLocked(s, topic)
+				}
+				s.Unlock()
+			}
+			p.goroutines.Wait()
+
+			for _, done := range pending {
+				<-done
+			}
+			if p.deadLetters != nil {
+				<-p.deadLetters.stop() // Last, children and workers may still dead-letter
+			}
+		}()
+	})
+	return p.stopped
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestShutdown tests that Shutdown closes the subscribers of every topic, namespace
+// and the dead-letter topic, waits for the replay pumps and ack loops, and rejects
+// later publishes and topics
+func TestShutdown(t *testing.T) {
+	pub := NewPublisher[string](WithDeadLetters())
+	pub.CreateTopic("orders", WithReplay(4))
+	pub.Publish("orders", "o1")
+	plain, _ := pub.Subscribe("orders")
+	replay, _ := pub.SubscribeWithReplay("orders")
+	acked, _ := pub.SubscribeAcked("orders")
+	dead, _ := pub.SubscribeDeadLetters()
+	tenant := namespace(t, pub, "tenant")
+	tenant.CreateTopic("events")
+	events, _ := tenant.Subscribe("events")
+
+	if err := pub.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+	for name, closed := range map[string]bool{
+		"plain":  isClosed(plain),
+		"replay": isClosed(replay),
+		"acked":  isClosed(acked.C),
+		"dead":   isClosed(dead),
+		"events": isClosed(events),
+	} {
+		if !closed {
+			t.Errorf("Expected the %s subscriber to be closed", name)
+		}
+	}
+	if err := pub.Publish("orders", "late"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected ErrShutdown from Publish, got %v", err)
+	}
+	if err := tenant.Publish("events", "late"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected ErrShutdown from a namespace, got %v", err)
+	}
+	if err := pub.CreateTopic("new"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected ErrShutdown from CreateTopic, got %v", err)
+	}
+	if err := pub.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected a second Shutdown to succeed, got %v", err)
+	}
+}
+
+// isClosed drains ch and reports whether it is closed.
+func isClosed[M any](ch <-chan M) bool {
+	for range len(ch) + 1 {
+		if _, ok := <-ch; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TestShutdownDropsQueued tests that WithDropOnShutdown discards the messages still
+// queued for async delivery
+func TestShutdownDropsQueued(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(0), WithAsyncDelivery(1, 3), WithDropOnShutdown())
+	pub.CreateTopic("events")
+	ch, _ := pub.Subscribe("events")
+	pub.Publish("events", "0")
+	waitFor(t, func() bool { return queued(pub) == 0 }) // The worker blocks on "0"
+	for i := 1; i <= 3; i++ {
+		pub.Publish("events", fmt.Sprint(i))
+	}
+
+	done := make(chan error)
+	go func() { done <- pub.Shutdown(context.Background()) }()
+	waitFor(t, pub.stopping.Load)
+	if msg := <-ch; msg != "0" {
+		t.Errorf("Expected the message in delivery, got %q", msg)
+	}
+	if msg, ok := <-ch; ok {
+		t.Errorf("Expected the queued messages to be dropped, got %q", msg)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+	if n := pub.metrics.dropped.Load(); n != 3 {
+		t.Errorf("Expected 3 dropped messages, got %d", n)
+	}
+}
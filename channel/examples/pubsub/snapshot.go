@@ -0,0 +1,56 @@
+package main
+
+import "errors"
+
+// SetSnapshotProvider installs snapshot as topic's state provider: every new Subscribe
+// or SubscribeMessages on topic (and SubscribeFunc, the HTTP gateway and the gRPC
+// bridge, which build on it) first receives the values snapshot returns (the current
+// contents of a cache, say), then the live messages. The snapshot takes the
+// place of the retained message (WithRetain); SubscribeWithReplay still replays the
+// topic's history instead. A nil snapshot removes the provider, which belongs to the
+// topic like a validator (see SetValidator).
+//
+// snapshot is called with the topic's shard write-locked, so no publish to topic is in
+// progress while it runs, and every message published after it returns is delivered
+// after the snapshot. For a hand-off without gaps, update the state the provider reads
+// before publishing the change: a change is then in the snapshot, or published after
+// it, or both. snapshot must be fast and must not call back into the Publisher.
+//
+// Go Concurrency Patterns used:
+//   - Write lock as a cut: taking the snapshot and registering the subscriber under the
+//     same write lock splits the topic's messages into before (in the snapshot) and
+//     after (delivered live), with no publish in between
+//   - Pump goroutine: the snapshot is handed over by the same pump as replayed history,
+//     so a snapshot larger than the buffer never blocks the lock holder
+//
+// Usage example:
+//
+//	pub.SetSnapshotProvider("prices", func() []Price {
+//		return cache.All()
+//	})
+func (p *Publisher[T]) SetSnapshotProvider(topic string, snapshot func() []T) error {
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	state, ok := s.topics[topic]
+	if !ok {
+		return errors.New("topic not found")
+	}
+	state.snapshot = snapshot
+	return nil
+}
+
+// snapshotMessagesLocked returns the snapshot of topic as envelopes, stamped like
+// published messages but without an ID. Called with the write lock of topic's shard
+// held and state.snapshot set.
+func (p *Publisher[T]) snapshotMessagesLocked(topic string, state *topicState[T]) []Message[T] {
+	values := state.snapshot()
+	messages := make([]Message[T], len(values))
+	now := p.config.clock.Now()
+	for i, value := range values {
+		messages[i] = Message[T]{Topic: p.qualified(topic), Time: now, Value: value}
+	}
+	return messages
+}
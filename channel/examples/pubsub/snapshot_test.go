@@ -0,0 +1,64 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestSetSnapshotProvider tests that new subscribers receive the snapshot before live
+// messages, and that a concurrent publisher leaves no gap between the two
+func TestSetSnapshotProvider(t *testing.T) {
+	pub := NewPublisher[int](WithDefaultBuffer(4))
+	pub.CreateTopic("counter", WithRetain())
+	if err := pub.SetSnapshotProvider("missing", nil); err == nil {
+		t.Error("Expected an error for an unknown topic")
+	}
+	pub.SetSnapshotProvider("counter", func() []int { return []int{1, 2, 3, 4, 5, 6} })
+	pub.Publish("counter", 7) // Retained, but the snapshot takes its place
+
+	ch, _ := pub.Subscribe("counter")
+	sub, _ := pub.SubscribeMessages("counter")
+	pub.Publish("counter", 8)
+	for want := 1; want <= 6; want++ {
+		if got := <-ch; got != want {
+			t.Errorf("Expected snapshot value %d, got %d", want, got)
+		}
+		if msg := <-sub.C; msg.Value != want || msg.Topic != "counter" {
+			t.Errorf("Expected snapshot envelope %d, got %+v", want, msg)
+		}
+	}
+	if got := <-ch; got != 8 {
+		t.Errorf("Expected the live message after the snapshot, got %d", got)
+	}
+	pub.CloseSubscriber("counter", ch)
+	sub.Close()
+
+	// The publisher updates the state before publishing it: the first live value
+	// repeats the snapshot or follows it, never skips
+	var current atomic.Int64
+	pub.SetSnapshotProvider("counter", func() []int { return []int{int(current.Load())} })
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			current.Store(int64(i))
+			pub.Publish("counter", i)
+		}
+	})
+	for range 20 {
+		late, _ := pub.SubscribeMessages("counter", WithOverflow(OverflowDropNewest))
+		first := (<-late.C).Value
+		if next := (<-late.C).Value; next != first && next != first+1 {
+			t.Errorf("Expected %d or %d after the snapshot, got %d", first, first+1, next)
+		}
+		late.Close()
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"strconv"
+)
+
+// Tracer starts spans for distributed tracing (see WithTracing). It mirrors the shape
+// of OpenTelemetry's trace.Tracer without depending on it, so an adapter is a few lines:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//	type otelSpan struct{ s trace.Span }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span) {
+//		kvs := make([]attribute.KeyValue, 0, len(attrs))
+//		for k, v := range attrs {
+//			kvs = append(kvs, attribute.String(k, v))
+//		}
+//		ctx, s := o.t.Start(ctx, name, trace.WithAttributes(kvs...))
+//		return ctx, otelSpan{s}
+//	}
+//	func (o otelSpan) RecordError(err error) { o.s.RecordError(err) }
+//	func (o otelSpan) End()                  { o.s.End() }
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span)
+}
+
+// Span is a started span.
+type Span interface {
+	RecordError(err error)
+	End()
+}
+
+// Propagator copies span context into message headers and back, so a subscriber's
+// work joins the publisher's trace. An OpenTelemetry propagation.TextMapPropagator
+// adapts by passing propagation.MapCarrier(headers) as the carrier.
+type Propagator interface {
+	Inject(ctx context.Context, headers map[string]string)
+	Extract(ctx context.Context, headers map[string]string) context.Context
+}
+
+// WithTracing creates a span around every publish ("pubsub.publish") and every
+// per-subscriber delivery ("pubsub.deliver"), tagged with the topic, the message ID and,
+// for deliveries, the subscriber id. With a propagator, the publish span's context is
+// injected into the message headers and each delivery span is started from the context
+// extracted from them, which links deliveries made by async workers (WithAsyncDelivery)
+// to their publish; subscribers using SubscribeMessages can extract it too and continue
+// the trace while processing. propagator may be nil.
+//
+// The spans are created by a middleware (see Use) installed before any other.
+func WithTracing(tracer Tracer, propagator Propagator) Option {
+	return func(c *config) {
+		c.tracer = tracer
+		c.propagator = propagator
+	}
+}
+
+// tracing is the middleware installed by WithTracing.
+func tracing[T any](tracer Tracer, propagator Propagator) Middleware[T] {
+	return func(next PublishFunc[T]) PublishFunc[T] {
+		return func(ctx context.Context, topic string, msg Message[T]) error {
+			attrs := map[string]string{"messaging.destination": msg.Topic, "messaging.message.id": msg.ID}
+			name := "pubsub.publish"
+			if id, delivering := SubscriberFromContext(ctx); delivering {
+				name = "pubsub.deliver"
+				attrs["pubsub.subscriber"] = strconv.FormatUint(id, 10)
+				if propagator != nil {
+					ctx = propagator.Extract(ctx, msg.Headers)
+				}
+				ctx, span := tracer.Start(ctx, name, attrs)
+				return endSpan(span, next(ctx, topic, msg))
+			}
+
+			ctx, span := tracer.Start(ctx, name, attrs)
+			if propagator != nil {
+				headers := maps.Clone(msg.Headers) // The caller's map is not ours to write
+				if headers == nil {
+					headers = make(map[string]string)
+				}
+				propagator.Inject(ctx, headers)
+				msg.Headers = headers
+			}
+			return endSpan(span, next(ctx, topic, msg))
+		}
+	}
+}
+
+// endSpan records err, if any, on span and ends it. It returns err.
+func endSpan(span Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	return err
+}
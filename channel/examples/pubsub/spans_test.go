@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordedSpan is a span captured by recordingTracer.
+type recordedSpan struct {
+	name   string
+	id     string
+	parent string
+	attrs  map[string]string
+	err    error
+}
+
+// recordingTracer is a Tracer and Propagator that keeps every span in memory. The
+// span context is the span id stored in ctx, carried in the "traceparent" header.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type spanKey struct{}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	parent, _ := ctx.Value(spanKey{}).(string)
+	s := &recordedSpan{name: name, id: string(rune('a' + len(r.spans))), parent: parent, attrs: attrs}
+	r.spans = append(r.spans, s)
+	return context.WithValue(ctx, spanKey{}, s.id), spanEnder{r, s}
+}
+
+func (r *recordingTracer) Inject(ctx context.Context, headers map[string]string) {
+	headers["traceparent"], _ = ctx.Value(spanKey{}).(string)
+}
+
+func (r *recordingTracer) Extract(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, spanKey{}, headers["traceparent"])
+}
+
+type spanEnder struct {
+	r *recordingTracer
+	s *recordedSpan
+}
+
+func (e spanEnder) RecordError(err error) {
+	e.r.mu.Lock()
+	e.s.err = err
+	e.r.mu.Unlock()
+}
+
+func (e spanEnder) End() {}
+
+// TestTracing tests that publishes and deliveries get spans, and that delivery spans
+// are children of the publish span through the message headers
+func TestTracing(t *testing.T) {
+	tracer := &recordingTracer{}
+	pub := NewPublisher[string](WithTracing(tracer, tracer))
+	pub.CreateTopic("orders")
+	sub, _ := pub.SubscribeMessages("orders")
+	pub.Subscribe("orders", WithOverflow(OverflowDropNewest))
+
+	pub.PublishWithID("orders", "o0", "created")
+	<-sub.C
+	pub.PublishWithID("orders", "o1", "paid") // Dropped by the full second subscriber
+	msg := <-sub.C
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.spans) != 6 {
+		t.Fatalf("Expected a publish and two delivery spans per message, got %d", len(tracer.spans))
+	}
+	publish := tracer.spans[3]
+	if publish.name != "pubsub.publish" || publish.attrs["messaging.message.id"] != "o1" {
+		t.Errorf("Expected the publish span of o1 first, got %+v", publish)
+	}
+	if msg.Headers["traceparent"] != publish.id {
+		t.Errorf("Expected the publish span in the headers, got %v", msg.Headers)
+	}
+	for _, deliver := range tracer.spans[4:] {
+		if deliver.name != "pubsub.deliver" || deliver.parent != publish.id {
+			t.Errorf("Expected a delivery span under the publish span, got %+v", deliver)
+		}
+	}
+	if failed := tracer.spans[5]; !errors.Is(failed.err, ErrDropped) {
+		t.Errorf("Expected the dropped delivery to record ErrDropped, got %v", failed.err)
+	}
+}
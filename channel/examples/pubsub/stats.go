@@ -0,0 +1,50 @@
+package main
+
+import "sync/atomic"
+
+// TopicStats is a snapshot of one topic's traffic and subscriber health.
+type TopicStats struct {
+	Published   int64 // Messages published to the topic
+	Delivered   int64 // Messages handed to subscribers, one per subscriber
+	Dropped     int64 // Messages discarded by subscriber overflow policies (WithOverflow)
+	Subscribers int   // Current number of subscribers
+	MaxBacklog  int   // Unread messages of the subscriber furthest behind
+}
+
+// topicCounters are the per-topic counters behind TopicStats. Like the Publisher-wide
+// metrics they are updated by concurrent publishers holding the read lock.
+type topicCounters struct {
+	published atomic.Int64
+	delivered atomic.Int64
+	dropped   atomic.Int64
+}
+
+// Stats returns a snapshot of every topic's counters and gauges, keyed by topic.
+// Counters start at zero when a topic is created (or re-created with CreateTopic).
+// Tests can wait for Delivered to reach the expected count instead of sleeping:
+//
+//	for pub.Stats()["orders"].Delivered < 3 {
+//		runtime.Gosched()
+//	}
+func (p *Publisher[T]) Stats() map[string]TopicStats {
+	stats := make(map[string]TopicStats)
+	p.eachTopic(func(topic string, state *topicState[T], subs []*subscriber[T]) {
+		stats[topic] = topicStatsOf(state, subs)
+	})
+	return stats
+}
+
+// topicStatsOf returns the stats of a topic with state and subscribers subs. Called
+// with the topic's shard locked.
+func topicStatsOf[T any](state *topicState[T], subs []*subscriber[T]) TopicStats {
+	s := TopicStats{
+		Published:   state.counters.published.Load(),
+		Delivered:   state.counters.delivered.Load(),
+		Dropped:     state.counters.dropped.Load(),
+		Subscribers: len(subs),
+	}
+	for _, sub := range subs {
+		s.MaxBacklog = max(s.MaxBacklog, sub.pending())
+	}
+	return s
+}
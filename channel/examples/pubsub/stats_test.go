@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// TestStats tests the per-topic counters and gauges
+func TestStats(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(2))
+	pub.CreateTopic("news")
+	pub.CreateTopic("quiet")
+	pub.Subscribe("news", WithOverflow(OverflowDropNewest))
+	fast, _ := pub.Subscribe("news")
+
+	for range 3 {
+		pub.Publish("news", "headline")
+		<-fast
+	}
+	pub.CloseSubscriber("news", fast)
+
+	want := TopicStats{Published: 3, Delivered: 5, Dropped: 1, Subscribers: 1, MaxBacklog: 2}
+	if got := pub.Stats()["news"]; got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := pub.Stats()["quiet"]; got != (TopicStats{}) {
+		t.Errorf("Expected an idle topic to report zeros, got %+v", got)
+	}
+}
+
+// TestStatsAsync tests waiting on Delivered instead of sleeping for async delivery
+func TestStatsAsync(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(8), WithAsyncDelivery(2, 8))
+	defer pub.Shutdown(context.Background())
+	pub.CreateTopic("news")
+	pub.Subscribe("news")
+	pub.Subscribe("news")
+
+	for range 4 {
+		pub.Publish("news", "headline")
+	}
+	waitFor(t, func() bool { return pub.Stats()["news"].Delivered == 8 })
+}
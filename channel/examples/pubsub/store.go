@@ -0,0 +1,181 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Record is one message in a topic log.
+type Record struct {
+	Offset    uint64    // Position in the topic log, assigned by Append
+	Time      time.Time // When the message was published
+	Key       string    // Optional message key
+	Payload   []byte    // Message encoded with the Publisher's Codec
+	Tombstone bool      // Marks Key as deleted (keyed topics), Payload is empty
+}
+
+// TopicStore persists topic logs for the Publisher. Every feature that needs messages
+// after they were delivered (offset replay, retained messages, ack bookkeeping) reads
+// and writes through this interface, so another backend (bolt, SQLite, ...) can be
+// plugged in with WithStore without touching broker logic.
+//
+// Offsets are assigned per topic by Append and increase strictly. Implementations
+// must be safe for concurrent use.
+type TopicStore interface {
+	// Append stores rec at the end of topic's log and returns the offset assigned to it.
+	// rec.Offset is ignored.
+	Append(topic string, rec Record) (uint64, error)
+	// ReadFrom returns up to limit records of topic with Offset >= offset, oldest first.
+	// A limit <= 0 means no limit. Unknown topics have no records.
+	ReadFrom(topic string, offset uint64, limit int) ([]Record, error)
+	// Truncate discards every record of topic with Offset < before.
+	Truncate(topic string, before uint64) error
+	// Close releases the store's resources.
+	Close() error
+}
+
+// Compactor is implemented by stores that can drop arbitrary records, which key-based
+// compaction needs (Truncate only drops a prefix). Stores that do not implement it
+// reject topics created WithCompaction.
+type Compactor interface {
+	// Compact discards every record of topic for which keep returns false.
+	// Offsets of the remaining records do not change.
+	Compact(topic string, keep func(Record) bool) error
+}
+
+// OffsetStore is implemented by stores that can persist consumer group offsets,
+// which SubscribeGroup and Subscription.Commit need.
+type OffsetStore interface {
+	// CommitOffset records that group has processed topic up to and including offset.
+	CommitOffset(group, topic string, offset uint64) error
+	// CommittedOffset returns group's last committed offset for topic; ok is false if
+	// the group never committed.
+	CommittedOffset(group, topic string) (offset uint64, ok bool, err error)
+}
+
+// MemoryStore is a TopicStore that keeps logs in memory. It is the natural choice for
+// tests and for replay within a single process lifetime.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	topics  map[string]*memoryLog
+	offsets map[[2]string]uint64 // {group, topic} -> committed offset
+}
+
+type memoryLog struct {
+	records []Record // sorted by Offset
+	next    uint64   // offset for the next Append
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{topics: make(map[string]*memoryLog), offsets: make(map[[2]string]uint64)}
+}
+
+// Append implements TopicStore.
+func (s *MemoryStore) Append(topic string, rec Record) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log := s.topics[topic]
+	if log == nil {
+		log = &memoryLog{}
+		s.topics[topic] = log
+	}
+	rec.Offset = log.next
+	rec.Payload = slices.Clone(rec.Payload) // caller may reuse its buffer
+	log.records = append(log.records, rec)
+	log.next++
+	return rec.Offset, nil
+}
+
+// ReadFrom implements TopicStore.
+func (s *MemoryStore) ReadFrom(topic string, offset uint64, limit int) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	log := s.topics[topic]
+	if log == nil {
+		return nil, nil
+	}
+	records := log.records[firstAtOrAfter(log.records, offset):]
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return slices.Clone(records), nil
+}
+
+// Truncate implements TopicStore.
+func (s *MemoryStore) Truncate(topic string, before uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if log := s.topics[topic]; log != nil {
+		log.records = slices.Clone(log.records[firstAtOrAfter(log.records, before):])
+	}
+	return nil
+}
+
+// Compact implements Compactor.
+func (s *MemoryStore) Compact(topic string, keep func(Record) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if log := s.topics[topic]; log != nil {
+		log.records = slices.DeleteFunc(slices.Clone(log.records), func(rec Record) bool {
+			return !keep(rec)
+		})
+	}
+	return nil
+}
+
+// CommitOffset implements OffsetStore.
+func (s *MemoryStore) CommitOffset(group, topic string, offset uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offsets[[2]string{group, topic}] = offset
+	return nil
+}
+
+// CommittedOffset implements OffsetStore.
+func (s *MemoryStore) CommittedOffset(group, topic string) (uint64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	offset, ok := s.offsets[[2]string{group, topic}]
+	return offset, ok, nil
+}
+
+// Topics implements TopicLister.
+func (s *MemoryStore) Topics() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.topics)), nil
+}
+
+// Close implements TopicStore.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// firstAtOrAfter returns the index of the first record with Offset >= offset.
+// Offsets may have gaps, so this is a binary search rather than arithmetic.
+func firstAtOrAfter(records []Record, offset uint64) int {
+	return sort.Search(len(records), func(i int) bool {
+		return records[i].Offset >= offset
+	})
+}
+
+// readAll reads every record of topic from offset on, in batches.
+func readAll(store TopicStore, topic string, offset uint64) ([]Record, error) {
+	const batch = 256
+	var all []Record
+	for {
+		records, err := store.ReadFrom(topic, offset, batch)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, records...)
+		if len(records) < batch {
+			return all, nil
+		}
+		offset = records[len(records)-1].Offset + 1
+	}
+}
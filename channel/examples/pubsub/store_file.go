@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore is a TopicStore that appends each topic's records to its own file in a
+// directory, so topic logs survive a restart. Only a small index (offset -> file
+// position) is kept in memory; payloads are read back from disk on ReadFrom.
+// Truncate and Compact also record the topic's next offset next to its log, so a
+// log they emptied still continues its numbering after a restart.
+//
+// Record layout (big-endian):
+//
+//	offset uint64 | unix nanos int64 | flags uint8 | key length uint32 | key | payload length uint32 | payload
+type FileStore struct {
+	dir    string
+	mu     sync.Mutex
+	topics map[string]*fileLog
+	closed bool
+	sync   bool // fsync after every Append (WithSyncWrites)
+}
+
+// FileStoreOption configures a FileStore (same functional options pattern as Option).
+type FileStoreOption func(*FileStore)
+
+// WithSyncWrites makes Append fsync the topic file before returning, so a published
+// message survives a machine crash, not only a process crash, at the cost of one disk
+// flush per publish. Without it the OS decides when appended records reach the disk.
+func WithSyncWrites() FileStoreOption {
+	return func(s *FileStore) {
+		s.sync = true
+	}
+}
+
+type fileLog struct {
+	file  *os.File
+	index []filePos // sorted by offset
+	size  int64     // end of the last complete record
+	next  uint64
+}
+
+type filePos struct {
+	offset uint64
+	pos    int64
+	length int64
+}
+
+const recordHeaderSize = 8 + 8 + 1 + 4
+
+// flagTombstone is set in a record's flags byte for Record.Tombstone.
+const flagTombstone = 1 << 0
+
+// ErrStoreClosed is returned by FileStore operations after Close.
+var ErrStoreClosed = errors.New("store is closed")
+
+// OpenFileStore returns a FileStore keeping its logs in dir, creating dir if needed.
+// Existing logs are indexed lazily, the first time a topic is used.
+func OpenFileStore(dir string, opts ...FileStoreOption) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &FileStore{dir: dir, topics: make(map[string]*fileLog)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Append implements TopicStore.
+func (s *FileStore) Append(topic string, rec Record) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log, err := s.logLocked(topic)
+	if err != nil {
+		return 0, err
+	}
+
+	rec.Offset = log.next
+	buf := encodeRecord(rec)
+	if _, err := log.file.WriteAt(buf, log.size); err != nil {
+		return 0, err
+	}
+	if s.sync {
+		if err := log.file.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	log.index = append(log.index, filePos{offset: rec.Offset, pos: log.size, length: int64(len(buf))})
+	log.size += int64(len(buf))
+	log.next++
+	return rec.Offset, nil
+}
+
+// ReadFrom implements TopicStore.
+func (s *FileStore) ReadFrom(topic string, offset uint64, limit int) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log, err := s.logLocked(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := log.index[firstPosAtOrAfter(log.index, offset):]
+	if limit > 0 && len(positions) > limit {
+		positions = positions[:limit]
+	}
+	records := make([]Record, 0, len(positions))
+	for _, p := range positions {
+		buf := make([]byte, p.length)
+		if _, err := log.file.ReadAt(buf, p.pos); err != nil {
+			return nil, err
+		}
+		rec, _, err := decodeRecord(buf)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// Truncate implements TopicStore by rewriting the topic file without the
+// discarded records and atomically renaming it into place.
+func (s *FileStore) Truncate(topic string, before uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log, err := s.logLocked(topic)
+	if err != nil {
+		return err
+	}
+	keep := log.index[firstPosAtOrAfter(log.index, before):]
+	return s.rewriteLocked(topic, log, keep)
+}
+
+// Compact implements Compactor by rewriting the topic file with the kept records.
+func (s *FileStore) Compact(topic string, keep func(Record) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log, err := s.logLocked(topic)
+	if err != nil {
+		return err
+	}
+	var kept []filePos
+	for _, p := range log.index {
+		buf := make([]byte, p.length)
+		if _, err := log.file.ReadAt(buf, p.pos); err != nil {
+			return err
+		}
+		rec, _, err := decodeRecord(buf)
+		if err != nil {
+			return err
+		}
+		if keep(rec) {
+			kept = append(kept, p)
+		}
+	}
+	return s.rewriteLocked(topic, log, kept)
+}
+
+// CommitOffset implements OffsetStore. Each {group, topic} offset is a small file
+// under .offsets, replaced atomically so a crash leaves either the old or new value.
+func (s *FileStore) CommitOffset(group, topic string, offset uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrStoreClosed
+	}
+	if err := os.MkdirAll(filepath.Dir(s.offsetPath(group, topic)), 0o755); err != nil {
+		return err
+	}
+	return writeUint64(s.offsetPath(group, topic), offset)
+}
+
+// CommittedOffset implements OffsetStore.
+func (s *FileStore) CommittedOffset(group, topic string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, false, ErrStoreClosed
+	}
+	offset, ok, err := readUint64(s.offsetPath(group, topic))
+	if err != nil {
+		return 0, false, fmt.Errorf("offset for %s/%s: %w", group, topic, err)
+	}
+	return offset, ok, nil
+}
+
+func (s *FileStore) offsetPath(group, topic string) string {
+	// Suffixes keep names like ".." from escaping the directory
+	return filepath.Join(s.dir, ".offsets", url.PathEscape(group)+".group", url.PathEscape(topic)+".offset")
+}
+
+// Topics implements TopicLister by listing the log files in the directory.
+func (s *FileStore) Topics() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var topics []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".log")
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		if topic, err := url.PathUnescape(name); err == nil {
+			topics = append(topics, topic)
+		}
+	}
+	slices.Sort(topics)
+	return topics, nil
+}
+
+// Close implements TopicStore.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var errs []error
+	for _, log := range s.topics {
+		errs = append(errs, log.file.Sync(), log.file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// logLocked returns the open log for topic, opening and indexing its file on first use.
+func (s *FileStore) logLocked(topic string) (*fileLog, error) {
+	if s.closed {
+		return nil, ErrStoreClosed
+	}
+	if log, ok := s.topics[topic]; ok {
+		return log, nil
+	}
+	f, err := os.OpenFile(s.path(topic), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	log, err := indexLog(f)
+	if err == nil {
+		// Records an emptied log no longer holds may have used later offsets
+		var next uint64
+		next, _, err = readUint64(s.nextPath(topic))
+		log.next = max(log.next, next)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("index %s: %w", s.path(topic), err)
+	}
+	s.topics[topic] = log
+	return log, nil
+}
+
+// rewriteLocked replaces topic's file with only the records at keep.
+func (s *FileStore) rewriteLocked(topic string, log *fileLog, keep []filePos) error {
+	tmp, err := os.CreateTemp(s.dir, ".rewrite-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	index := make([]filePos, 0, len(keep))
+	var size int64
+	for _, p := range keep {
+		buf := make([]byte, p.length)
+		if _, err := log.file.ReadAt(buf, p.pos); err != nil {
+			tmp.Close()
+			return err
+		}
+		if _, err := tmp.Write(buf); err != nil {
+			tmp.Close()
+			return err
+		}
+		index = append(index, filePos{offset: p.offset, pos: size, length: p.length})
+		size += p.length
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	// Saved before the rename: the new file may no longer hold the last offset
+	if err := writeUint64(s.nextPath(topic), log.next); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(topic)); err != nil {
+		tmp.Close()
+		return err
+	}
+	log.file.Close()
+	log.file, log.index, log.size = tmp, index, size
+	return nil
+}
+
+func (s *FileStore) path(topic string) string {
+	return filepath.Join(s.dir, url.PathEscape(topic)+".log")
+}
+
+// nextPath is where rewriteLocked records the topic's next offset.
+func (s *FileStore) nextPath(topic string) string {
+	return s.path(topic) + ".next"
+}
+
+// writeUint64 replaces the file at path with v, atomically so a crash leaves either
+// the old or the new value.
+func writeUint64(path string, v uint64) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".write-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	_, err = tmp.Write(binary.BigEndian.AppendUint64(nil, v))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readUint64 reads a value written by writeUint64, reporting false if there is none.
+func readUint64(path string) (uint64, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if len(data) != 8 {
+		return 0, false, fmt.Errorf("corrupt file %s", path)
+	}
+	return binary.BigEndian.Uint64(data), true, nil
+}
+
+// indexLog scans a log file and builds its offset index. A torn record at the end
+// (crash during Append) is cut off, so that the next Append does not leave part of it
+// behind to be indexed as records on the following restart.
+func indexLog(f *os.File) (*fileLog, error) {
+	log := &fileLog{file: f}
+	r := bufio.NewReader(f)
+	for {
+		header := make([]byte, recordHeaderSize)
+		if _, err := io.ReadFull(r, header); err != nil {
+			break
+		}
+		offset := binary.BigEndian.Uint64(header)
+		keyLen := int64(binary.BigEndian.Uint32(header[17:]))
+		if _, err := r.Discard(int(keyLen)); err != nil {
+			break
+		}
+		var payloadLen [4]byte
+		if _, err := io.ReadFull(r, payloadLen[:]); err != nil {
+			break
+		}
+		n := int64(binary.BigEndian.Uint32(payloadLen[:]))
+		if _, err := r.Discard(int(n)); err != nil {
+			break
+		}
+		length := recordHeaderSize + keyLen + 4 + n
+		log.index = append(log.index, filePos{offset: offset, pos: log.size, length: length})
+		log.size += length
+		log.next = offset + 1
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > log.size {
+		if err := f.Truncate(log.size); err != nil {
+			return nil, err
+		}
+	}
+	return log, nil
+}
+
+func encodeRecord(rec Record) []byte {
+	buf := make([]byte, 0, recordHeaderSize+len(rec.Key)+4+len(rec.Payload))
+	buf = binary.BigEndian.AppendUint64(buf, rec.Offset)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(rec.Time.UnixNano()))
+	var flags byte
+	if rec.Tombstone {
+		flags |= flagTombstone
+	}
+	buf = append(buf, flags)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(rec.Key)))
+	buf = append(buf, rec.Key...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(rec.Payload)))
+	buf = append(buf, rec.Payload...)
+	return buf
+}
+
+func decodeRecord(buf []byte) (Record, int, error) {
+	if len(buf) < recordHeaderSize {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+	rec := Record{
+		Offset:    binary.BigEndian.Uint64(buf),
+		Time:      time.Unix(0, int64(binary.BigEndian.Uint64(buf[8:]))),
+		Tombstone: buf[16]&flagTombstone != 0,
+	}
+	n := recordHeaderSize
+	keyLen := int(binary.BigEndian.Uint32(buf[17:]))
+	if len(buf) < n+keyLen+4 {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+	rec.Key = string(buf[n : n+keyLen])
+	n += keyLen
+	payloadLen := int(binary.BigEndian.Uint32(buf[n:]))
+	n += 4
+	if len(buf) < n+payloadLen {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+	rec.Payload = buf[n : n+payloadLen]
+	return rec, n + payloadLen, nil
+}
+
+func firstPosAtOrAfter(index []filePos, offset uint64) int {
+	lo, hi := 0, len(index)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if index[mid].offset < offset {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
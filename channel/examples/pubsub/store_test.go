@@ -0,0 +1,286 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// testStores returns one of each built-in TopicStore
+func testStores(t *testing.T) map[string]TopicStore {
+	fs, err := OpenFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenFileStore() returned error: %v", err)
+	}
+	t.Cleanup(func() { fs.Close() })
+	return map[string]TopicStore{"memory": NewMemoryStore(), "file": fs}
+}
+
+// payloads returns the payloads of records as strings
+func payloads(records []Record) []string {
+	var out []string
+	for _, rec := range records {
+		out = append(out, string(rec.Payload))
+	}
+	return out
+}
+
+// TestTopicStore tests Append, ReadFrom and Truncate against every built-in store
+func TestTopicStore(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i, msg := range []string{"a", "b", "c", "d"} {
+				offset, err := store.Append("news", Record{Key: "k", Payload: []byte(msg), Time: time.Unix(int64(i), 0)})
+				if err != nil {
+					t.Fatalf("Append() returned error: %v", err)
+				}
+				if offset != uint64(i) {
+					t.Errorf("Expected offset %d, got %d", i, offset)
+				}
+			}
+
+			records, err := store.ReadFrom("news", 1, 2)
+			if err != nil {
+				t.Fatalf("ReadFrom() returned error: %v", err)
+			}
+			if got := payloads(records); !slices.Equal(got, []string{"b", "c"}) {
+				t.Errorf("Expected [b c], got %v", got)
+			}
+			if records[0].Offset != 1 || records[0].Key != "k" || !records[0].Time.Equal(time.Unix(1, 0)) {
+				t.Errorf("Unexpected record fields: %+v", records[0])
+			}
+
+			if err := store.Truncate("news", 2); err != nil {
+				t.Fatalf("Truncate() returned error: %v", err)
+			}
+			records, _ = store.ReadFrom("news", 0, 0)
+			if got := payloads(records); !slices.Equal(got, []string{"c", "d"}) {
+				t.Errorf("Expected [c d] after truncate, got %v", got)
+			}
+
+			// Offsets keep increasing after a truncate
+			if offset, _ := store.Append("news", Record{Payload: []byte("e")}); offset != 4 {
+				t.Errorf("Expected offset 4 after truncate, got %d", offset)
+			}
+			if records, _ := store.ReadFrom("unknown", 0, 0); len(records) != 0 {
+				t.Errorf("Expected no records for unknown topic, got %d", len(records))
+			}
+		})
+	}
+}
+
+// TestFileStoreReopen tests that a FileStore's logs and offsets survive a restart
+func TestFileStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore() returned error: %v", err)
+	}
+	store.Append("a/b", Record{Payload: []byte("first")})
+	store.Append("a/b", Record{Payload: []byte("second")})
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+	if _, err := store.Append("a/b", Record{}); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Expected ErrStoreClosed after Close, got %v", err)
+	}
+
+	store, err = OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore() returned error: %v", err)
+	}
+	defer store.Close()
+	records, err := store.ReadFrom("a/b", 0, 0)
+	if err != nil {
+		t.Fatalf("ReadFrom() returned error: %v", err)
+	}
+	if got := payloads(records); !slices.Equal(got, []string{"first", "second"}) {
+		t.Errorf("Expected both records after reopen, got %v", got)
+	}
+	if offset, _ := store.Append("a/b", Record{Payload: []byte("third")}); offset != 2 {
+		t.Errorf("Expected offset 2 after reopen, got %d", offset)
+	}
+}
+
+// TestFileStoreEmptiedReopen tests that a log emptied by Truncate or Compact keeps its
+// offsets increasing across a restart
+func TestFileStoreEmptiedReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := OpenFileStore(dir)
+	store.Append("truncated", Record{Payload: []byte("a")})
+	store.Append("truncated", Record{Payload: []byte("b")})
+	store.Append("compacted", Record{Payload: []byte("a")})
+	if err := store.Truncate("truncated", 2); err != nil {
+		t.Fatalf("Truncate() returned error: %v", err)
+	}
+	if err := store.Compact("compacted", func(Record) bool { return false }); err != nil {
+		t.Fatalf("Compact() returned error: %v", err)
+	}
+	store.Close()
+
+	store, _ = OpenFileStore(dir)
+	defer store.Close()
+	for topic, want := range map[string]uint64{"truncated": 2, "compacted": 1} {
+		if records, _ := store.ReadFrom(topic, 0, 0); len(records) != 0 {
+			t.Errorf("Expected %s to stay empty after reopen, got %v", topic, records)
+		}
+		if offset, _ := store.Append(topic, Record{Payload: []byte("c")}); offset != want {
+			t.Errorf("Expected %s to continue at offset %d, got %d", topic, want, offset)
+		}
+	}
+	if topics, _ := store.Topics(); !slices.Equal(topics, []string{"compacted", "truncated"}) {
+		t.Errorf("Expected the sidecar files not to be listed as topics, got %v", topics)
+	}
+}
+
+// TestFileStoreTornTail tests that a record torn by a crash during Append is dropped
+// on reopen and leaves nothing behind for later restarts
+func TestFileStoreTornTail(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := OpenFileStore(dir)
+	store.Append("news", Record{Payload: []byte("a")})
+	store.Append("news", Record{Payload: []byte("b")})
+	store.Append("news", Record{Payload: make([]byte, 200)})
+	store.Close()
+
+	// Tear the last record, as a crash in the middle of writing it would
+	path := filepath.Join(dir, "news.log")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() returned error: %v", err)
+	}
+	if err := os.Truncate(path, info.Size()-10); err != nil {
+		t.Fatalf("Truncate() returned error: %v", err)
+	}
+
+	store, _ = OpenFileStore(dir)
+	if offset, _ := store.Append("news", Record{Payload: []byte("c")}); offset != 2 {
+		t.Errorf("Expected offset 2 in place of the torn record, got %d", offset)
+	}
+	store.Close()
+
+	store, _ = OpenFileStore(dir)
+	defer store.Close()
+	records, err := store.ReadFrom("news", 0, 0)
+	if err != nil {
+		t.Fatalf("ReadFrom() returned error: %v", err)
+	}
+	if got := payloads(records); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c] after the second reopen, got %v", got)
+	}
+	for i, rec := range records {
+		if rec.Offset != uint64(i) {
+			t.Errorf("Expected record %d at offset %d, got %d", i, i, rec.Offset)
+		}
+	}
+	if offset, _ := store.Append("news", Record{Payload: []byte("d")}); offset != 3 {
+		t.Errorf("Expected offset 3 after the second reopen, got %d", offset)
+	}
+}
+
+// TestSubscribeFrom tests that replay hands off to the live stream without gaps
+func TestSubscribeFrom(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			pub := NewPublisher[string](WithStore(store), WithDefaultBuffer(10))
+			topic := "test-topic"
+			pub.CreateTopic(topic)
+			for _, msg := range []string{"m0", "m1", "m2"} {
+				if err := pub.Publish(topic, msg); err != nil {
+					t.Fatalf("Publish() returned error: %v", err)
+				}
+			}
+
+			ch, err := pub.SubscribeFrom(topic, 1)
+			if err != nil {
+				t.Fatalf("SubscribeFrom() returned error: %v", err)
+			}
+			pub.Publish(topic, "m3")
+
+			var got []string
+			for range 3 {
+				got = append(got, <-ch)
+			}
+			if !slices.Equal(got, []string{"m1", "m2", "m3"}) {
+				t.Errorf("Expected [m1 m2 m3], got %v", got)
+			}
+
+			if err := pub.CloseSubscriber(topic, ch); err != nil {
+				t.Fatalf("CloseSubscriber() returned error: %v", err)
+			}
+			if _, ok := <-ch; ok {
+				t.Error("Expected channel to be closed")
+			}
+		})
+	}
+}
+
+// TestSubscribeFromRestart tests replaying messages stored by a previous Publisher
+func TestSubscribeFromRestart(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := OpenFileStore(dir)
+	pub := NewPublisher[string](WithStore(store), WithCodec(GobCodec))
+	pub.CreateTopic("news")
+	pub.Publish("news", "before restart")
+	store.Close()
+
+	store, _ = OpenFileStore(dir)
+	defer store.Close()
+	pub = NewPublisher[string](WithStore(store), WithCodec(GobCodec))
+	pub.CreateTopic("news")
+	ch, err := pub.SubscribeFrom("news", 0)
+	if err != nil {
+		t.Fatalf("SubscribeFrom() returned error: %v", err)
+	}
+	if msg := <-ch; msg != "before restart" {
+		t.Errorf("Expected 'before restart', got '%s'", msg)
+	}
+	pub.CloseTopic("news")
+}
+
+// TestSubscribeFromWithoutStore tests the error for publishers that keep no log
+func TestSubscribeFromWithoutStore(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("news")
+	if _, err := pub.SubscribeFrom("news", 0); !errors.Is(err, ErrNoStore) {
+		t.Errorf("Expected ErrNoStore, got %v", err)
+	}
+}
+
+// TestSubscribeSince tests that only messages published after the given time are replayed
+func TestSubscribeSince(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(100, 0))
+	pub := NewPublisher[string](WithStore(NewMemoryStore()), WithClock(fake), WithDefaultBuffer(10))
+	topic := "test-topic"
+	pub.CreateTopic(topic)
+
+	pub.Publish(topic, "old")
+	fake.Advance(time.Second)
+	pub.Publish(topic, "boundary") // published exactly at since: not "after"
+	since := fake.Now()
+	fake.Advance(time.Second)
+	pub.Publish(topic, "new")
+
+	ch, err := pub.SubscribeSince(topic, since)
+	if err != nil {
+		t.Fatalf("SubscribeSince() returned error: %v", err)
+	}
+	pub.Publish(topic, "live")
+
+	var got []string
+	for range 2 {
+		got = append(got, <-ch)
+	}
+	if !slices.Equal(got, []string{"new", "live"}) {
+		t.Errorf("Expected [new live], got %v", got)
+	}
+	if extra := drain(ch); len(extra) != 0 {
+		t.Errorf("Expected no duplicates, got %v", extra)
+	}
+	pub.CloseTopic(topic)
+}
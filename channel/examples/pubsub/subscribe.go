@@ -1,13 +1,15 @@
 package main
 
-import "errors"
+import (
+	"errors"
+)
 
 // Subscribe allows a subscriber to register for messages from a specific topic.
-// Returns a receive-only channel (<-chan string) that the subscriber can use to receive messages.
+// Returns a receive-only channel (<-chan T) that the subscriber can use to receive messages.
 //
 // Go Concurrency Patterns used:
-//   - Channel creation: Creates a buffered channel (capacity 1) for the subscriber
-//   - Receive-only channel: Returns <-chan string to prevent subscribers from sending
+//   - Channel creation: Creates a buffered channel (capacity 1 unless WithDefaultBuffer is set)
+//   - Receive-only channel: Returns <-chan T to prevent subscribers from sending
 //   - Channel-based communication: Messages flow through channels between goroutines
 //   - Lock for map modification: Uses exclusive lock to safely append to subscribers slice
 //
@@ -19,10 +21,12 @@ import "errors"
 //
 // Parameters:
 //   - topic: string - the topic name to subscribe to
+//   - opts: ...SubscribeOption - per-subscription settings (e.g. WithRateLimit)
 //
 // Returns:
-//   - <-chan string: receive-only channel for receiving messages
-//   - error: returns error if topic doesn't exist
+//   - <-chan T: receive-only channel for receiving messages
+//   - error: returns error if topic doesn't exist, or ErrUnauthorized when an Authorizer
+//     is configured and the Anonymous principal may not subscribe (see SubscribeAs)
 //
 // Usage example:
 //
@@ -31,20 +35,108 @@ import "errors"
 //		for msg := range ch {
 //	 	Process message
 //		}
-func (p *Publisher) Subscribe(topic string) (<-chan string, error) {
-	p.Lock()         // Acquire exclusive write lock (modifying subscribers map)
-	defer p.Unlock() // Ensure lock is released
+func (p *Publisher[T]) Subscribe(topic string, opts ...SubscribeOption) (<-chan T, error) {
+	return p.SubscribeAs(Anonymous, topic, opts...)
+}
 
-	// Create buffered channel with capacity 1 for this subscriber
-	// Buffered channel prevents blocking if subscriber is slow to read
-	channel := make(chan string, 1)
+// subscribe registers a new subscriber channel once authorization has passed. With
+// replay the topic's history (WithReplay) is delivered first, otherwise its retained
+// message (WithRetain), if any.
+func (p *Publisher[T]) subscribe(topic string, replay bool, opts []SubscribeOption) (<-chan T, error) {
+	topic = p.resolve(topic)
+	settings := newSubscribeConfig(opts)
+	if settings.catchUpBatch > 0 || settings.credits {
+		return nil, errNeedsLog
+	}
+
+	s := p.shard(topic)
+	s.Lock()         // Acquire exclusive write lock (modifying subscribers map)
+	defer s.Unlock() // Ensure lock is released
 
 	// Check if topic exists
-	if _, ok := p.subscribers[topic]; !ok {
+	if _, ok := s.subscribers[topic]; !ok {
 		return nil, errors.New("topic not found")
 	}
 
-	// Add subscriber's channel to the topic's subscriber list
-	p.subscribers[topic] = append(p.subscribers[topic], channel)
+	// History, a snapshot or a retained message goes first; the write lock keeps
+	// publishes out until sub is registered, so nothing is missed or delivered twice
+	var backlog []T
+	switch state := s.topics[topic]; {
+	case replay && state.history != nil:
+		backlog = p.unexpired(state.history.items())
+	case state.snapshot != nil:
+		backlog = state.snapshot()
+	case state.retained != nil:
+		backlog = p.unexpired([]Message[T]{*state.retained})
+	}
+	if len(backlog) > 0 {
+		return p.subscribeBacklogLocked(s, topic, backlog, settings)
+	}
+
+	// Create buffered channel (capacity from WithDefaultBuffer, 1 by default)
+	// Buffered channel prevents blocking if subscriber is slow to read
+	channel := make(chan T, p.config.buffer)
+
+	sub := &subscriber[T]{ch: channel, out: channel, buffered: p.config.buffer}
+	if err := p.addSubscriberLocked(s, topic, sub, settings); err != nil {
+		return nil, err
+	}
 	return channel, nil
 }
+
+// subscribeBacklogLocked registers a subscriber of topic that receives backlog before
+// the live messages. A pump goroutine forwards both, so a backlog larger than the
+// buffer never blocks the lock holder. Must be called with the shard's write lock held.
+func (p *Publisher[T]) subscribeBacklogLocked(s *topicShard[T], topic string, backlog []T, settings subscribeConfig) (<-chan T, error) {
+	live := make(chan T, p.config.buffer)
+	out := make(chan T, p.config.buffer)
+	sub := &subscriber[T]{ch: live, out: out, done: make(chan struct{}), buffered: 2 * p.config.buffer}
+	if err := p.addSubscriberLocked(s, topic, sub, settings); err != nil {
+		return nil, err
+	}
+	p.goroutines.Go(func() { pump(backlog, live, out, sub.done, nil) })
+	return out, nil
+}
+
+// addSubscriberLocked assigns sub an id and registers it as a subscriber of topic,
+// unless topic is at its WithMaxSubscribers quota or sub's buffers would exceed the
+// namespace's WithMaxBuffered limit.
+// Must be called with the write lock of topic's shard s held and topic known to exist.
+func (p *Publisher[T]) addSubscriberLocked(s *topicShard[T], topic string, sub *subscriber[T], settings subscribeConfig) error {
+	if limit, n := p.limits.Load().maxSubscribers, len(s.subscribers[topic]); limit > 0 && n >= limit {
+		return &QuotaError{Quota: "WithMaxSubscribers", Namespace: p.namespace, Topic: topic, Limit: int64(limit), Used: int64(n)}
+	}
+	if err := p.reserveBuffered(sub.buffered); err != nil {
+		return err
+	}
+	sub.id = p.nextID.Add(1)
+
+	// Install the delivery quota, if any, in the shared keyed limiter
+	sub.overflow = settings.overflow
+	sub.queue = settings.queueGroup
+	if settings.dedupWindow > 0 {
+		sub.dedup = newDedupWindow(p.config.clock, settings.dedupWindow)
+	}
+	p.joinQueueLocked(s.topics[topic], sub)
+	if settings.ratePerSecond > 0 {
+		p.limiter.Set(sub.id, settings.ratePerSecond, settings.rateBurst)
+		sub.limited = true
+	}
+
+	// Add subscriber to the topic's subscriber list
+	s.subscribers[topic] = append(s.subscribers[topic], sub)
+	p.subscribedLocked(topic, sub)
+	if p.debugEnabled() {
+		p.config.logger.Debug("pubsub: subscribed", "topic", topic, "subscribers", len(s.subscribers[topic]))
+	}
+	return nil
+}
+
+// removeSubscriberLocked stops delivery to sub and releases its quota and buffer
+// accounting. The caller removes sub from the topic's list.
+func (p *Publisher[T]) removeSubscriberLocked(topic string, sub *subscriber[T]) {
+	sub.close()
+	p.unsubscribedLocked(topic, sub)
+	p.limiter.Remove(sub.id)
+	p.releaseBuffered(sub.buffered)
+}
@@ -0,0 +1,159 @@
+package main
+
+import "errors"
+
+// Subscription is an envelope subscription to a topic (SubscribeLog, SubscribeGroup,
+// SubscribeMessages): unlike Subscribe's plain value channel, every Message carries its
+// offset, key and publish time, and deletes arrive as tombstones. This is what consumers need to rebuild state from a keyed topic.
+type Subscription[T any] struct {
+	C <-chan Message[T] // Replayed messages, then live ones; closed by Close or CloseTopic
+
+	pub   *Publisher[T]
+	topic string
+	group string // Consumer group for Commit, empty unless SubscribeGroup
+	sub   *subscriber[T]
+	parts []*Subscription[T] // One per topic of a SubscribeMany subscription, which has no topic or sub itself
+}
+
+// ErrNoGroup is returned by Commit on subscriptions not created with SubscribeGroup.
+var ErrNoGroup = errors.New("subscription has no consumer group")
+
+// errNeedsLog is returned when WithCatchUp or WithCredits is used with a plain subscription.
+var errNeedsLog = errors.New("catch-up mode and credits require SubscribeLog or SubscribeGroup")
+
+// ErrNoOffsetStore is returned by SubscribeGroup when the store does not implement OffsetStore.
+var ErrNoOffsetStore = errors.New("store does not persist consumer offsets")
+
+// SubscribeLog subscribes to topic's log starting at offset: the stored messages with
+// an offset >= offset are replayed first, then live messages follow with the same
+// hand-off guarantees as SubscribeFrom.
+//
+// Usage example (rebuilding the latest state of a compacted topic):
+//
+//	sub, err := pub.SubscribeLog("prices", 0)
+//	if err != nil { ... }
+//	state := map[string]string{}
+//	for msg := range sub.C {
+//		if msg.Deleted { delete(state, msg.Key) } else { state[msg.Key] = msg.Value }
+//	}
+func (p *Publisher[T]) SubscribeLog(topic string, offset uint64, opts ...SubscribeOption) (*Subscription[T], error) {
+	return p.subscribeLog(topic, "", func(OffsetStore) (uint64, error) { return offset, nil }, opts)
+}
+
+// SubscribeGroup subscribes to topic as a member of the consumer group group, resuming
+// right after the group's last committed offset (from the start of the log if the group
+// never committed). Offsets are persisted in the Publisher's store, which must implement
+// OffsetStore, so a restarted consumer picks up where it left off.
+//
+// Messages are delivered at least once: anything received after the last Commit is
+// delivered again on resume.
+//
+// Usage example:
+//
+//	sub, err := pub.SubscribeGroup("orders", "billing")
+//	if err != nil { ... }
+//	for msg := range sub.C {
+//		process(msg)
+//		sub.Commit(msg.Offset)
+//	}
+func (p *Publisher[T]) SubscribeGroup(topic, group string, opts ...SubscribeOption) (*Subscription[T], error) {
+	return p.subscribeLog(topic, group, func(offsets OffsetStore) (uint64, error) {
+		if offsets == nil {
+			return 0, ErrNoOffsetStore
+		}
+		committed, ok, err := offsets.CommittedOffset(group, p.qualified(topic))
+		if err != nil || !ok {
+			return 0, err
+		}
+		return committed + 1, nil
+	}, opts)
+}
+
+// Commit records that the subscription's consumer group has processed every message
+// up to and including offset. The next SubscribeGroup for the group resumes after it.
+func (s *Subscription[T]) Commit(offset uint64) error {
+	if s.group == "" {
+		return ErrNoGroup
+	}
+	return s.pub.config.store.(OffsetStore).CommitOffset(s.group, s.pub.qualified(s.topic), offset)
+}
+
+// subscribeLog implements SubscribeLog and SubscribeGroup; start picks the first offset
+// to replay given the store's OffsetStore (nil if it does not implement one).
+func (p *Publisher[T]) subscribeLog(topic, group string, start func(OffsetStore) (uint64, error), opts []SubscribeOption) (*Subscription[T], error) {
+	if err := p.authorizeSubscribe(Anonymous, topic); err != nil {
+		return nil, err
+	}
+	if p.config.store == nil {
+		return nil, ErrNoStore
+	}
+	offsets, _ := p.config.store.(OffsetStore)
+	offset, err := start(offsets)
+	if err != nil {
+		return nil, err
+	}
+	settings := newSubscribeConfig(opts)
+
+	topic = p.resolve(topic)
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.subscribers[topic]; !ok {
+		return nil, errors.New("topic not found")
+	}
+
+	live := make(chan Message[T], p.config.buffer)
+	out := make(chan Message[T], p.config.buffer)
+	sub := &subscriber[T]{msgs: live, outLog: out, done: make(chan struct{}), buffered: 2 * p.config.buffer}
+	if settings.credits {
+		sub.credits = newCreditGate(settings.initialCredits)
+	}
+
+	// Catch-up subscribers start lagging: the pump reads the backlog in batches
+	if settings.catchUpBatch > 0 {
+		sub.catchUp = true
+		sub.lagging.Store(true)
+		if err := p.addSubscriberLocked(s, topic, sub, settings); err != nil {
+			return nil, err
+		}
+		p.goroutines.Go(func() { p.catchUpPump(topic, sub, offset, settings.catchUpBatch, out) })
+		return &Subscription[T]{C: out, pub: p, topic: topic, group: group, sub: sub}, nil
+	}
+
+	backlog, err := p.replayLocked(topic, offset, func(Record) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	if err := p.addSubscriberLocked(s, topic, sub, settings); err != nil {
+		return nil, err
+	}
+	p.goroutines.Go(func() { pump(backlog, live, out, sub.done, sub.credits) })
+	return &Subscription[T]{C: out, pub: p, topic: topic, group: group, sub: sub}, nil
+}
+
+// Close ends the subscription and closes C. Closing twice, or after the topic was
+// closed, returns "subscriber not found"; a SubscribeMany subscription returns it once
+// all of its topics are gone.
+func (s *Subscription[T]) Close() error {
+	if s.parts != nil {
+		closed := false
+		for _, part := range s.parts {
+			closed = part.Close() == nil || closed
+		}
+		if !closed {
+			return errors.New("subscriber not found")
+		}
+		return nil
+	}
+	p := s.pub
+	topic := p.resolve(s.topic) // Renamed since (RenameTopic)
+	shard := p.shard(topic)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if !p.dropSubscriberLocked(shard, topic, s.sub) {
+		return errors.New("subscriber not found")
+	}
+	return nil
+}
@@ -1,7 +1,139 @@
 package main
 
-func (p *Publisher) CreateTopic(topic string) {
-	p.Lock()
-	defer p.Unlock()
-	p.subscribers[topic] = make([]chan string, 0)
+import (
+	"fmt"
+	"time"
+)
+
+// topicConfig collects per-topic settings given to CreateTopic.
+type topicConfig struct {
+	compactEvery time.Duration // Background compaction interval, 0 means not compacted
+	retain       bool          // Keep the last message for new subscribers (WithRetain)
+	replay       int           // Messages kept for SubscribeWithReplay (WithReplay), 0 keeps none
+	maxAge       time.Duration // Stored records older than this are discarded (WithRetention)
+	maxBytes     int64         // Stored key and payload bytes kept (WithRetention)
+	retainEvery  time.Duration // Retention interval, 0 means the log is never trimmed
+	partitions   int           // Partitions keyed messages are routed by (WithPartitions), 0 means none
+	ttl          time.Duration // Time to live of the topic's messages (WithTTL), 0 means forever
+
+	onPressure         func(PressureEvent) // Called when the backlog crosses a threshold (WithPressureThresholds)
+	pressureThresholds []int               // Backlog thresholds, ascending
+}
+
+// TopicOption configures a topic (same functional options pattern as Option).
+type TopicOption func(*topicConfig)
+
+// WithCompaction turns the topic into a compacted, keyed topic: every interval a
+// background goroutine rewrites the stored log so it keeps only the latest message
+// per key (see Compact). Requires a store implementing Compactor (MemoryStore and
+// FileStore do); otherwise the compactor logs a warning and the log is left as is.
+func WithCompaction(interval time.Duration) TopicOption {
+	return func(c *topicConfig) {
+		c.compactEvery = interval
+	}
+}
+
+// WithRetain keeps the topic's most recent message and delivers it to every new
+// Subscribe before any live message (MQTT retained-message semantics), so subscribers
+// of configuration or state topics start with the current value instead of waiting
+// for the next change. A DeleteKey on the topic clears the retained message.
+//
+// SubscribeFrom, SubscribeSince and log subscriptions replay the store instead.
+func WithRetain() TopicOption {
+	return func(c *topicConfig) {
+		c.retain = true
+	}
+}
+
+// CreateTopic registers topic so it can be published and subscribed to.
+// Re-creating an existing topic drops its subscriber list and applies the new options.
+//
+// Parameters:
+//   - topic: string - the topic name
+//   - opts: ...TopicOption - per-topic settings (e.g. WithCompaction)
+//
+// Returns:
+//   - error: ErrNamespaceLimit (wrapped) if the namespace already has WithMaxTopics topics,
+//     ErrCrossNamespace (wrapped) if topic is in one of the Publisher's namespaces
+func (p *Publisher[T]) CreateTopic(topic string, opts ...TopicOption) error {
+	if err := p.checkNamespace(topic); err != nil {
+		return err
+	}
+	state := &topicState[T]{done: make(chan struct{}), parallel: p.config.topicOptions.Load().deliveryConcurrency[p.qualified(topic)]}
+	for _, opt := range opts {
+		opt(&state.settings)
+	}
+	if state.settings.replay > 0 {
+		state.history = newRing[Message[T]](state.settings.replay)
+	}
+
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+	if p.stopping.Load() {
+		return ErrShutdown
+	}
+	if p.isAlias(topic) {
+		return fmt.Errorf("%w: %q is an alias", errTopicExists, topic)
+	}
+	old, exists := s.topics[topic]
+	if !exists {
+		if err := p.reserveTopic(); err != nil {
+			return err
+		}
+	}
+	if err := p.rehydrateLocked(topic, state); err != nil {
+		if !exists {
+			p.releaseTopic()
+		}
+		return err
+	}
+	for _, sub := range s.subscribers[topic] {
+		p.releaseBuffered(sub.buffered) // Dropped subscribers no longer count against the namespace
+		p.unsubscribedLocked(topic, sub)
+	}
+	s.subscribers[topic] = make([]*subscriber[T], 0)
+	if exists {
+		close(old.done) // Stop the previous incarnation's background goroutines
+	}
+	s.topics[topic] = state
+	if state.settings.compactEvery > 0 && p.config.store != nil {
+		p.goroutines.Go(func() { p.compactLoop(topic, state.settings.compactEvery, state.done) })
+	}
+	if state.settings.retainEvery > 0 && p.config.store != nil {
+		p.goroutines.Go(func() { p.retentionLoop(topic, state.settings.retainEvery, state.done) })
+	}
+	p.topicCreatedLocked(topic)
+	if p.debugEnabled() {
+		p.config.logger.Debug("pubsub: topic created", "topic", topic)
+	}
+	return nil
+}
+
+// rehydrateLocked fills state's retained message and replay history from the stored
+// log of topic, so they survive a restart. Called with the shard's write lock held.
+func (p *Publisher[T]) rehydrateLocked(topic string, state *topicState[T]) error {
+	if p.config.store == nil || !state.settings.retain && state.history == nil {
+		return nil
+	}
+	messages, err := p.replayLocked(topic, 0, func(Record) bool { return true })
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		if state.settings.ttl > 0 {
+			msg.Expires = msg.Time.Add(state.settings.ttl)
+		}
+		if msg.Deleted {
+			state.retained = nil
+			continue
+		}
+		if state.settings.retain {
+			state.retained = &msg
+		}
+		if state.history != nil {
+			state.history.push(msg)
+		}
+	}
+	return nil
 }
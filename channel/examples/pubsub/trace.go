@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"os"
+	"runtime/trace"
+)
+
+// startTraceFromEnv records a runtime trace into the file named by the TRACE
+// environment variable, e.g. `TRACE=pubsub.out go run .` followed by
+// `go tool trace pubsub.out`. It returns a stop function; when TRACE is unset
+// nothing is recorded and stop is a no-op.
+func startTraceFromEnv() (stop func(), err error) {
+	path := os.Getenv("TRACE")
+	if path == "" {
+		return func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		trace.Stop()
+		f.Close()
+	}, nil
+}
+
+// traceTask starts a runtime/trace task for one logical broker operation and tags it
+// with the topic, so `go tool trace` groups the goroutine work by publish instead of
+// showing anonymous goroutines.
+//
+// When no trace is being recorded it returns ctx unchanged and a no-op end function,
+// so the hot path doesn't allocate tasks nobody will look at.
+//
+// Parameters:
+//   - ctx: context.Context - parent context, e.g. the one given to PublishContext
+//   - name: string - task type shown in the "User-defined tasks" view (e.g. "pubsub.Publish")
+//   - topic: string - logged on the task as the "topic" annotation
+//
+// Returns:
+//   - context.Context: carries the task; pass it to traceRegion
+//   - func(): ends the task, call it with defer
+func traceTask(ctx context.Context, name, topic string) (context.Context, func()) {
+	if !trace.IsEnabled() {
+		return ctx, func() {}
+	}
+	ctx, task := trace.NewTask(ctx, name)
+	trace.Log(ctx, "topic", topic)
+	return ctx, task.End
+}
+
+// traceRegion runs fn inside a named trace region belonging to the task in ctx.
+// Regions show up nested under the task, e.g. each subscriber delivery of a publish.
+func traceRegion(ctx context.Context, name string, fn func()) {
+	trace.WithRegion(ctx, name, fn)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+)
+
+// TestPublishWhileTracing tests that Publish delivers normally while a runtime trace is recorded
+func TestPublishWhileTracing(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("tracing unavailable: %v", err)
+	}
+	defer trace.Stop()
+
+	pub := NewPublisher[string]()
+	pub.CreateTopic("test-topic")
+	ch, err := pub.Subscribe("test-topic")
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+
+	if err := pub.Publish("test-topic", "traced message"); err != nil {
+		t.Fatalf("Publish() returned error: %v", err)
+	}
+	if msg := <-ch; msg != "traced message" {
+		t.Errorf("Expected message 'traced message', got '%s'", msg)
+	}
+}
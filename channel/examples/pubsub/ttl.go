@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrExpired is returned by Publish when the message's TTL ran out before delivery
+// started, and is the DeadLetter reason for messages that expired on their way to a
+// subscriber.
+var ErrExpired = errors.New("message expired")
+
+// WithTTL gives every message published to the topic a time to live: a message that
+// could not be handed to a subscriber within ttl of being published is dropped for that
+// subscriber instead of arriving stale. This covers messages waiting in the async
+// delivery queue (WithAsyncDelivery), publishes blocked on a full subscriber channel
+// (OverflowBlock), and retained or replayed messages (WithRetain, WithReplay) offered
+// to new subscribers. Expired messages go to the dead-letter topic when WithDeadLetters
+// is set.
+//
+// Once in a subscriber's channel a message is the subscriber's, and replays from the
+// store (SubscribeFrom, SubscribeLog) are never filtered. PublishWithTTL overrides ttl
+// for one message.
+func WithTTL(ttl time.Duration) TopicOption {
+	return func(c *topicConfig) {
+		c.ttl = max(ttl, 0)
+	}
+}
+
+// PublishWithTTL is Publish with a time to live for this message only, overriding the
+// topic's WithTTL. See WithTTL for what expiry means.
+func (p *Publisher[T]) PublishWithTTL(topic string, message T, ttl time.Duration) error {
+	if err := p.authorizePublish(Anonymous, topic); err != nil {
+		return err
+	}
+	msg := Message[T]{Value: message, Expires: p.config.clock.Now().Add(ttl)}
+	return p.publish(context.Background(), topic, msg)
+}
+
+// stampExpiry sets msg's expiry from the topic's TTL, unless the message has its own.
+func (p *Publisher[T]) stampExpiry(state *topicState[T], msg *Message[T]) {
+	if msg.Expires.IsZero() && state.settings.ttl > 0 {
+		msg.Expires = p.config.clock.Now().Add(state.settings.ttl)
+	}
+}
+
+// timeLeft returns how long msg may still wait for a subscriber, and false once it
+// has expired. Messages without a TTL never expire.
+func (p *Publisher[T]) timeLeft(msg Message[T]) (time.Duration, bool) {
+	if msg.Expires.IsZero() {
+		return 0, true
+	}
+	left := msg.Expires.Sub(p.config.clock.Now())
+	return left, left > 0
+}
+
+// waitLimit returns how long a blocking delivery of msg may wait: the stall limit
+// (WithSlowSubscriberEviction) or the time msg has left, whichever is shorter.
+func (p *Publisher[T]) waitLimit(msg Message[T]) time.Duration {
+	left, _ := p.timeLeft(msg)
+	if msg.Expires.IsZero() || p.config.stallLimit > 0 && p.config.stallLimit < left {
+		return p.config.stallLimit
+	}
+	return left
+}
+
+// expire records that msg expired before reaching sub.
+func (p *Publisher[T]) expire(topic string, sub *subscriber[T], msg Message[T]) {
+	p.metrics.expired.Add(1)
+	p.deadLetter(topic, sub, msg, ErrExpired)
+}
+
+// unexpired returns the messages of backlog that have not expired yet.
+func (p *Publisher[T]) unexpired(backlog []Message[T]) []T {
+	values := make([]T, 0, len(backlog))
+	for _, msg := range backlog {
+		if _, ok := p.timeLeft(msg); ok {
+			values = append(values, msg.Value)
+		} else {
+			p.metrics.expired.Add(1)
+		}
+	}
+	return values
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+	"goconcurrency/testkit"
+)
+
+// TestTTLBlockedDelivery tests that a publish blocked on a full subscriber gives up
+// when the message expires, and dead-letters it
+func TestTTLBlockedDelivery(t *testing.T) {
+	testkit.Run(t, func(t *testing.T, s *testkit.Scheduler) {
+		pub := NewPublisher[string](WithClock(s.Clock), WithDefaultBuffer(1), WithDeadLetters())
+		dead, _ := pub.SubscribeDeadLetters()
+		pub.CreateTopic("quotes", WithTTL(time.Second))
+		ch, _ := pub.Subscribe("quotes")
+
+		pub.Publish("quotes", "q1")
+		s.Go(func() {
+			if err := pub.Publish("quotes", "q2"); err != nil {
+				t.Errorf("Publish() returned error: %v", err)
+			}
+		})
+		s.Advance(time.Second) // The publish blocks on ch until q2 expires
+		s.Wait()
+
+		if letter := <-dead; !errors.Is(letter.Reason, ErrExpired) || letter.Message.Value != "q2" {
+			t.Errorf("Expected q2 dead-lettered as ErrExpired, got %+v", letter)
+		}
+		if msg := <-ch; msg != "q1" || len(ch) != 0 {
+			t.Errorf("Expected only q1 delivered, got %q and %d more", msg, len(ch))
+		}
+		if n := pub.metrics.expired.Load(); n != 1 {
+			t.Errorf("Expected 1 expired delivery counted, got %d", n)
+		}
+	})
+}
+
+// TestPublishWithTTL tests that a message whose TTL ran out before delivery started
+// is not published
+func TestPublishWithTTL(t *testing.T) {
+	pub := NewPublisher[string]()
+	pub.CreateTopic("quotes")
+	ch, _ := pub.Subscribe("quotes")
+
+	if err := pub.PublishWithTTL("quotes", "stale", 0); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+	if err := pub.PublishWithTTL("quotes", "fresh", time.Minute); err != nil {
+		t.Errorf("PublishWithTTL() returned error: %v", err)
+	}
+	if msg := <-ch; msg != "fresh" {
+		t.Errorf("Expected fresh, got %q", msg)
+	}
+	if n := pub.metrics.published.Load(); n != 1 {
+		t.Errorf("Expected 1 message published, got %d", n)
+	}
+}
+
+// TestTTLRetainedAndReplay tests that expired retained and history messages are not
+// delivered to new subscribers
+func TestTTLRetainedAndReplay(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(1, 0))
+	pub := NewPublisher[string](WithClock(fake), WithDefaultBuffer(4))
+	pub.CreateTopic("state", WithRetain(), WithReplay(4), WithTTL(time.Minute))
+
+	pub.Publish("state", "old")
+	fake.Advance(30 * time.Second)
+	pub.Publish("state", "new")
+	fake.Advance(45 * time.Second) // old has expired, new has not
+
+	replayed, _ := pub.SubscribeWithReplay("state")
+	if msg := <-replayed; msg != "new" {
+		t.Errorf("Expected only new replayed, got %q", msg)
+	}
+	fake.Advance(time.Minute)
+	retained, _ := pub.Subscribe("state")
+	if len(retained) != 0 {
+		t.Errorf("Expected no retained message after expiry, got %q", <-retained)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidMessage is returned (wrapped together with the validator's error) by
+// Publish when the topic's validator (see SetValidator) rejects the message.
+var ErrInvalidMessage = errors.New("invalid message")
+
+// SetValidator installs validate as topic's schema check: every message published to
+// topic (routed ones included) is passed to it first, and one it returns an error for
+// is not published. Publish returns ErrInvalidMessage wrapped together with that
+// error, so both errors.Is(err, ErrInvalidMessage) and the validator's own error can be
+// tested. With WithDeadLetters the rejected message also goes to the dead-letter topic,
+// with Subscriber 0. Deletes (DeleteKey) carry no value and are not validated.
+//
+// validate runs on the publishing goroutine without any lock held, after the publish
+// middleware (see Use). A nil validate removes the check. The validator belongs to the
+// topic: it is gone once the topic is closed or created again.
+//
+// Usage example:
+//
+//	pub.SetValidator("orders", func(o Order) error {
+//		if o.Total <= 0 {
+//			return errors.New("order total must be positive")
+//		}
+//		return nil
+//	})
+func (p *Publisher[T]) SetValidator(topic string, validate func(T) error) error {
+	s := p.shard(topic)
+	s.Lock()
+	defer s.Unlock()
+
+	state, ok := s.topics[topic]
+	if !ok {
+		return errors.New("topic not found")
+	}
+	state.validate = validate
+	return nil
+}
+
+// validate runs topic's validator, if any, on msg and dead-letters msg if it fails.
+func (p *Publisher[T]) validate(topic string, msg Message[T]) error {
+	if msg.Deleted {
+		return nil
+	}
+	s := p.shard(topic)
+	s.RLock()
+	var validate func(T) error
+	if state, ok := s.topics[topic]; ok {
+		validate = state.validate
+	}
+	s.RUnlock()
+	if validate == nil {
+		return nil // An unknown topic is reported by the delivery
+	}
+	err := validate(msg.Value)
+	if err == nil {
+		return nil
+	}
+	p.metrics.invalid.Add(1)
+	p.deadLetter(topic, nil, msg, err)
+	return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestSetValidator tests that invalid messages are rejected with the validator's error
+// and dead-lettered, while valid ones are delivered
+func TestSetValidator(t *testing.T) {
+	pub := NewPublisher[string](WithDefaultBuffer(4), WithDeadLetters())
+	dead, _ := pub.SubscribeDeadLetters()
+	pub.CreateTopic("orders")
+	ch, _ := pub.Subscribe("orders")
+	errEmpty := errors.New("empty order")
+	if err := pub.SetValidator("orders", func(order string) error {
+		if strings.TrimSpace(order) == "" {
+			return errEmpty
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("SetValidator() returned error: %v", err)
+	}
+
+	err := pub.Publish("orders", " ")
+	if !errors.Is(err, ErrInvalidMessage) || !errors.Is(err, errEmpty) {
+		t.Errorf("Expected ErrInvalidMessage and the validator's error, got %v", err)
+	}
+	if letter := <-dead; letter.Subscriber != 0 || !errors.Is(letter.Reason, errEmpty) || letter.Message.Value != " " {
+		t.Errorf("Expected the rejected message as a dead letter, got %+v", letter)
+	}
+	if err := pub.Publish("orders", "o1"); err != nil {
+		t.Errorf("Expected a valid message to be published, got %v", err)
+	}
+	if msg := <-ch; msg != "o1" {
+		t.Errorf("Expected only o1 delivered, got %q", msg)
+	}
+	if n := pub.metrics.invalid.Load(); n != 1 {
+		t.Errorf("Expected 1 invalid publish counted, got %d", n)
+	}
+
+	pub.SetValidator("orders", nil)
+	if err := pub.Publish("orders", ""); err != nil {
+		t.Errorf("Expected no validation after removing the validator, got %v", err)
+	}
+	if err := pub.SetValidator("missing", nil); err == nil {
+		t.Error("Expected an error for an unknown topic")
+	}
+}
@@ -0,0 +1,134 @@
+// Package selectx receives from a dynamic set of channels with a chosen preference,
+// which a plain select cannot express: when several cases are ready, select picks one
+// uniformly at random. It generalizes the select demo in channel/unbuffered/example_2:
+//
+//	for {
+//		v, i, ok := selectx.Prioritized(ctx, urgent, normal, background)
+//		if i < 0 {
+//			return ctx.Err()
+//		}
+//		if !ok {
+//			... channel i is closed, drop it from the set
+//		}
+//		handle(v)
+//	}
+package selectx
+
+import (
+	"context"
+	"math/rand/v2"
+	"reflect"
+)
+
+// Prioritized receives one value from chans with strict priority: if chans[0] has a
+// value ready it is always taken, else chans[1], and so on. If none is ready it blocks
+// until one is, or until ctx is done.
+//
+// It uses the double-check pattern: a non-blocking pass in priority order, then a
+// blocking select over every channel. The first pass is what makes the priority
+// strict for everything ready at call time; values that become ready simultaneously
+// while blocked are, as with select, taken in no particular order.
+//
+// Returns the value, the index of the channel it came from, and ok=false if that
+// channel is closed (a closed channel is always ready, so callers should drop it).
+// If ctx is done first the index is -1. Nil channels are never ready, as in select.
+func Prioritized[T any](ctx context.Context, chans ...<-chan T) (value T, index int, ok bool) {
+	// First check: strictly in priority order, without blocking
+	for i, ch := range chans {
+		if ch == nil {
+			continue
+		}
+		select {
+		case value, ok = <-ch:
+			return value, i, ok
+		default:
+		}
+	}
+	// Second check: nothing was ready, wait for whichever is first
+	return wait(ctx, chans)
+}
+
+// Weighted receives one value from chans, preferring ready channels in proportion to
+// weights: when every channel has a value ready, chans[i] is chosen with probability
+// weights[i]/sum(weights). Unlike Prioritized, low-weight channels are never starved.
+// A channel with weight <= 0 is only chosen when no positively weighted channel is ready.
+//
+// r is the random source (nil uses the global one); pass a seeded generator for
+// reproducible tests. Return values are as for Prioritized.
+//
+// It panics if len(weights) != len(chans).
+func Weighted[T any](ctx context.Context, r *rand.Rand, weights []int, chans ...<-chan T) (value T, index int, ok bool) {
+	if len(weights) != len(chans) {
+		panic("selectx: weights and chans differ in length")
+	}
+	order := weightedOrder(r, weights)
+	ordered := make([]<-chan T, len(chans))
+	for i, idx := range order {
+		ordered[i] = chans[idx]
+	}
+	value, i, ok := Prioritized(ctx, ordered...)
+	if i < 0 {
+		return value, -1, ok
+	}
+	return value, order[i], ok
+}
+
+// weightedOrder returns the channel indexes in a random order where each position is
+// drawn with probability proportional to the remaining weights (sampling without
+// replacement). Non-positive weights go last.
+func weightedOrder(r *rand.Rand, weights []int) []int {
+	intN := rand.IntN
+	if r != nil {
+		intN = r.IntN
+	}
+
+	remaining := make([]int, 0, len(weights))
+	var zero []int
+	total := 0
+	for i, w := range weights {
+		if w > 0 {
+			remaining = append(remaining, i)
+			total += w
+		} else {
+			zero = append(zero, i)
+		}
+	}
+
+	order := make([]int, 0, len(weights))
+	for len(remaining) > 0 {
+		pick := intN(total)
+		for j, idx := range remaining {
+			if pick < weights[idx] {
+				order = append(order, idx)
+				total -= weights[idx]
+				remaining = append(remaining[:j], remaining[j+1:]...)
+				break
+			}
+			pick -= weights[idx]
+		}
+	}
+	return append(order, zero...)
+}
+
+// wait blocks on every channel and ctx.Done at once. The number of channels is only
+// known at run time, so this uses reflect.Select.
+func wait[T any](ctx context.Context, chans []<-chan T) (value T, index int, ok bool) {
+	cases := make([]reflect.SelectCase, 0, len(chans)+1)
+	for _, ch := range chans {
+		c := reflect.SelectCase{Dir: reflect.SelectRecv}
+		if ch != nil {
+			c.Chan = reflect.ValueOf(ch)
+		}
+		cases = append(cases, c)
+	}
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+
+	chosen, recv, recvOK := reflect.Select(cases)
+	if chosen == len(chans) {
+		return value, -1, false
+	}
+	if recvOK {
+		value, _ = recv.Interface().(T) // comma-ok: a nil interface value does not assert
+	}
+	return value, chosen, recvOK
+}
@@ -0,0 +1,91 @@
+package selectx
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// TestPrioritizedPrefersEarlierChannels tests strict priority among ready channels
+func TestPrioritizedPrefersEarlierChannels(t *testing.T) {
+	high, low := make(chan int, 10), make(chan int, 10)
+	for i := range 5 {
+		high <- i
+		low <- 100 + i
+	}
+	for i := range 10 {
+		v, idx, ok := Prioritized(context.Background(), high, low)
+		wantIdx := 0
+		if i >= 5 {
+			wantIdx = 1
+		}
+		if idx != wantIdx || !ok {
+			t.Fatalf("receive %d: expected channel %d, got %d (value %d, ok=%v)", i, wantIdx, idx, v, ok)
+		}
+	}
+}
+
+// TestPrioritizedBlocks tests waking up on a channel that becomes ready later
+func TestPrioritizedBlocks(t *testing.T) {
+	high, low := make(chan string), make(chan string)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		low <- "late"
+	}()
+	if v, idx, ok := Prioritized(context.Background(), high, low); v != "late" || idx != 1 || !ok {
+		t.Errorf("Expected 'late' from channel 1, got %q from %d (ok=%v)", v, idx, ok)
+	}
+}
+
+// TestPrioritizedClosedAndCanceled tests closed channels, nil channels and context cancellation
+func TestPrioritizedClosedAndCanceled(t *testing.T) {
+	closed := make(chan int)
+	close(closed)
+	if _, idx, ok := Prioritized(context.Background(), nil, closed); idx != 1 || ok {
+		t.Errorf("Expected closed channel 1 with ok=false, got %d (ok=%v)", idx, ok)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, idx, _ := Prioritized(ctx, make(chan int), nil); idx != -1 {
+		t.Errorf("Expected -1 after the context is done, got %d", idx)
+	}
+
+	var nilErr chan error = make(chan error, 1)
+	nilErr <- nil
+	if v, idx, ok := Prioritized(context.Background(), nilErr); v != nil || idx != 0 || !ok {
+		t.Errorf("Expected a nil error value from channel 0, got %v from %d (ok=%v)", v, idx, ok)
+	}
+}
+
+// TestWeighted tests that ready channels are chosen in proportion to their weights
+func TestWeighted(t *testing.T) {
+	const n = 10000
+	a, b := make(chan int, 1), make(chan int, 1)
+	r := rand.New(rand.NewPCG(1, 2))
+	counts := [2]int{}
+	for range n {
+		// Keep both channels ready
+		select {
+		case a <- 0:
+		default:
+		}
+		select {
+		case b <- 1:
+		default:
+		}
+		_, idx, _ := Weighted(context.Background(), r, []int{3, 1}, a, b)
+		counts[idx]++
+	}
+	if share := float64(counts[0]) / n; share < 0.72 || share > 0.78 {
+		t.Errorf("Expected channel 0 about 75%% of the time, got %.3f (%v)", share, counts)
+	}
+
+	// Zero weight only wins when nothing else is ready
+	zero, other := make(chan int, 1), make(chan int)
+	zero <- 7
+	if v, idx, _ := Weighted(context.Background(), r, []int{0, 1}, zero, other); idx != 0 || v != 7 {
+		t.Errorf("Expected zero-weight channel when it is the only ready one, got %d from %d", v, idx)
+	}
+}
@@ -54,5 +54,6 @@ func main() {
 			fmt.Println("Received from ch2:", msg2)
 		}
 		// Note: If both channels are ready, Go randomly selects one
+		// (selectx.Prioritized and selectx.Weighted choose by priority or weight instead)
 	}
 }
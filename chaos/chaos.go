@@ -0,0 +1,126 @@
+// Package chaos wraps the examples' concurrency primitives with fault injection:
+// random delays, silently dropped deliveries and injected errors. Faults are drawn
+// from a seeded generator, so a failing run can be reproduced with the same seed.
+//
+// The wrappers work on small interfaces, so they accept the custom Channel from
+// channel/examples/custom_channel, the pubsub Publisher, or any user type with the
+// same methods.
+package chaos
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// ErrInjected is returned by wrapped operations when Config.Err is nil.
+var ErrInjected = errors.New("chaos: injected failure")
+
+// Config controls how often and how badly operations are disturbed.
+// Probabilities are in the range [0, 1]; zero disables that fault.
+type Config struct {
+	Seed uint64 // Seed for the fault generator
+
+	DelayProbability float64       // Chance an operation is delayed
+	MaxDelay         time.Duration // Delays are uniform in (0, MaxDelay]
+
+	DropProbability  float64 // Chance a send/publish is reported as successful but discarded
+	ErrorProbability float64 // Chance a send/publish fails with Err
+	Err              error   // Error to inject; defaults to ErrInjected
+
+	Clock clock.Clock // Used for delays; defaults to clock.Real
+}
+
+// Stats counts the faults injected so far.
+type Stats struct {
+	Delayed int64
+	Dropped int64
+	Errored int64
+}
+
+// Injector decides which faults to apply. One Injector can be shared by several
+// wrappers; it is safe for concurrent use.
+type Injector struct {
+	cfg Config
+
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	delayed atomic.Int64
+	dropped atomic.Int64
+	errored atomic.Int64
+}
+
+// NewInjector returns an Injector for cfg.
+func NewInjector(cfg Config) *Injector {
+	if cfg.Err == nil {
+		cfg.Err = ErrInjected
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
+	return &Injector{
+		cfg: cfg,
+		rng: rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+	}
+}
+
+// Stats returns the number of faults injected so far.
+func (inj *Injector) Stats() Stats {
+	return Stats{
+		Delayed: inj.delayed.Load(),
+		Dropped: inj.dropped.Load(),
+		Errored: inj.errored.Load(),
+	}
+}
+
+// fault is the outcome of one roll for a send-like operation.
+type fault int
+
+const (
+	faultNone fault = iota
+	faultDrop
+	faultError
+)
+
+// roll draws the delay and fault for one operation under a single lock, so the
+// sequence of decisions depends only on the seed and the order of calls.
+func (inj *Injector) roll(canFail bool) (time.Duration, fault) {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+
+	var delay time.Duration
+	if inj.cfg.MaxDelay > 0 && inj.rng.Float64() < inj.cfg.DelayProbability {
+		delay = time.Duration(inj.rng.Int64N(int64(inj.cfg.MaxDelay))) + 1
+	}
+	if !canFail {
+		return delay, faultNone
+	}
+	switch p := inj.rng.Float64(); {
+	case p < inj.cfg.ErrorProbability:
+		return delay, faultError
+	case p < inj.cfg.ErrorProbability+inj.cfg.DropProbability:
+		return delay, faultDrop
+	}
+	return delay, faultNone
+}
+
+// disturb applies the delay part of a roll and reports the fault to apply.
+func (inj *Injector) disturb(canFail bool) fault {
+	delay, f := inj.roll(canFail)
+	if delay > 0 {
+		inj.delayed.Add(1)
+		inj.cfg.Clock.Sleep(delay)
+	}
+	switch f {
+	case faultDrop:
+		inj.dropped.Add(1)
+	case faultError:
+		inj.errored.Add(1)
+	}
+	return f
+}
@@ -0,0 +1,118 @@
+package chaos
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// sliceChannel is a minimal non-blocking Channel used as the wrapped target.
+type sliceChannel struct {
+	items []int
+}
+
+func (s *sliceChannel) Send(v int) error {
+	s.items = append(s.items, v)
+	return nil
+}
+
+func (s *sliceChannel) Receive() (int, bool) {
+	if len(s.items) == 0 {
+		return 0, false
+	}
+	v := s.items[0]
+	s.items = s.items[1:]
+	return v, true
+}
+
+type recordingPublisher struct {
+	published []string
+}
+
+func (r *recordingPublisher) Publish(topic string, message string) error {
+	r.published = append(r.published, topic+":"+message)
+	return nil
+}
+
+// run sends 0..n-1 through a wrapped channel and returns what arrived and which sends failed.
+func run(seed uint64, n int) (delivered []int, failed []int, stats Stats) {
+	inner := &sliceChannel{}
+	inj := NewInjector(Config{
+		Seed:             seed,
+		DropProbability:  0.2,
+		ErrorProbability: 0.1,
+	})
+	ch := WrapChannel[int](inner, inj)
+	for i := range n {
+		if err := ch.Send(i); err != nil {
+			failed = append(failed, i)
+		}
+	}
+	return inner.items, failed, inj.Stats()
+}
+
+// TestChannelFaultsAreReproducible tests that the same seed yields the same faults
+func TestChannelFaultsAreReproducible(t *testing.T) {
+	delivered1, failed1, stats := run(42, 200)
+	delivered2, failed2, _ := run(42, 200)
+
+	if !slices.Equal(delivered1, delivered2) || !slices.Equal(failed1, failed2) {
+		t.Fatal("Expected identical fault sequence for identical seeds")
+	}
+	if stats.Dropped == 0 || stats.Errored == 0 {
+		t.Fatalf("Expected both drops and errors in 200 sends, got %+v", stats)
+	}
+	if got := int64(len(delivered1)) + stats.Dropped + stats.Errored; got != 200 {
+		t.Errorf("Expected delivered+dropped+errored = 200, got %d", got)
+	}
+	if int64(len(failed1)) != stats.Errored {
+		t.Errorf("Expected %d failed sends, got %d", stats.Errored, len(failed1))
+	}
+}
+
+// TestPublisherInjectedError tests that publish failures surface the configured error
+func TestPublisherInjectedError(t *testing.T) {
+	errBroker := errors.New("broker unavailable")
+	inner := &recordingPublisher{}
+	pub := WrapPublisher[string](inner, NewInjector(Config{ErrorProbability: 1, Err: errBroker}))
+
+	if err := pub.Publish("news", "hello"); !errors.Is(err, errBroker) {
+		t.Fatalf("Expected injected error, got %v", err)
+	}
+	if len(inner.published) != 0 {
+		t.Errorf("Expected nothing published, got %v", inner.published)
+	}
+}
+
+// TestDelaysUseClock tests that injected delays sleep on the configured clock
+func TestDelaysUseClock(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	inj := NewInjector(Config{DelayProbability: 1, MaxDelay: time.Second, Clock: fake})
+	ch := WrapChannel[int](&sliceChannel{items: []int{7}}, inj)
+
+	done := make(chan int)
+	go func() {
+		v, _ := ch.Receive()
+		done <- v
+	}()
+
+	for fake.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("Receive returned before the injected delay elapsed")
+	default:
+	}
+
+	fake.Advance(time.Second)
+	if v := <-done; v != 7 {
+		t.Errorf("Expected 7, got %d", v)
+	}
+	if inj.Stats().Delayed != 1 {
+		t.Errorf("Expected 1 delayed operation, got %d", inj.Stats().Delayed)
+	}
+}
@@ -0,0 +1,59 @@
+package chaos
+
+// Channel is the method set of the custom channel example.
+type Channel[T any] interface {
+	Send(message T) error
+	Receive() (T, bool)
+}
+
+// Publisher is the publishing side of the pubsub example.
+type Publisher[T any] interface {
+	Publish(topic string, message T) error
+}
+
+// WrapChannel returns a Channel whose Send may be delayed, dropped or failed,
+// and whose Receive may be delayed, according to inj.
+func WrapChannel[T any](ch Channel[T], inj *Injector) Channel[T] {
+	return &channel[T]{inner: ch, inj: inj}
+}
+
+type channel[T any] struct {
+	inner Channel[T]
+	inj   *Injector
+}
+
+func (c *channel[T]) Send(message T) error {
+	switch c.inj.disturb(true) {
+	case faultDrop:
+		return nil
+	case faultError:
+		return c.inj.cfg.Err
+	}
+	return c.inner.Send(message)
+}
+
+func (c *channel[T]) Receive() (T, bool) {
+	c.inj.disturb(false)
+	return c.inner.Receive()
+}
+
+// WrapPublisher returns a Publisher whose Publish may be delayed, dropped
+// (reported as delivered but never published) or failed, according to inj.
+func WrapPublisher[T any](p Publisher[T], inj *Injector) Publisher[T] {
+	return &publisher[T]{inner: p, inj: inj}
+}
+
+type publisher[T any] struct {
+	inner Publisher[T]
+	inj   *Injector
+}
+
+func (p *publisher[T]) Publish(topic string, message T) error {
+	switch p.inj.disturb(true) {
+	case faultDrop:
+		return nil
+	case faultError:
+		return p.inj.cfg.Err
+	}
+	return p.inner.Publish(topic, message)
+}
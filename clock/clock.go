@@ -0,0 +1,49 @@
+// Package clock abstracts the parts of the time package that the examples use
+// for timeouts, TTLs and periodic work, so tests can substitute FakeClock and
+// control time explicitly instead of sleeping.
+package clock
+
+import "time"
+
+// Clock is the source of time for time-dependent code.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a Timer that fires once after d.
+	NewTimer(d time.Duration) Timer
+	// Sleep pauses the calling goroutine for at least d.
+	Sleep(d time.Duration)
+}
+
+// Timer is the Clock counterpart of *time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+	// Reset changes the timer to fire after d. It returns true if the timer
+	// was active.
+	Reset(d time.Duration) bool
+}
+
+// Real is the Clock backed by the time package.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time        { return r.t.C }
+func (r realTimer) Stop() bool                 { return r.t.Stop() }
+func (r realTimer) Reset(d time.Duration) bool { return r.t.Reset(d) }
@@ -0,0 +1,147 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a Clock whose time only moves when Advance or Set is called.
+// Timers fire synchronously inside those calls, in deadline order, which makes
+// timeout and TTL behaviour reproducible in tests.
+//
+// FakeClock is safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer // pending timers, unordered
+}
+
+// NewFakeClock returns a FakeClock whose current time is start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the fake current time.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once d has been advanced past.
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock has been advanced by at least d.
+func (f *FakeClock) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTimer creates a timer that fires when the clock reaches Now()+d.
+// A non-positive d fires immediately.
+func (f *FakeClock) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.schedule(t, d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer that becomes due.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to t, firing every timer whose deadline is at or before t
+// in deadline order. Moving the clock backwards is ignored.
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for {
+		next := f.nextLocked()
+		if next == nil || next.when.After(t) {
+			break
+		}
+		f.now = next.when
+		f.fireLocked(next)
+	}
+	if t.After(f.now) {
+		f.now = t
+	}
+}
+
+// Pending returns the number of timers waiting to fire.
+func (f *FakeClock) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+func (f *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	t.when = f.now.Add(d)
+	if d <= 0 {
+		f.fireLocked(t)
+		return
+	}
+	t.active = true
+	f.timers = append(f.timers, t)
+}
+
+func (f *FakeClock) nextLocked() *fakeTimer {
+	if len(f.timers) == 0 {
+		return nil
+	}
+	sort.SliceStable(f.timers, func(i, j int) bool {
+		return f.timers[i].when.Before(f.timers[j].when)
+	})
+	return f.timers[0]
+}
+
+func (f *FakeClock) fireLocked(t *fakeTimer) {
+	f.removeLocked(t)
+	select {
+	case t.c <- f.now:
+	default: // previous tick not consumed yet, same as time.Timer
+	}
+}
+
+func (f *FakeClock) removeLocked(t *fakeTimer) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	for i, pending := range f.timers {
+		if pending == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	when   time.Time
+	c      chan time.Time
+	active bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.removeLocked(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := t.clock.removeLocked(t)
+	t.clock.schedule(t, d)
+	return wasActive
+}
@@ -0,0 +1,45 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFakeClockTimers tests that timers fire in deadline order only when time is advanced
+func TestFakeClockTimers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+
+	late := c.NewTimer(2 * time.Second)
+	early := c.NewTimer(time.Second)
+	stopped := c.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Fatal("Stop() on an active timer should return true")
+	}
+
+	c.Advance(1500 * time.Millisecond)
+	select {
+	case at := <-early.C():
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("Expected early timer to fire at +1s, got %v", at.Sub(start))
+		}
+	default:
+		t.Fatal("Expected early timer to have fired")
+	}
+	select {
+	case <-late.C():
+		t.Fatal("Late timer fired before its deadline")
+	case <-stopped.C():
+		t.Fatal("Stopped timer fired")
+	default:
+	}
+
+	if c.Pending() != 1 {
+		t.Errorf("Expected 1 pending timer, got %d", c.Pending())
+	}
+	c.Advance(time.Second)
+	<-late.C()
+	if !c.Now().Equal(start.Add(2500 * time.Millisecond)) {
+		t.Errorf("Expected clock at +2.5s, got %v", c.Now().Sub(start))
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"sync"
+
+	"goconcurrency/goroutine/stress"
 )
 
 // counter demonstrates the race condition problem.
@@ -50,6 +52,7 @@ func counter(count *int, wg *sync.WaitGroup) {
 //  2. Goroutines run concurrently, causing race condition
 //  3. Some increments are lost due to concurrent access
 //  4. Final count is incorrect and non-deterministic
+//  5. Repeat the experiment with the stress package to show how results diverge
 //
 // Solution:
 //   - Use sync.Mutex to protect shared variable
@@ -82,4 +85,21 @@ func main() {
 		fmt.Println("⚠️  Count is correct this time, but race condition still exists!")
 		fmt.Println("   Run multiple times or use -race flag to detect it.")
 	}
+
+	// Repeat the same experiment 20 times with the stress harness
+	// All goroutines start together behind a barrier, and the report groups
+	// the final counts: more than one distinct count means the code is racy
+	fmt.Println()
+	fmt.Println("Repeating with goroutine/stress (20 runs):")
+	report := stress.Run(stress.Config{Goroutines: 10, Iterations: 1000, Runs: 20}, func() stress.Trial[int] {
+		count := 0
+		return stress.Trial[int]{
+			Op:     func(goroutine, iteration int) { count++ }, // Same unsynchronized increment
+			Result: func() int { return count },
+		}
+	})
+	fmt.Println(report)
+	if report.Diverged() || report.Count(10000) == 0 {
+		fmt.Println("❌ Results are not reproducible: the counter has a race condition.")
+	}
 }
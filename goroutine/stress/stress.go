@@ -0,0 +1,124 @@
+// Package stress runs a piece of code under heavy, synchronized concurrency many
+// times and reports whether the outcome is stable. It generalizes the race demo in
+// goroutine/basic/example_4 into something usable from ordinary tests:
+//
+//	report := stress.Run(stress.Config{Goroutines: 10, Iterations: 1000, Runs: 20},
+//		func() stress.Trial[int] {
+//			var count atomic.Int64
+//			return stress.Trial[int]{
+//				Op:     func(g, i int) { count.Add(1) },
+//				Result: func() int { return int(count.Load()) },
+//			}
+//		})
+//	if report.Diverged() {
+//		t.Fatal(report)
+//	}
+package stress
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config sizes a stress run.
+type Config struct {
+	Goroutines int // Concurrent goroutines per run (default 1)
+	Iterations int // Calls of Op per goroutine (default 1)
+	Runs       int // Independent repetitions compared for divergence (default 1)
+}
+
+// Trial is one independent run: fresh state plus the operation to hammer and a way
+// to read the outcome once every goroutine has finished.
+type Trial[R comparable] struct {
+	Op     func(goroutine, iteration int)
+	Result func() R
+}
+
+// Report summarizes all runs.
+type Report[R comparable] struct {
+	Config  Config
+	Results map[R]int // Distinct outcome -> number of runs that produced it
+
+	Min  time.Duration // Fastest run
+	Max  time.Duration // Slowest run
+	Mean time.Duration // Average run duration
+}
+
+// Run executes cfg.Runs trials. Each trial gets fresh state from setup, starts
+// cfg.Goroutines goroutines that wait on a barrier so they begin at the same
+// moment (maximizing interleaving), and calls Op cfg.Iterations times in each.
+func Run[R comparable](cfg Config, setup func() Trial[R]) Report[R] {
+	cfg.Goroutines = max(cfg.Goroutines, 1)
+	cfg.Iterations = max(cfg.Iterations, 1)
+	cfg.Runs = max(cfg.Runs, 1)
+
+	report := Report[R]{Config: cfg, Results: make(map[R]int)}
+	var total time.Duration
+	for run := range cfg.Runs {
+		trial := setup()
+		elapsed := runTrial(cfg, trial.Op)
+		report.Results[trial.Result()]++
+
+		total += elapsed
+		if run == 0 || elapsed < report.Min {
+			report.Min = elapsed
+		}
+		report.Max = max(report.Max, elapsed)
+	}
+	report.Mean = total / time.Duration(cfg.Runs)
+	return report
+}
+
+// runTrial starts all goroutines behind a barrier and returns how long they took
+// once released.
+func runTrial(cfg Config, op func(goroutine, iteration int)) time.Duration {
+	var ready, done sync.WaitGroup
+	start := make(chan struct{})
+
+	ready.Add(cfg.Goroutines)
+	for g := range cfg.Goroutines {
+		done.Go(func() {
+			ready.Done()
+			<-start // barrier: nobody runs until everyone is ready
+			for i := range cfg.Iterations {
+				op(g, i)
+			}
+		})
+	}
+
+	ready.Wait()
+	began := time.Now()
+	close(start)
+	done.Wait()
+	return time.Since(began)
+}
+
+// Diverged reports whether the runs produced more than one distinct result.
+func (r Report[R]) Diverged() bool {
+	return len(r.Results) > 1
+}
+
+// Count returns how many runs produced result.
+func (r Report[R]) Count(result R) int {
+	return r.Results[result]
+}
+
+// String formats the report, most frequent outcome first.
+func (r Report[R]) String() string {
+	outcomes := slices.SortedFunc(maps.Keys(r.Results), func(a, b R) int {
+		return r.Results[b] - r.Results[a]
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d runs × %d goroutines × %d iterations: %d distinct result(s)\n",
+		r.Config.Runs, r.Config.Goroutines, r.Config.Iterations, len(r.Results))
+	for _, outcome := range outcomes {
+		fmt.Fprintf(&b, "  %v: %d run(s)\n", outcome, r.Results[outcome])
+	}
+	fmt.Fprintf(&b, "  duration min %v / mean %v / max %v", r.Min, r.Mean, r.Max)
+	return b.String()
+}
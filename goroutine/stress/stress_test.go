@@ -0,0 +1,49 @@
+package stress
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+// TestRunAtomicCounterIsStable tests that a correctly synchronized counter never diverges
+func TestRunAtomicCounterIsStable(t *testing.T) {
+	cfg := Config{Goroutines: 8, Iterations: 500, Runs: 10}
+	report := Run(cfg, func() Trial[int64] {
+		var count atomic.Int64
+		return Trial[int64]{
+			Op:     func(int, int) { count.Add(1) },
+			Result: count.Load,
+		}
+	})
+
+	if report.Diverged() {
+		t.Fatalf("Expected a single result, got:\n%v", report)
+	}
+	if report.Count(8*500) != 10 {
+		t.Errorf("Expected all 10 runs to count 4000, got:\n%v", report)
+	}
+	if report.Min > report.Mean || report.Mean > report.Max {
+		t.Errorf("Expected min <= mean <= max, got %v / %v / %v", report.Min, report.Mean, report.Max)
+	}
+}
+
+// TestRunDetectsLostUpdates tests that a non-atomic read-modify-write is caught
+func TestRunDetectsLostUpdates(t *testing.T) {
+	cfg := Config{Goroutines: 8, Iterations: 200, Runs: 5}
+	report := Run(cfg, func() Trial[int64] {
+		var count atomic.Int64 // atomic loads/stores keep -race quiet; the update is still lost
+		return Trial[int64]{
+			Op: func(int, int) {
+				v := count.Load()
+				runtime.Gosched() // widen the window between read and write
+				count.Store(v + 1)
+			},
+			Result: count.Load,
+		}
+	})
+
+	if report.Count(8*200) == cfg.Runs {
+		t.Fatalf("Expected lost updates in at least one run, got:\n%v", report)
+	}
+}
@@ -0,0 +1,215 @@
+// Package linearizability checks recorded histories of concurrent operations
+// against a sequential model.
+//
+// A history is linearizable if every operation can be assigned a single instant
+// between its call and its return such that executing the operations one by one in
+// that order on the sequential model produces exactly the observed outputs. This is
+// the correctness condition expected from channels, queues and maps shared between
+// goroutines, and it catches ordering bugs that -race cannot see (the race detector
+// only reports unsynchronized memory access, not wrong results).
+//
+// The checker uses the Wing & Gong backtracking search with memoization of
+// (linearized set, model state) pairs. The search is exponential in the worst case,
+// so keep histories bounded: a few goroutines with tens of operations each.
+package linearizability
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Operation is one completed call observed by a Recorder.
+type Operation[I, O any] struct {
+	Client int   // Caller identifier, informational only
+	Input  I     // What was requested
+	Output O     // What was observed
+	Call   int64 // Logical timestamp taken before the call started
+	Return int64 // Logical timestamp taken after the call returned
+}
+
+// Model is the sequential specification of a data structure.
+type Model[S, I, O any] struct {
+	// Init returns the initial state.
+	Init func() S
+	// Step applies input to state and reports whether output is a legal result,
+	// together with the resulting state. Step must not modify state in place.
+	Step func(state S, input I, output O) (ok bool, next S)
+	// Equal compares states for memoization. Defaults to reflect.DeepEqual.
+	Equal func(a, b S) bool
+}
+
+// Recorder collects operations performed concurrently by many goroutines.
+// Timestamps come from a shared logical clock, so "returned before called"
+// relationships are exact rather than subject to wall-clock resolution.
+type Recorder[I, O any] struct {
+	clock atomic.Int64
+	mu    sync.Mutex
+	ops   []Operation[I, O]
+}
+
+// Record invokes fn and stores it as an operation of client with the given input.
+func (r *Recorder[I, O]) Record(client int, input I, fn func() O) O {
+	call := r.clock.Add(1)
+	output := fn()
+	ret := r.clock.Add(1)
+
+	r.mu.Lock()
+	r.ops = append(r.ops, Operation[I, O]{Client: client, Input: input, Output: output, Call: call, Return: ret})
+	r.mu.Unlock()
+	return output
+}
+
+// History returns a copy of the recorded operations.
+func (r *Recorder[I, O]) History() []Operation[I, O] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.ops)
+}
+
+// entry is a call or return event in the time-ordered event list.
+type entry struct {
+	op       int
+	time     int64
+	isReturn bool
+	match    *entry // call <-> return of the same operation
+	prev     *entry
+	next     *entry
+}
+
+// Check reports whether history is linearizable with respect to model.
+func Check[S, I, O any](model Model[S, I, O], history []Operation[I, O]) bool {
+	equal := model.Equal
+	if equal == nil {
+		equal = func(a, b S) bool { return reflect.DeepEqual(a, b) }
+	}
+
+	head := buildEntries(history)
+	linearized := newBitset(len(history))
+	cache := make(map[string][]S)
+
+	type frame struct {
+		call  *entry
+		state S
+	}
+	var stack []frame
+
+	state := model.Init()
+	e := head.next
+	for head.next != nil {
+		if !e.isReturn {
+			op := history[e.op]
+			if ok, next := model.Step(state, op.Input, op.Output); ok {
+				candidate := linearized.with(e.op)
+				key := candidate.key()
+				if !slices.ContainsFunc(cache[key], func(s S) bool { return equal(s, next) }) {
+					cache[key] = append(cache[key], next)
+					stack = append(stack, frame{call: e, state: state})
+					state = next
+					linearized = candidate
+					lift(e)
+					e = head.next
+					continue
+				}
+			}
+			e = e.next
+			continue
+		}
+
+		// Reached the return of an operation that could not be placed: backtrack
+		if len(stack) == 0 {
+			return false
+		}
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		state = top.state
+		linearized = linearized.without(top.call.op)
+		unlift(top.call)
+		e = top.call.next
+	}
+	return true
+}
+
+// buildEntries returns a sentinel head of the doubly linked, time-ordered event list.
+func buildEntries[I, O any](history []Operation[I, O]) *entry {
+	events := make([]*entry, 0, 2*len(history))
+	for i, op := range history {
+		call := &entry{op: i, time: op.Call}
+		ret := &entry{op: i, time: op.Return, isReturn: true}
+		call.match, ret.match = ret, call
+		events = append(events, call, ret)
+	}
+	slices.SortStableFunc(events, func(a, b *entry) int {
+		switch {
+		case a.time < b.time:
+			return -1
+		case a.time > b.time:
+			return 1
+		case !a.isReturn && b.isReturn:
+			return -1 // overlapping at the same instant counts as concurrent
+		case a.isReturn && !b.isReturn:
+			return 1
+		}
+		return 0
+	})
+
+	head := &entry{}
+	prev := head
+	for _, e := range events {
+		prev.next, e.prev = e, prev
+		prev = e
+	}
+	return head
+}
+
+// lift removes an operation's call and return events from the list.
+func lift(call *entry) {
+	call.prev.next = call.next
+	call.next.prev = call.prev
+	ret := call.match
+	ret.prev.next = ret.next
+	if ret.next != nil {
+		ret.next.prev = ret.prev
+	}
+}
+
+// unlift restores events removed by lift, in reverse order.
+func unlift(call *entry) {
+	ret := call.match
+	ret.prev.next = ret
+	if ret.next != nil {
+		ret.next.prev = ret
+	}
+	call.prev.next = call
+	call.next.prev = call
+}
+
+// bitset records which operations are already linearized.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) with(i int) bitset {
+	c := slices.Clone(b)
+	c[i/64] |= 1 << (i % 64)
+	return c
+}
+
+func (b bitset) without(i int) bitset {
+	c := slices.Clone(b)
+	c[i/64] &^= 1 << (i % 64)
+	return c
+}
+
+func (b bitset) key() string {
+	buf := make([]byte, 0, 8*len(b))
+	for _, word := range b {
+		for shift := 0; shift < 64; shift += 8 {
+			buf = append(buf, byte(word>>shift))
+		}
+	}
+	return string(buf)
+}
@@ -0,0 +1,128 @@
+package linearizability
+
+import (
+	"sync"
+	"testing"
+)
+
+type queueOp = Operation[QueueInput[int], QueueOutput[int]]
+
+func enq(v int, call, ret int64) queueOp {
+	return queueOp{Input: QueueInput[int]{Op: Enqueue, Value: v}, Call: call, Return: ret}
+}
+
+func deq(v int, call, ret int64) queueOp {
+	return queueOp{Input: QueueInput[int]{Op: Dequeue}, Output: QueueOutput[int]{Value: v, OK: true}, Call: call, Return: ret}
+}
+
+// TestCheckQueueHistories tests hand-written queue histories with known answers
+func TestCheckQueueHistories(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []queueOp
+		want    bool
+	}{
+		{
+			name:    "sequential FIFO",
+			history: []queueOp{enq(1, 1, 2), enq(2, 3, 4), deq(1, 5, 6), deq(2, 7, 8)},
+			want:    true,
+		},
+		{
+			name:    "sequential LIFO is rejected",
+			history: []queueOp{enq(1, 1, 2), enq(2, 3, 4), deq(2, 5, 6), deq(1, 7, 8)},
+			want:    false,
+		},
+		{
+			name:    "overlapping enqueues may be ordered either way",
+			history: []queueOp{enq(1, 1, 4), enq(2, 2, 3), deq(2, 5, 6), deq(1, 7, 8)},
+			want:    true,
+		},
+		{
+			name:    "dequeue overlapping its enqueue",
+			history: []queueOp{deq(1, 1, 4), enq(1, 2, 3)},
+			want:    true,
+		},
+		{
+			name:    "dequeue completed before the enqueue began",
+			history: []queueOp{deq(1, 1, 2), enq(1, 3, 4)},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Check(QueueModel[int](), tt.history); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCheckRegisterStaleRead tests that a read returning an overwritten value is rejected
+func TestCheckRegisterStaleRead(t *testing.T) {
+	type regOp = Operation[RegisterInput[int], int]
+	history := []regOp{
+		{Input: RegisterInput[int]{Op: Write, Value: 1}, Call: 1, Return: 2},
+		{Input: RegisterInput[int]{Op: Write, Value: 2}, Call: 3, Return: 4},
+		{Input: RegisterInput[int]{Op: Read}, Output: 1, Call: 5, Return: 6},
+	}
+	if Check(RegisterModel(0), history) {
+		t.Fatal("Expected stale read to be rejected")
+	}
+
+	history[2].Call = 2 // now overlaps the first write's completion window
+	history[1].Call, history[1].Return = 5, 7
+	history[2].Return = 6
+	if !Check(RegisterModel(0), history) {
+		t.Fatal("Expected read concurrent with second write to be accepted")
+	}
+}
+
+// lockedQueue is a mutex-protected FIFO used to exercise the Recorder end to end.
+type lockedQueue struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	items []int
+}
+
+// TestRecorderConcurrentQueue tests a recorded concurrent history of a correct queue
+func TestRecorderConcurrentQueue(t *testing.T) {
+	q := &lockedQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	var rec Recorder[QueueInput[int], QueueOutput[int]]
+
+	var wg sync.WaitGroup
+	for p := range 3 {
+		wg.Go(func() {
+			for i := range 4 {
+				v := p*10 + i
+				rec.Record(p, QueueInput[int]{Op: Enqueue, Value: v}, func() QueueOutput[int] {
+					q.mu.Lock()
+					q.items = append(q.items, v)
+					q.cond.Signal()
+					q.mu.Unlock()
+					return QueueOutput[int]{}
+				})
+			}
+		})
+		wg.Go(func() {
+			for range 4 {
+				rec.Record(100+p, QueueInput[int]{Op: Dequeue}, func() QueueOutput[int] {
+					q.mu.Lock()
+					defer q.mu.Unlock()
+					for len(q.items) == 0 {
+						q.cond.Wait()
+					}
+					v := q.items[0]
+					q.items = q.items[1:]
+					return QueueOutput[int]{Value: v, OK: true}
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	if !Check(QueueModel[int](), rec.History()) {
+		t.Fatal("Expected history of a mutex-protected queue to be linearizable")
+	}
+}
@@ -0,0 +1,72 @@
+package linearizability
+
+import "slices"
+
+// QueueOp selects the operation of a QueueInput.
+type QueueOp int
+
+const (
+	Enqueue QueueOp = iota
+	Dequeue
+)
+
+// QueueInput is the input of a FIFO queue operation. Value is used by Enqueue.
+type QueueInput[T any] struct {
+	Op    QueueOp
+	Value T
+}
+
+// QueueOutput is the observed result. For Dequeue, OK is false when the queue
+// reported that nothing could be taken (e.g. a closed, drained channel).
+type QueueOutput[T any] struct {
+	Value T
+	OK    bool
+}
+
+// QueueModel is the sequential specification of an unbounded FIFO queue whose
+// Dequeue blocks while empty, which is how a channel behaves while open.
+func QueueModel[T comparable]() Model[[]T, QueueInput[T], QueueOutput[T]] {
+	return Model[[]T, QueueInput[T], QueueOutput[T]]{
+		Init: func() []T { return nil },
+		Step: func(state []T, in QueueInput[T], out QueueOutput[T]) (bool, []T) {
+			switch in.Op {
+			case Enqueue:
+				return true, append(slices.Clip(state), in.Value)
+			default:
+				if !out.OK || len(state) == 0 || state[0] != out.Value {
+					return false, state
+				}
+				return true, state[1:]
+			}
+		},
+		Equal: slices.Equal[[]T],
+	}
+}
+
+// RegisterOp selects the operation of a RegisterInput.
+type RegisterOp int
+
+const (
+	Read RegisterOp = iota
+	Write
+)
+
+// RegisterInput is a read or a write of a single value.
+type RegisterInput[T any] struct {
+	Op    RegisterOp
+	Value T
+}
+
+// RegisterModel is the sequential specification of a read/write register whose
+// initial value is initial. Output is the value observed by reads.
+func RegisterModel[T comparable](initial T) Model[T, RegisterInput[T], T] {
+	return Model[T, RegisterInput[T], T]{
+		Init: func() T { return initial },
+		Step: func(state T, in RegisterInput[T], out T) (bool, T) {
+			if in.Op == Write {
+				return true, in.Value
+			}
+			return out == state, state
+		},
+	}
+}
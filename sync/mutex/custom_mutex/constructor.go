@@ -2,18 +2,46 @@ package main
 
 func NewMutex[T any]() *Mutex[T] {
 	m := &Mutex[T]{
-		read:  make(chan chan T),
-		write: make(chan T),
-		stop:  make(chan struct{}),
+		read:   make(chan chan T),
+		write:  make(chan T),
+		update: make(chan func(T) T),
+		wait:   make(chan *waiter[T]),
+		unwait: make(chan *waiter[T]),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
 	}
 	go func() {
+		// Waiters are only touched by this goroutine, like data
+		waiters := make(map[*waiter[T]]struct{})
+		// Re-evaluate every parked WaitFor against the new value
+		wake := func() {
+			for w := range waiters {
+				if w.pred(m.data) {
+					w.ready <- m.data
+					delete(waiters, w)
+				}
+			}
+		}
 		for {
 			select {
 			case responeChan := <-m.read:
 				responeChan <- m.data
 			case value := <-m.write:
 				m.data = value
+				wake()
+			case fn := <-m.update:
+				m.data = fn(m.data) // Read and write in one step: no Send can slip in between
+				wake()
+			case w := <-m.wait:
+				if w.pred(m.data) {
+					w.ready <- m.data
+				} else {
+					waiters[w] = struct{}{}
+				}
+			case w := <-m.unwait:
+				delete(waiters, w)
 			case <-m.stop:
+				close(m.done) // Callers parked on the request channels can give up
 				return
 			}
 		}
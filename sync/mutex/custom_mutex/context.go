@@ -0,0 +1,28 @@
+package main
+
+import "context"
+
+// GetContext returns the protected value like Get, or ctx.Err() if ctx is done before
+// the monitor goroutine takes the request, so a caller cannot hang forever on a busy
+// or closed monitor.
+func (m *Mutex[T]) GetContext(ctx context.Context) (T, error) {
+	responeChan := make(chan T)
+	select {
+	case m.read <- responeChan:
+		return <-responeChan, nil // The monitor answers as soon as it took the request
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// SendContext stores value like Send, or returns ctx.Err() if ctx is done before the
+// monitor goroutine takes it, in which case the protected value is unchanged.
+func (m *Mutex[T]) SendContext(ctx context.Context, value T) error {
+	select {
+	case m.write <- value:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
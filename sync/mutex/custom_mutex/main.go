@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 )
@@ -17,6 +20,12 @@ import (
 //  1. Basic Operations
 //  2. Concurrent Access
 //  3. Resource Clean-up
+//  4. Heavy Load
+//  5. Concurrent String Access
+//  6. WaitFor
+//  7. Update (atomic read-modify-write)
+//  8. TryGet / TrySet (non-blocking)
+//  9. GetContext / SendContext (cancellable)
 func main() {
 	fmt.Println("=== Custom Mutex Implementation Tests ===")
 	fmt.Println()
@@ -36,6 +45,18 @@ func main() {
 	// Test 5: Concurrent (Race Condition) String Access
 	testConcurrentValueAccess()
 
+	// Test 6: WaitFor (condition variable on the monitor)
+	testWaitFor()
+
+	// Test 7: Update (read-modify-write in one monitor request)
+	testUpdate()
+
+	// Test 8: TryGet / TrySet (never block, even after Close)
+	testTry()
+
+	// Test 9: GetContext / SendContext (give up when the context is done)
+	testContext()
+
 	fmt.Println("=== All Tests Completed ===")
 }
 
@@ -244,3 +265,128 @@ func testConcurrentValueAccess() {
 	m.Close()
 	fmt.Println()
 }
+
+// testWaitFor waits until a counter reaches 100 while writers increment it
+func testWaitFor() {
+	fmt.Println("Test 6: WaitFor (wait until counter >= 100)")
+	m := NewMutexWithValue(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	go func() {
+		for i := 1; i <= 150; i++ {
+			m.Send(i)
+		}
+	}()
+
+	val, err := m.WaitFor(ctx, func(v int) bool { return v >= 100 })
+	if err != nil {
+		fmt.Printf("  ✗ WaitFor returned error: %v\n", err)
+	} else if val == 100 {
+		fmt.Println("  ✓ WaitFor woke up exactly when the counter reached 100")
+	} else {
+		fmt.Printf("  ✗ Expected 100, got %d\n", val)
+	}
+
+	m.Close()
+	fmt.Println()
+}
+
+// testUpdate increments a counter from many goroutines, first with Get + Send and then
+// with Update, to show the lost updates Update prevents
+func testUpdate() {
+	fmt.Println("Test 7: Update (atomic read-modify-write)")
+	workers := 50
+	increments := 100
+	want := workers * increments
+
+	run := func(increment func(m *Mutex[int])) int {
+		m := NewMutexWithValue(0)
+		defer m.Close()
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < increments; j++ {
+					increment(m)
+				}
+			}()
+		}
+		wg.Wait()
+		return m.Get()
+	}
+
+	racy := run(func(m *Mutex[int]) {
+		v := m.Get()
+		runtime.Gosched() // Widen the window another writer can slip into
+		m.Send(v + 1)
+	})
+	fmt.Printf("  Get() + Send(): %d of %d increments kept\n", racy, want)
+
+	atomic := run(func(m *Mutex[int]) { m.Update(func(v int) int { return v + 1 }) })
+	if atomic == want {
+		fmt.Printf("  ✓ Update(): all %d increments kept\n", want)
+	} else {
+		fmt.Printf("  ✗ Update(): expected %d, got %d\n", want, atomic)
+	}
+	fmt.Println()
+}
+
+// testTry shows TryGet and TrySet succeeding on an idle monitor and failing instead of
+// blocking once it is closed
+func testTry() {
+	fmt.Println("Test 8: TryGet / TrySet (non-blocking)")
+	m := NewMutexWithValue(1)
+	time.Sleep(time.Millisecond) // Let the monitor reach its select
+
+	if m.TrySet(2) {
+		time.Sleep(time.Millisecond) // The monitor is busy until it has stored 2
+		if val, ok := m.TryGet(); ok && val == 2 {
+			fmt.Println("  ✓ TrySet(2) and TryGet() succeeded on the idle monitor")
+		} else {
+			fmt.Printf("  ✗ Expected TryGet() = 2, true; got %d, %v\n", val, ok)
+		}
+	} else {
+		fmt.Println("  ✗ TrySet(2) failed on the idle monitor")
+	}
+
+	m.Close()
+	_, got := m.TryGet()
+	set := m.TrySet(3)
+	if !got && !set {
+		fmt.Println("  ✓ TryGet() and TrySet() returned false after Close() instead of blocking")
+	} else {
+		fmt.Println("  ✗ Expected TryGet() and TrySet() to fail after Close()")
+	}
+	fmt.Println()
+}
+
+// testContext shows GetContext and SendContext working on a running monitor and giving
+// up at their deadline once it is closed, where Get and Send would hang forever
+func testContext() {
+	fmt.Println("Test 9: GetContext / SendContext (cancellable)")
+	m := NewMutex[int]()
+
+	if err := m.SendContext(context.Background(), 7); err != nil {
+		fmt.Printf("  ✗ SendContext returned error: %v\n", err)
+	}
+	if val, err := m.GetContext(context.Background()); err == nil && val == 7 {
+		fmt.Println("  ✓ SendContext(7) and GetContext() worked on the running monitor")
+	} else {
+		fmt.Printf("  ✗ Expected 7, got %d (err=%v)\n", val, err)
+	}
+
+	m.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, getErr := m.GetContext(ctx)
+	sendErr := m.SendContext(ctx, 8)
+	if errors.Is(getErr, context.DeadlineExceeded) && errors.Is(sendErr, context.DeadlineExceeded) {
+		fmt.Println("  ✓ GetContext and SendContext gave up at the deadline after Close()")
+	} else {
+		fmt.Printf("  ✗ Expected context.DeadlineExceeded, got %v and %v\n", getErr, sendErr)
+	}
+	fmt.Println()
+}
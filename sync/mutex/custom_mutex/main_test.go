@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWaitFor tests that WaitFor wakes up on the first write satisfying the predicate
+func TestWaitFor(t *testing.T) {
+	m := NewMutexWithValue(0)
+	defer m.Close()
+
+	// Already satisfied: returns immediately
+	if val, err := m.WaitFor(context.Background(), func(v int) bool { return v >= 0 }); err != nil || val != 0 {
+		t.Errorf("Expected immediate 0, got %d (err=%v)", val, err)
+	}
+
+	go func() {
+		for i := 1; i <= 150; i++ {
+			m.Send(i)
+		}
+	}()
+	val, err := m.WaitFor(context.Background(), func(v int) bool { return v >= 100 })
+	if err != nil || val != 100 {
+		t.Errorf("Expected 100, got %d (err=%v)", val, err)
+	}
+}
+
+// TestWaitForCanceled tests that WaitFor gives up when its context is done
+func TestWaitForCanceled(t *testing.T) {
+	m := NewMutexWithValue(0)
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.WaitFor(ctx, func(v int) bool { return v < 0 }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The canceled waiter was removed: later writes still work
+	m.Send(-1)
+	if got := m.Get(); got != -1 {
+		t.Errorf("Expected -1, got %d", got)
+	}
+}
+
+// TestUpdate tests that concurrent Updates never lose an increment and wake WaitFor
+func TestUpdate(t *testing.T) {
+	m := NewMutexWithValue(0)
+	defer m.Close()
+
+	reached := make(chan int, 1)
+	go func() {
+		val, _ := m.WaitFor(context.Background(), func(v int) bool { return v >= 1000 })
+		reached <- val
+	}()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			for range 100 {
+				m.Update(func(v int) int { return v + 1 })
+			}
+		})
+	}
+	wg.Wait()
+	if got := m.Get(); got != 1000 {
+		t.Errorf("Expected 1000 after 1000 increments, got %d", got)
+	}
+	if val := <-reached; val != 1000 {
+		t.Errorf("Expected WaitFor to wake at 1000, got %d", val)
+	}
+}
+
+// TestTryGetTrySet tests that TryGet and TrySet succeed once the monitor is free and
+// return false instead of blocking after Close
+func TestTryGetTrySet(t *testing.T) {
+	m := NewMutexWithValue(1)
+
+	deadline := time.Now().Add(time.Second)
+	for !m.TrySet(2) { // Fails while the monitor goroutine is starting or busy
+		if time.Now().After(deadline) {
+			t.Fatal("TrySet never succeeded on an idle monitor")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for {
+		if val, ok := m.TryGet(); ok {
+			if val != 2 {
+				t.Errorf("Expected 2, got %d", val)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("TryGet never succeeded on an idle monitor")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	m.Close()
+	if _, ok := m.TryGet(); ok {
+		t.Error("Expected TryGet to fail after Close")
+	}
+	if m.TrySet(3) {
+		t.Error("Expected TrySet to fail after Close")
+	}
+}
+
+// TestGetSendContext tests that GetContext and SendContext work on a running monitor
+// and return the context's error instead of hanging once it is closed
+func TestGetSendContext(t *testing.T) {
+	m := NewMutex[int]()
+	if err := m.SendContext(context.Background(), 7); err != nil {
+		t.Fatalf("SendContext() returned error: %v", err)
+	}
+	if val, err := m.GetContext(context.Background()); err != nil || val != 7 {
+		t.Errorf("Expected 7, got %d (err=%v)", val, err)
+	}
+
+	m.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.GetContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded from GetContext, got %v", err)
+	}
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := m.SendContext(canceled, 8); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from SendContext, got %v", err)
+	}
+}
+
+// TestWaitForClosed tests that WaitFor returns ErrClosed, even without a deadline,
+// when the Mutex is closed while it waits or before it is called
+func TestWaitForClosed(t *testing.T) {
+	m := NewMutexWithValue(0)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := m.WaitFor(context.Background(), func(v int) bool { return v < 0 })
+		errs <- err
+	}()
+	time.Sleep(5 * time.Millisecond) // Let WaitFor park
+	m.Close()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitFor hung after Close")
+	}
+
+	if _, err := m.WaitFor(context.Background(), func(v int) bool { return v < 0 }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed on a closed Mutex, got %v", err)
+	}
+}
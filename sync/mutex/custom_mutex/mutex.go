@@ -1,8 +1,18 @@
 package main
 
 type Mutex[T any] struct {
-	data  T
-	read  chan chan T
-	write chan T
-	stop  chan struct{}
+	data   T
+	read   chan chan T
+	write  chan T
+	update chan func(T) T
+	wait   chan *waiter[T]
+	unwait chan *waiter[T]
+	stop   chan struct{}
+	done   chan struct{} // closed once the monitor goroutine has exited
+}
+
+// waiter is a WaitFor call parked in the monitor goroutine until pred holds.
+type waiter[T any] struct {
+	pred  func(T) bool
+	ready chan T // buffered (1): the monitor never blocks on a waiter that gave up
 }
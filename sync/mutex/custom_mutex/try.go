@@ -0,0 +1,27 @@
+package main
+
+// TryGet returns the protected value if the monitor goroutine takes the request right
+// away. It returns false, without blocking, when the monitor is busy with another
+// request or has been closed.
+func (m *Mutex[T]) TryGet() (T, bool) {
+	responeChan := make(chan T)
+	select {
+	case m.read <- responeChan:
+		return <-responeChan, true // The monitor answers as soon as it took the request
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
+// TrySet stores value if the monitor goroutine takes it right away, and reports
+// whether it did. Like TryGet it never blocks: a busy or closed monitor makes it
+// return false, leaving the value unchanged.
+func (m *Mutex[T]) TrySet(value T) bool {
+	select {
+	case m.write <- value:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+// Update replaces the protected value with fn(value) as a single request to the
+// monitor goroutine. Unlike m.Send(f(m.Get())), no other Send or Update can run
+// between the read and the write, so concurrent read-modify-write cycles (counters,
+// appends) never lose an update.
+//
+// fn runs inside the monitor goroutine: it must be fast and must not call methods of
+// the same Mutex (that would deadlock the monitor). Parked WaitFor calls see the new
+// value as after a Send.
+func (m *Mutex[T]) Update(fn func(T) T) {
+	m.update <- fn
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned by WaitFor when the Mutex is closed before pred holds.
+var ErrClosed = errors.New("mutex is closed")
+
+// WaitFor blocks until the protected value satisfies pred and returns that value,
+// or returns ctx.Err() if ctx is done first. It gives the monitor Mutex
+// condition-variable semantics: "wait until counter >= 100".
+//
+// pred is evaluated inside the monitor goroutine, once when WaitFor is called and
+// again after every Send, so it sees every value written and never a torn one. It
+// must be fast and must not call methods of the same Mutex (that would deadlock the
+// monitor). A closed Mutex never changes its value again, so WaitFor returns ErrClosed
+// as soon as it is closed, including for calls already waiting.
+func (m *Mutex[T]) WaitFor(ctx context.Context, pred func(T) bool) (T, error) {
+	var zero T
+	w := &waiter[T]{pred: pred, ready: make(chan T, 1)}
+	select {
+	case m.wait <- w:
+	case <-m.done:
+		return zero, ErrClosed
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+
+	select {
+	case value := <-w.ready:
+		return value, nil
+	case <-m.done:
+		// The monitor may have satisfied w just before it stopped
+		select {
+		case value := <-w.ready:
+			return value, nil
+		default:
+			return zero, ErrClosed
+		}
+	case <-ctx.Done():
+		select {
+		case m.unwait <- w:
+		case <-m.done: // Closed meanwhile: nobody holds w anymore
+		}
+		// The monitor may have satisfied w just before it was removed
+		select {
+		case value := <-w.ready:
+			return value, nil
+		default:
+			return zero, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package testkit contains helpers for testing the concurrency examples
+// deterministically.
+package testkit
+
+import (
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// Scheduler drives goroutines in virtual time.
+//
+// It lives in a testing/synctest bubble (see Run), where time only moves when every
+// goroutine of the bubble is durably blocked: on a channel created in the bubble, a
+// select, a sync.Cond, a sync.WaitGroup or a timer. Advance moves the clock and,
+// after each timer that fires on the way, waits until every goroutine is blocked
+// again or has returned. Tests can therefore say "advance 500ms, expect debounce
+// flush" without sleeping and hoping the goroutine got scheduled.
+//
+// Code under test takes the Scheduler's Clock (or uses the time package directly,
+// which the bubble makes virtual too). Goroutines blocked on a sync.Mutex are not
+// durably blocked, so Settle waits for them to get the lock.
+type Scheduler struct {
+	// Clock is the bubble's clock. It starts at midnight UTC, 2000-01-01, so
+	// timestamps are identical between runs.
+	Clock clock.Clock
+
+	wg sync.WaitGroup
+}
+
+// Run runs fn in a new synctest bubble with a Scheduler for it. Every goroutine fn
+// starts must have returned by the time fn does, or the test fails.
+//
+// Usage example:
+//
+//	testkit.Run(t, func(t *testing.T, s *testkit.Scheduler) {
+//		s.Go(func() { debounce(s.Clock, 500*time.Millisecond, in, out) })
+//		in <- "a"
+//		s.Advance(500 * time.Millisecond)
+//		...
+//	})
+func Run(t *testing.T, fn func(t *testing.T, s *Scheduler)) {
+	t.Helper()
+	synctest.Test(t, func(t *testing.T) {
+		fn(t, &Scheduler{Clock: clock.Real})
+	})
+}
+
+// Go runs fn in a goroutine that Wait waits for.
+func (s *Scheduler) Go(fn func()) {
+	s.wg.Go(fn)
+}
+
+// Advance moves the clock forward by d. Every timer deadline inside the window is
+// visited in order and the goroutines are settled after each one, so timers created
+// while reacting to an earlier timer fire correctly.
+func (s *Scheduler) Advance(d time.Duration) {
+	time.Sleep(d)
+	s.Settle()
+}
+
+// Settle blocks until every other goroutine of the bubble is durably blocked or has
+// returned.
+func (s *Scheduler) Settle() {
+	synctest.Wait()
+}
+
+// Wait blocks until every goroutine started with Go has returned.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
@@ -0,0 +1,104 @@
+package testkit
+
+import (
+	"testing"
+	"time"
+
+	"goconcurrency/clock"
+)
+
+// debounce forwards the last value received on in once no new value has
+// arrived for wait, measured on c.
+func debounce(c clock.Clock, wait time.Duration, in <-chan string, out chan<- string) {
+	var (
+		pending string
+		timer   clock.Timer
+		fire    <-chan time.Time
+	)
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return
+			}
+			pending = v
+			if timer == nil {
+				timer = c.NewTimer(wait)
+			} else {
+				timer.Reset(wait)
+			}
+			fire = timer.C()
+		case <-fire:
+			fire = nil
+			out <- pending
+		}
+	}
+}
+
+// TestSchedulerDebounceFlush tests that advancing virtual time flushes a debouncer exactly once
+func TestSchedulerDebounceFlush(t *testing.T) {
+	Run(t, func(t *testing.T, s *Scheduler) {
+		in := make(chan string)
+		out := make(chan string, 1)
+		s.Go(func() { debounce(s.Clock, 500*time.Millisecond, in, out) })
+
+		in <- "a"
+		s.Advance(300 * time.Millisecond)
+		in <- "b" // restarts the 500ms window
+		s.Advance(300 * time.Millisecond)
+
+		select {
+		case v := <-out:
+			t.Fatalf("Expected no flush 300ms after last input, got %q", v)
+		default:
+		}
+
+		s.Advance(200 * time.Millisecond)
+		select {
+		case v := <-out:
+			if v != "b" {
+				t.Errorf("Expected flushed value 'b', got %q", v)
+			}
+		default:
+			t.Fatal("Expected debounce flush after 500ms of quiet")
+		}
+
+		close(in)
+		s.Wait()
+	})
+}
+
+// TestSchedulerChainedTimers tests that timers created while handling an earlier timer fire within one Advance
+func TestSchedulerChainedTimers(t *testing.T) {
+	Run(t, func(t *testing.T, s *Scheduler) {
+		ticks := make(chan time.Time, 10)
+		start := s.Clock.Now()
+		s.Go(func() {
+			for range 3 {
+				ticks <- <-s.Clock.After(100 * time.Millisecond)
+			}
+		})
+
+		s.Advance(time.Second)
+		s.Wait()
+
+		if len(ticks) != 3 {
+			t.Fatalf("Expected 3 ticks, got %d", len(ticks))
+		}
+		for i := 1; i <= 3; i++ {
+			tick := <-ticks
+			if want := start.Add(time.Duration(i) * 100 * time.Millisecond); !tick.Equal(want) {
+				t.Errorf("Tick %d: expected %v, got %v", i, want, tick)
+			}
+		}
+	})
+}
+
+// TestSchedulerStartTime tests that every run starts the clock at the same instant
+func TestSchedulerStartTime(t *testing.T) {
+	Run(t, func(t *testing.T, s *Scheduler) {
+		if want := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC); !s.Clock.Now().Equal(want) {
+			t.Errorf("Expected the clock to start at %v, got %v", want, s.Clock.Now())
+		}
+	})
+}